@@ -19,7 +19,6 @@ package main
 import (
 	"context"
 	"os"
-	"strings"
 
 	// load credential helpers
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
@@ -35,15 +34,9 @@ func main() {
 
 	cmd.SilenceErrors = true
 	if err := cmd.Execute(); err != nil {
-		// silent errors should not log, but still exit with an error code
-		// typically the command has already been logged with more detail
-		if !cli.IsSilent(err) {
-			c.Errorf("Error executing command:\n")
-			// errors can be multiple lines, indent each line
-			for _, line := range strings.Split(err.Error(), "\n") {
-				c.Errorf("  %s\n", line)
-			}
-		}
-		os.Exit(1)
+		// silent errors are not logged as text, but still exit with an error
+		// code, typically the command has already been logged with more detail
+		cli.PrintError(c, err)
+		os.Exit(cli.ExitCode(err))
 	}
 }
@@ -42,6 +42,8 @@ knative runtime.
 		Aliases: []string{"applications", "app", "apps"},
 	}
 
+	cli.CompleteNameArgs(cmd, cli.ApplicationsCompletion)
+
 	cmd.AddCommand(NewApplicationListCommand(ctx, c))
 	cmd.AddCommand(NewApplicationCreateCommand(ctx, c))
 	cmd.AddCommand(NewApplicationDeleteCommand(ctx, c))
@@ -26,11 +26,14 @@ import (
 	"github.com/buildpack/pack"
 	"github.com/projectriff/cli/pkg/cli"
 	"github.com/projectriff/cli/pkg/k8s"
+	"github.com/projectriff/cli/pkg/kail"
 	"github.com/projectriff/cli/pkg/race"
+	"github.com/projectriff/cli/pkg/validation"
 	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiruntime "k8s.io/apimachinery/pkg/runtime"
 )
 
 type ApplicationCreateOptions struct {
@@ -54,6 +57,7 @@ var (
 	_ cli.Validatable = (*ApplicationCreateOptions)(nil)
 	_ cli.Executable  = (*ApplicationCreateOptions)(nil)
 	_ cli.DryRunable  = (*ApplicationCreateOptions)(nil)
+	_ cli.Promptable  = (*ApplicationCreateOptions)(nil)
 )
 
 func (opts *ApplicationCreateOptions) Validate(ctx context.Context) *cli.FieldError {
@@ -63,6 +67,8 @@ func (opts *ApplicationCreateOptions) Validate(ctx context.Context) *cli.FieldEr
 
 	if opts.Image == "" {
 		errs = errs.Also(cli.ErrMissingField(cli.ImageFlagName))
+	} else {
+		errs = errs.Also(validation.BuildImageRef(opts.Image, cli.ImageFlagName))
 	}
 
 	if opts.CacheSize != "" {
@@ -169,7 +175,15 @@ func (opts *ApplicationCreateOptions) Exec(ctx context.Context, c *cli.Config) e
 	}
 
 	if opts.DryRun {
-		cli.DryRunResource(ctx, application, application.GetGroupVersionKind())
+		get := func() (apiruntime.Object, error) {
+			return c.Build().Applications(opts.Namespace).Get(application.Name, metav1.GetOptions{})
+		}
+		create := func() (apiruntime.Object, error) {
+			return k8s.DryRunCreate(c.Build().RESTClient(), "applications", application)
+		}
+		if err := cli.DryRunCreate(ctx, c, application, application.GetGroupVersionKind(), get, create); err != nil {
+			return err
+		}
 	} else {
 		var err error
 		application, err = c.Build().Applications(opts.Namespace).Create(application)
@@ -186,7 +200,7 @@ func (opts *ApplicationCreateOptions) Exec(ctx context.Context, c *cli.Config) e
 				return k8s.WaitUntilReady(ctx, c.Build().RESTClient(), "applications", application)
 			},
 			func(ctx context.Context) error {
-				return c.Kail.ApplicationLogs(ctx, application, cli.TailSinceCreateDefault, c.Stdout)
+				return c.Kail.ApplicationLogs(ctx, application, kail.LogOptions{Since: cli.TailSinceCreateDefault}, c.Stdout)
 			},
 		)
 		if err == context.DeadlineExceeded {
@@ -202,6 +216,18 @@ func (opts *ApplicationCreateOptions) Exec(ctx context.Context, c *cli.Config) e
 	return nil
 }
 
+func (opts *ApplicationCreateOptions) Prompt(ctx context.Context, c *cli.Config, p *cli.Prompter) error {
+	if err := p.OneOf(cli.GitRepoFlagName, cli.LocalPathFlagName); err != nil {
+		return err
+	}
+	if opts.GitRepo != "" {
+		if err := p.Optional(cli.GitRevisionFlagName); err != nil {
+			return err
+		}
+	}
+	return p.Optional(cli.ImageFlagName)
+}
+
 func (opts *ApplicationCreateOptions) IsDryRun() bool {
 	return opts.DryRun
 }
@@ -234,6 +260,7 @@ cluster).
 		cli.NameArg(&opts.Name),
 	)
 
+	cli.InteractiveFlag(ctx, cmd, c, opts)
 	cli.NamespaceFlag(cmd, c, &opts.Namespace)
 	cmd.Flags().StringVar(&opts.Image, cli.StripDash(cli.ImageFlagName), "_", "`repository` where the built images are pushed")
 	cmd.Flags().StringVar(&opts.CacheSize, cli.StripDash(cli.CacheSizeFlagName), "", "`size` of persistent volume to cache resources between builds")
@@ -243,7 +270,7 @@ cluster).
 	cmd.Flags().StringVar(&opts.SubPath, cli.StripDash(cli.SubPathFlagName), "", "path to `directory` within the git repo to checkout")
 	cmd.Flags().BoolVar(&opts.Tail, cli.StripDash(cli.TailFlagName), false, "watch build logs")
 	cmd.Flags().StringVar(&opts.WaitTimeout, cli.StripDash(cli.WaitTimeoutFlagName), "10m", "`duration` to wait for the application to become ready when watching logs")
-	cmd.Flags().BoolVar(&opts.DryRun, cli.StripDash(cli.DryRunFlagName), false, "print kubernetes resources to stdout rather than apply them to the cluster, messages normally on stdout will be sent to stderr")
+	cli.DryRunFlags(cmd, c, &opts.DryRun)
 
 	return cmd
 }
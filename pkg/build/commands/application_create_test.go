@@ -27,6 +27,7 @@ import (
 	"github.com/projectriff/cli/pkg/build/commands"
 	"github.com/projectriff/cli/pkg/cli"
 	"github.com/projectriff/cli/pkg/k8s"
+	riffkail "github.com/projectriff/cli/pkg/kail"
 	rifftesting "github.com/projectriff/cli/pkg/testing"
 	kailtesting "github.com/projectriff/cli/pkg/testing/kail"
 	packtesting "github.com/projectriff/cli/pkg/testing/pack"
@@ -70,6 +71,24 @@ func TestApplicationCreateOptions(t *testing.T) {
 			},
 			ShouldValidate: true,
 		},
+		{
+			Name: "default image with path",
+			Options: &commands.ApplicationCreateOptions{
+				ResourceOptions: rifftesting.ValidResourceOptions,
+				Image:           "_/apps/my-app",
+				LocalPath:       ".",
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "invalid image",
+			Options: &commands.ApplicationCreateOptions{
+				ResourceOptions: rifftesting.ValidResourceOptions,
+				Image:           "registry.example.com//image:",
+				LocalPath:       ".",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("registry.example.com//image:", cli.ImageFlagName),
+		},
 		{
 			Name: "no source",
 			Options: &commands.ApplicationCreateOptions{
@@ -696,7 +715,7 @@ Created application "my-application"
 							},
 						},
 					},
-				}, cli.TailSinceCreateDefault, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				}, riffkail.LogOptions{Since: cli.TailSinceCreateDefault}, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
 					fmt.Fprintf(c.Stdout, "...log output...\n")
 				})
 				return ctx, nil
@@ -755,7 +774,7 @@ Created application "my-application"
 							},
 						},
 					},
-				}, cli.TailSinceCreateDefault, mock.Anything).Return(k8s.ErrWaitTimeout).Run(func(args mock.Arguments) {
+				}, riffkail.LogOptions{Since: cli.TailSinceCreateDefault}, mock.Anything).Return(k8s.ErrWaitTimeout).Run(func(args mock.Arguments) {
 					ctx := args[0].(context.Context)
 					fmt.Fprintf(c.Stdout, "...log output...\n")
 					// wait for context to be cancelled
@@ -826,7 +845,7 @@ To continue watching logs run: riff application tail my-application --namespace
 							},
 						},
 					},
-				}, cli.TailSinceCreateDefault, mock.Anything).Return(fmt.Errorf("kail error"))
+				}, riffkail.LogOptions{Since: cli.TailSinceCreateDefault}, mock.Anything).Return(fmt.Errorf("kail error"))
 				return ctx, nil
 			},
 			CleanUp: func(t *testing.T, ctx context.Context, c *cli.Config) error {
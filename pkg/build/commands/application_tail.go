@@ -20,7 +20,6 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/projectriff/cli/pkg/cli"
 	"github.com/spf13/cobra"
@@ -29,7 +28,7 @@ import (
 
 type ApplicationTailOptions struct {
 	cli.ResourceOptions
-	Since string
+	cli.TailOptions
 }
 
 var (
@@ -42,11 +41,7 @@ func (opts *ApplicationTailOptions) Validate(ctx context.Context) *cli.FieldErro
 
 	errs = errs.Also(opts.ResourceOptions.Validate(ctx))
 
-	if opts.Since != "" {
-		if _, err := time.ParseDuration(opts.Since); err != nil {
-			errs = errs.Also(cli.ErrInvalidValue(opts.Since, cli.SinceFlagName))
-		}
-	}
+	errs = errs.Also(opts.TailOptions.Validate(ctx))
 
 	return errs
 }
@@ -56,12 +51,7 @@ func (opts *ApplicationTailOptions) Exec(ctx context.Context, c *cli.Config) err
 	if err != nil {
 		return err
 	}
-	since := cli.TailSinceDefault
-	if opts.Since != "" {
-		// error is protected by Validate()
-		since, _ = time.ParseDuration(opts.Since)
-	}
-	return c.Kail.ApplicationLogs(ctx, application, since, c.Stdout)
+	return c.Kail.ApplicationLogs(ctx, application, opts.TailOptions.LogOptions(), c.Stdout)
 }
 
 func NewApplicationTailCommand(ctx context.Context, c *cli.Config) *cobra.Command {
@@ -76,10 +66,17 @@ the shell or kill the process.
 
 As new builds are started, the logs are displayed. To show historical logs use
 ` + cli.SinceFlagName + `.
+
+Log lines are written for people to read by default. Use ` + cli.OutputFlagName + ` json to
+write one JSON object per line instead, for processing by other tools.
+
+Use ` + cli.NoFollowFlagName + ` to read existing logs and exit, or ` + cli.PreviousFlagName + ` to read
+the logs of containers from before they last restarted.
 `),
 		Example: strings.Join([]string{
 			fmt.Sprintf("%s application tail my-application", c.Name),
 			fmt.Sprintf("%s application tail my-application %s 1h", c.Name, cli.SinceFlagName),
+			fmt.Sprintf("%s application tail my-application %s json %s error", c.Name, cli.OutputFlagName, cli.GrepFlagName),
 		}, "\n"),
 		PreRunE: cli.ValidateOptions(ctx, opts),
 		RunE:    cli.ExecOptions(ctx, c, opts),
@@ -90,7 +87,7 @@ As new builds are started, the logs are displayed. To show historical logs use
 	)
 
 	cli.NamespaceFlag(cmd, c, &opts.Namespace)
-	cmd.Flags().StringVar(&opts.Since, cli.StripDash(cli.SinceFlagName), "", "time `duration` to start reading logs from")
+	cli.TailFlags(cmd, &opts.TailOptions)
 
 	return cmd
 }
@@ -0,0 +1,44 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"context"
+	"strings"
+
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+func NewBuildCommand(ctx context.Context, c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "build",
+		Short: "build settings shared by functions, applications and containers",
+		Long: strings.TrimSpace(`
+Builds for functions, applications and containers in a namespace share settings
+stored in the 'riff-build' ConfigMap.
+
+To manage build settings, read and write access to the 'riff-build' ConfigMap is
+required for the namespace.
+`),
+		Aliases: []string{"builds"},
+	}
+
+	cmd.AddCommand(NewBuildConfigCommand(ctx, c))
+
+	return cmd
+}
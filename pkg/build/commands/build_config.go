@@ -0,0 +1,101 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/projectriff/cli/pkg/cli/printers"
+	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+	"github.com/spf13/cobra"
+)
+
+const DefaultImagePrefixKey = "default-image-prefix"
+
+func NewBuildConfigCommand(ctx context.Context, c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "build configuration for a namespace",
+		Long: strings.TrimSpace(`
+Build configuration for a namespace.
+
+The supported key is:
+- ` + DefaultImagePrefixKey + `: the repository prefix applied to the image of
+  functions, applications and containers whose image is '_' or starts with '_/'
+`),
+	}
+
+	cmd.AddCommand(NewBuildConfigGetCommand(ctx, c))
+	cmd.AddCommand(NewBuildConfigSetCommand(ctx, c))
+	cmd.AddCommand(NewBuildConfigUnsetCommand(ctx, c))
+
+	return cmd
+}
+
+func keyArg(key *string) cli.Arg {
+	return cli.Arg{
+		Name:   cli.KeyArgumentName,
+		Arity:  1,
+		Values: []string{DefaultImagePrefixKey},
+		Set: func(cmd *cobra.Command, args []string, offset int) error {
+			*key = args[offset]
+			return nil
+		},
+	}
+}
+
+func validateBuildConfigKey(key string) *cli.FieldError {
+	errs := cli.EmptyFieldError
+
+	if key == "" {
+		errs = errs.Also(cli.ErrMissingField(cli.KeyArgumentName))
+	} else if key != DefaultImagePrefixKey {
+		errs = errs.Also(cli.ErrInvalidValue(key, cli.KeyArgumentName))
+	}
+
+	return errs
+}
+
+// defaultImageResources filters resources to those whose image is resolved
+// with the default image prefix.
+func defaultImageResources(resources []imageResource) []imageResource {
+	filtered := []imageResource{}
+	for _, r := range resources {
+		if image := r.resource.GetImage(); image == "_" || strings.HasPrefix(image, "_/") {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// printDefaultImageBuilds prints the images resources resolve to with the
+// default image prefix. Images are unresolved when the prefix is empty.
+func printDefaultImageBuilds(c *cli.Config, resources []imageResource, defaultImagePrefix string) {
+	printer := printers.GetNewTabWriter(c.Stdout)
+	defer printer.Flush()
+	fmt.Fprintf(printer, "BUILD\tIMAGE\n")
+	for _, r := range resources {
+		image, err := buildv1alpha1.ResolveDefaultImage(r.resource, defaultImagePrefix)
+		if err != nil {
+			image = cli.Swarnf("<unresolved>")
+		}
+		fmt.Fprintf(printer, "%s/%s\t%s\n", r.kind, r.resource.GetObjectMeta().GetName(), image)
+	}
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+type BuildConfigGetOptions struct {
+	Namespace string
+	Key       string
+}
+
+var (
+	_ cli.Validatable = (*BuildConfigGetOptions)(nil)
+	_ cli.Executable  = (*BuildConfigGetOptions)(nil)
+)
+
+func (opts *BuildConfigGetOptions) Validate(ctx context.Context) *cli.FieldError {
+	errs := cli.EmptyFieldError
+
+	if opts.Namespace == "" {
+		errs = errs.Also(cli.ErrMissingField(cli.NamespaceFlagName))
+	}
+	errs = errs.Also(validateBuildConfigKey(opts.Key))
+
+	return errs
+}
+
+func (opts *BuildConfigGetOptions) Exec(ctx context.Context, c *cli.Config) error {
+	defaultImagePrefix, err := getDefaultImagePrefix(c, opts.Namespace)
+	if err != nil {
+		return err
+	}
+	if defaultImagePrefix == "" {
+		c.Einfof("%s is not set\n", opts.Key)
+		return nil
+	}
+	c.Printf("%s\n", defaultImagePrefix)
+	return nil
+}
+
+func NewBuildConfigGetCommand(ctx context.Context, c *cli.Config) *cobra.Command {
+	opts := &BuildConfigGetOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "get",
+		Short: "print a build configuration value",
+		Long: strings.TrimSpace(`
+Print the value of a build configuration key for the namespace.
+
+Nothing is printed to stdout when the key is not set.
+`),
+		Example: strings.Join([]string{
+			fmt.Sprintf("%s build config get %s", c.Name, DefaultImagePrefixKey),
+		}, "\n"),
+		PreRunE: cli.ValidateOptions(ctx, opts),
+		RunE:    cli.ExecOptions(ctx, c, opts),
+	}
+
+	cli.Args(cmd,
+		keyArg(&opts.Key),
+	)
+
+	cli.NamespaceFlag(cmd, c, &opts.Namespace)
+
+	return cmd
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/projectriff/cli/pkg/build/commands"
+	"github.com/projectriff/cli/pkg/cli"
+	rifftesting "github.com/projectriff/cli/pkg/testing"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestBuildConfigGetOptions(t *testing.T) {
+	table := rifftesting.OptionsTable{
+		{
+			Name: "valid",
+			Options: &commands.BuildConfigGetOptions{
+				Namespace: "default",
+				Key:       commands.DefaultImagePrefixKey,
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "missing namespace",
+			Options: &commands.BuildConfigGetOptions{
+				Key: commands.DefaultImagePrefixKey,
+			},
+			ExpectFieldError: cli.ErrMissingField(cli.NamespaceFlagName),
+		},
+		{
+			Name: "missing key",
+			Options: &commands.BuildConfigGetOptions{
+				Namespace: "default",
+			},
+			ExpectFieldError: cli.ErrMissingField(cli.KeyArgumentName),
+		},
+		{
+			Name: "unknown key",
+			Options: &commands.BuildConfigGetOptions{
+				Namespace: "default",
+				Key:       "builder",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("builder", cli.KeyArgumentName),
+		},
+	}
+
+	table.Run(t)
+}
+
+func TestBuildConfigGetCommand(t *testing.T) {
+	defaultNamespace := "default"
+
+	table := rifftesting.CommandTable{
+		{
+			Name:        "invalid args",
+			Args:        []string{},
+			ShouldError: true,
+		},
+		{
+			Name: "get default image prefix",
+			Args: []string{commands.DefaultImagePrefixKey},
+			GivenObjects: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      "riff-build",
+					},
+					Data: map[string]string{
+						"default-image-prefix": "registry.example.com/my-team",
+					},
+				},
+			},
+			ExpectOutput: `
+registry.example.com/my-team
+`,
+		},
+		{
+			Name: "not set",
+			Args: []string{commands.DefaultImagePrefixKey},
+			GivenObjects: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      "riff-build",
+					},
+				},
+			},
+			ExpectOutput: `
+default-image-prefix is not set
+`,
+		},
+		{
+			Name: "no riff-build config",
+			Args: []string{commands.DefaultImagePrefixKey},
+			ExpectOutput: `
+default-image-prefix is not set
+`,
+		},
+		{
+			Name: "get error",
+			Args: []string{commands.DefaultImagePrefixKey},
+			WithReactors: []rifftesting.ReactionFunc{
+				rifftesting.InduceFailure("get", "configmaps"),
+			},
+			ShouldError: true,
+		},
+	}
+
+	table.Run(t, commands.NewBuildConfigGetCommand)
+}
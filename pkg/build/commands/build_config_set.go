@@ -0,0 +1,124 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/projectriff/cli/pkg/validation"
+	"github.com/spf13/cobra"
+)
+
+type BuildConfigSetOptions struct {
+	Namespace string
+	Key       string
+	Value     string
+	DryRun    bool
+}
+
+var (
+	_ cli.Validatable = (*BuildConfigSetOptions)(nil)
+	_ cli.Executable  = (*BuildConfigSetOptions)(nil)
+	_ cli.DryRunable  = (*BuildConfigSetOptions)(nil)
+)
+
+func (opts *BuildConfigSetOptions) Validate(ctx context.Context) *cli.FieldError {
+	errs := cli.EmptyFieldError
+
+	if opts.Namespace == "" {
+		errs = errs.Also(cli.ErrMissingField(cli.NamespaceFlagName))
+	}
+	errs = errs.Also(validateBuildConfigKey(opts.Key))
+
+	if opts.Value == "" {
+		errs = errs.Also(cli.ErrMissingField(cli.ValueArgumentName))
+	} else {
+		errs = errs.Also(validation.ImageRepository(opts.Value, cli.ValueArgumentName))
+	}
+
+	return errs
+}
+
+func (opts *BuildConfigSetOptions) Exec(ctx context.Context, c *cli.Config) error {
+	if err := setDefaultImagePrefix(ctx, c, opts.Namespace, opts.Value, opts.DryRun); err != nil {
+		return err
+	}
+	c.Successf("Set default image prefix to %q\n", opts.Value)
+
+	resources, err := listImageResources(c, opts.Namespace)
+	if err != nil {
+		return err
+	}
+	resources = defaultImageResources(resources)
+	if len(resources) == 0 {
+		c.Infof("No builds use the default image prefix.\n")
+		return nil
+	}
+	c.Printf("\n")
+	printDefaultImageBuilds(c, resources, opts.Value)
+
+	return nil
+}
+
+func (opts *BuildConfigSetOptions) IsDryRun() bool {
+	return opts.DryRun
+}
+
+func NewBuildConfigSetCommand(ctx context.Context, c *cli.Config) *cobra.Command {
+	opts := &BuildConfigSetOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "set a build configuration value",
+		Long: strings.TrimSpace(`
+Set the value of a build configuration key for the namespace.
+
+The ` + DefaultImagePrefixKey + ` must be an image repository, like
+'registry.example.com/my-team'. The functions, applications and containers that
+use the default image prefix are listed with the image each resolves to. Images
+are resolved when the resource is next reconciled.
+
+Credentials for the registry are managed independently with the credential
+commands.
+`),
+		Example: strings.Join([]string{
+			fmt.Sprintf("%s build config set %s registry.example.com/my-team", c.Name, DefaultImagePrefixKey),
+		}, "\n"),
+		PreRunE: cli.ValidateOptions(ctx, opts),
+		RunE:    cli.ExecOptions(ctx, c, opts),
+	}
+
+	cli.Args(cmd,
+		keyArg(&opts.Key),
+		cli.Arg{
+			Name:  cli.ValueArgumentName,
+			Arity: 1,
+			Set: func(cmd *cobra.Command, args []string, offset int) error {
+				opts.Value = args[offset]
+				return nil
+			},
+		},
+	)
+
+	cli.NamespaceFlag(cmd, c, &opts.Namespace)
+	cli.DryRunFlags(cmd, c, &opts.DryRun)
+
+	return cmd
+}
@@ -0,0 +1,288 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/projectriff/cli/pkg/build/commands"
+	"github.com/projectriff/cli/pkg/cli"
+	rifftesting "github.com/projectriff/cli/pkg/testing"
+	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestBuildConfigSetOptions(t *testing.T) {
+	table := rifftesting.OptionsTable{
+		{
+			Name: "valid",
+			Options: &commands.BuildConfigSetOptions{
+				Namespace: "default",
+				Key:       commands.DefaultImagePrefixKey,
+				Value:     "registry.example.com/my-team",
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "missing namespace",
+			Options: &commands.BuildConfigSetOptions{
+				Key:   commands.DefaultImagePrefixKey,
+				Value: "registry.example.com/my-team",
+			},
+			ExpectFieldError: cli.ErrMissingField(cli.NamespaceFlagName),
+		},
+		{
+			Name: "unknown key",
+			Options: &commands.BuildConfigSetOptions{
+				Namespace: "default",
+				Key:       "builder",
+				Value:     "registry.example.com/my-team",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("builder", cli.KeyArgumentName),
+		},
+		{
+			Name: "missing value",
+			Options: &commands.BuildConfigSetOptions{
+				Namespace: "default",
+				Key:       commands.DefaultImagePrefixKey,
+			},
+			ExpectFieldError: cli.ErrMissingField(cli.ValueArgumentName),
+		},
+		{
+			Name: "invalid value",
+			Options: &commands.BuildConfigSetOptions{
+				Namespace: "default",
+				Key:       commands.DefaultImagePrefixKey,
+				Value:     "registry.example.com//my-team",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("registry.example.com//my-team", cli.ValueArgumentName),
+		},
+		{
+			Name: "dry run",
+			Options: &commands.BuildConfigSetOptions{
+				Namespace: "default",
+				Key:       commands.DefaultImagePrefixKey,
+				Value:     "registry.example.com/my-team",
+				DryRun:    true,
+			},
+			ShouldValidate: true,
+		},
+	}
+
+	table.Run(t)
+}
+
+func TestBuildConfigSetCommand(t *testing.T) {
+	defaultNamespace := "default"
+	prefix := "registry.example.com/my-team"
+
+	function := &buildv1alpha1.Function{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: defaultNamespace,
+			Name:      "my-function",
+		},
+		Spec: buildv1alpha1.FunctionSpec{
+			Image: "_",
+		},
+	}
+	application := &buildv1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: defaultNamespace,
+			Name:      "my-application",
+		},
+		Spec: buildv1alpha1.ApplicationSpec{
+			Image: "_/apps/my-application",
+		},
+	}
+	container := &buildv1alpha1.Container{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: defaultNamespace,
+			Name:      "my-container",
+		},
+		Spec: buildv1alpha1.ContainerSpec{
+			Image: "docker.io/projectriff/my-container",
+		},
+	}
+
+	table := rifftesting.CommandTable{
+		{
+			Name:        "invalid args",
+			Args:        []string{commands.DefaultImagePrefixKey},
+			ShouldError: true,
+		},
+		{
+			Name: "create riff-build config",
+			Args: []string{commands.DefaultImagePrefixKey, prefix},
+			GivenObjects: []runtime.Object{
+				function,
+				application,
+				container,
+			},
+			ExpectCreates: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      "riff-build",
+					},
+					Data: map[string]string{
+						"default-image-prefix": prefix,
+					},
+				},
+			},
+			ExpectOutput: `
+Set default image prefix to "registry.example.com/my-team"
+
+BUILD                        IMAGE
+function/my-function         registry.example.com/my-team/my-function
+application/my-application   registry.example.com/my-team/apps/my-application
+`,
+		},
+		{
+			Name: "update riff-build config",
+			Args: []string{commands.DefaultImagePrefixKey, prefix},
+			GivenObjects: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      "riff-build",
+					},
+					Data: map[string]string{
+						"default-image-prefix": "docker.io/projectriff",
+						"other":                "value",
+					},
+				},
+				container,
+			},
+			ExpectUpdates: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      "riff-build",
+					},
+					Data: map[string]string{
+						"default-image-prefix": prefix,
+						"other":                "value",
+					},
+				},
+			},
+			ExpectOutput: `
+Set default image prefix to "registry.example.com/my-team"
+No builds use the default image prefix.
+`,
+		},
+		{
+			Name: "update riff-build config without data",
+			Args: []string{commands.DefaultImagePrefixKey, prefix},
+			GivenObjects: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      "riff-build",
+					},
+				},
+			},
+			ExpectUpdates: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      "riff-build",
+					},
+					Data: map[string]string{
+						"default-image-prefix": prefix,
+					},
+				},
+			},
+			ExpectOutput: `
+Set default image prefix to "registry.example.com/my-team"
+No builds use the default image prefix.
+`,
+		},
+		{
+			Name: "dry run",
+			Args: []string{commands.DefaultImagePrefixKey, prefix, cli.DryRunFlagName},
+			GivenObjects: []runtime.Object{
+				function,
+			},
+			ExpectOutput: `
+---
+apiVersion: v1
+data:
+  default-image-prefix: registry.example.com/my-team
+kind: ConfigMap
+metadata:
+  creationTimestamp: null
+  name: riff-build
+  namespace: default
+
+Set default image prefix to "registry.example.com/my-team"
+
+BUILD                  IMAGE
+function/my-function   registry.example.com/my-team/my-function
+`,
+		},
+		{
+			Name: "get error",
+			Args: []string{commands.DefaultImagePrefixKey, prefix},
+			WithReactors: []rifftesting.ReactionFunc{
+				rifftesting.InduceFailure("get", "configmaps"),
+			},
+			ShouldError: true,
+		},
+		{
+			Name: "create error",
+			Args: []string{commands.DefaultImagePrefixKey, prefix},
+			WithReactors: []rifftesting.ReactionFunc{
+				rifftesting.InduceFailure("create", "configmaps"),
+			},
+			ExpectCreates: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      "riff-build",
+					},
+					Data: map[string]string{
+						"default-image-prefix": prefix,
+					},
+				},
+			},
+			ShouldError: true,
+		},
+		{
+			Name: "list builds error",
+			Args: []string{commands.DefaultImagePrefixKey, prefix},
+			WithReactors: []rifftesting.ReactionFunc{
+				rifftesting.InduceFailure("list", "functions"),
+			},
+			ExpectCreates: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      "riff-build",
+					},
+					Data: map[string]string{
+						"default-image-prefix": prefix,
+					},
+				},
+			},
+			ShouldError: true,
+		},
+	}
+
+	table.Run(t, commands.NewBuildConfigSetCommand)
+}
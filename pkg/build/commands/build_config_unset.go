@@ -0,0 +1,130 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/projectriff/cli/pkg/k8s"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type BuildConfigUnsetOptions struct {
+	Namespace string
+	Key       string
+	DryRun    bool
+}
+
+var (
+	_ cli.Validatable = (*BuildConfigUnsetOptions)(nil)
+	_ cli.Executable  = (*BuildConfigUnsetOptions)(nil)
+	_ cli.DryRunable  = (*BuildConfigUnsetOptions)(nil)
+)
+
+func (opts *BuildConfigUnsetOptions) Validate(ctx context.Context) *cli.FieldError {
+	errs := cli.EmptyFieldError
+
+	if opts.Namespace == "" {
+		errs = errs.Also(cli.ErrMissingField(cli.NamespaceFlagName))
+	}
+	errs = errs.Also(validateBuildConfigKey(opts.Key))
+
+	return errs
+}
+
+func (opts *BuildConfigUnsetOptions) Exec(ctx context.Context, c *cli.Config) error {
+	riffBuildConfig, err := c.Core().ConfigMaps(opts.Namespace).Get("riff-build", metav1.GetOptions{})
+	if err != nil && !apierrs.IsNotFound(err) {
+		return err
+	}
+	if err != nil || riffBuildConfig.Data[opts.Key] == "" {
+		c.Infof("%s is not set\n", opts.Key)
+		return nil
+	}
+
+	live := riffBuildConfig
+	riffBuildConfig = riffBuildConfig.DeepCopy()
+	delete(riffBuildConfig.Data, opts.Key)
+	if opts.DryRun {
+		update := func() (runtime.Object, error) {
+			return k8s.DryRunUpdate(c.Core().RESTClient(), "configmaps", riffBuildConfig)
+		}
+		if err := cli.DryRunApply(ctx, c, live, riffBuildConfig, corev1.SchemeGroupVersion.WithKind("ConfigMap"), update); err != nil {
+			return err
+		}
+	} else {
+		_, err := c.Core().ConfigMaps(opts.Namespace).Update(riffBuildConfig)
+		if err != nil {
+			return err
+		}
+	}
+	c.Successf("Unset default image prefix\n")
+
+	resources, err := listImageResources(c, opts.Namespace)
+	if err != nil {
+		return err
+	}
+	resources = defaultImageResources(resources)
+	if len(resources) == 0 {
+		return nil
+	}
+	c.Printf("\n")
+	c.Infof("Builds using the default image prefix will fail to resolve their image:\n")
+	printDefaultImageBuilds(c, resources, "")
+
+	return nil
+}
+
+func (opts *BuildConfigUnsetOptions) IsDryRun() bool {
+	return opts.DryRun
+}
+
+func NewBuildConfigUnsetCommand(ctx context.Context, c *cli.Config) *cobra.Command {
+	opts := &BuildConfigUnsetOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "unset",
+		Short: "remove a build configuration value",
+		Long: strings.TrimSpace(`
+Remove a build configuration key for the namespace.
+
+Functions, applications and containers that use the default image prefix are
+listed, as they will fail to resolve their image until a prefix is set again.
+`),
+		Example: strings.Join([]string{
+			fmt.Sprintf("%s build config unset %s", c.Name, DefaultImagePrefixKey),
+		}, "\n"),
+		PreRunE: cli.ValidateOptions(ctx, opts),
+		RunE:    cli.ExecOptions(ctx, c, opts),
+	}
+
+	cli.Args(cmd,
+		keyArg(&opts.Key),
+	)
+
+	cli.NamespaceFlag(cmd, c, &opts.Namespace)
+	cli.DryRunFlags(cmd, c, &opts.DryRun)
+
+	return cmd
+}
@@ -0,0 +1,231 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/projectriff/cli/pkg/build/commands"
+	"github.com/projectriff/cli/pkg/cli"
+	rifftesting "github.com/projectriff/cli/pkg/testing"
+	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestBuildConfigUnsetOptions(t *testing.T) {
+	table := rifftesting.OptionsTable{
+		{
+			Name: "valid",
+			Options: &commands.BuildConfigUnsetOptions{
+				Namespace: "default",
+				Key:       commands.DefaultImagePrefixKey,
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "missing namespace",
+			Options: &commands.BuildConfigUnsetOptions{
+				Key: commands.DefaultImagePrefixKey,
+			},
+			ExpectFieldError: cli.ErrMissingField(cli.NamespaceFlagName),
+		},
+		{
+			Name: "unknown key",
+			Options: &commands.BuildConfigUnsetOptions{
+				Namespace: "default",
+				Key:       "builder",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("builder", cli.KeyArgumentName),
+		},
+		{
+			Name: "dry run",
+			Options: &commands.BuildConfigUnsetOptions{
+				Namespace: "default",
+				Key:       commands.DefaultImagePrefixKey,
+				DryRun:    true,
+			},
+			ShouldValidate: true,
+		},
+	}
+
+	table.Run(t)
+}
+
+func TestBuildConfigUnsetCommand(t *testing.T) {
+	defaultNamespace := "default"
+
+	riffBuild := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: defaultNamespace,
+			Name:      "riff-build",
+		},
+		Data: map[string]string{
+			"default-image-prefix": "registry.example.com/my-team",
+			"other":                "value",
+		},
+	}
+	function := &buildv1alpha1.Function{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: defaultNamespace,
+			Name:      "my-function",
+		},
+		Spec: buildv1alpha1.FunctionSpec{
+			Image: "_",
+		},
+	}
+
+	table := rifftesting.CommandTable{
+		{
+			Name:        "invalid args",
+			Args:        []string{},
+			ShouldError: true,
+		},
+		{
+			Name: "unset default image prefix",
+			Args: []string{commands.DefaultImagePrefixKey},
+			GivenObjects: []runtime.Object{
+				riffBuild,
+				function,
+			},
+			ExpectUpdates: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      "riff-build",
+					},
+					Data: map[string]string{
+						"other": "value",
+					},
+				},
+			},
+			ExpectOutput: `
+Unset default image prefix
+
+Builds using the default image prefix will fail to resolve their image:
+BUILD                  IMAGE
+function/my-function   <unresolved>
+`,
+		},
+		{
+			Name: "no builds affected",
+			Args: []string{commands.DefaultImagePrefixKey},
+			GivenObjects: []runtime.Object{
+				riffBuild,
+			},
+			ExpectUpdates: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      "riff-build",
+					},
+					Data: map[string]string{
+						"other": "value",
+					},
+				},
+			},
+			ExpectOutput: `
+Unset default image prefix
+`,
+		},
+		{
+			Name: "not set",
+			Args: []string{commands.DefaultImagePrefixKey},
+			ExpectOutput: `
+default-image-prefix is not set
+`,
+		},
+		{
+			Name: "dry run",
+			Args: []string{commands.DefaultImagePrefixKey, cli.DryRunFlagName},
+			GivenObjects: []runtime.Object{
+				riffBuild,
+			},
+			ExpectOutput: `
+---
+apiVersion: v1
+data:
+  other: value
+kind: ConfigMap
+metadata:
+  creationTimestamp: null
+  name: riff-build
+  namespace: default
+
+Unset default image prefix
+`,
+		},
+		{
+			Name: "get error",
+			Args: []string{commands.DefaultImagePrefixKey},
+			GivenObjects: []runtime.Object{
+				riffBuild,
+			},
+			WithReactors: []rifftesting.ReactionFunc{
+				rifftesting.InduceFailure("get", "configmaps"),
+			},
+			ShouldError: true,
+		},
+		{
+			Name: "update error",
+			Args: []string{commands.DefaultImagePrefixKey},
+			GivenObjects: []runtime.Object{
+				riffBuild,
+			},
+			WithReactors: []rifftesting.ReactionFunc{
+				rifftesting.InduceFailure("update", "configmaps"),
+			},
+			ExpectUpdates: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      "riff-build",
+					},
+					Data: map[string]string{
+						"other": "value",
+					},
+				},
+			},
+			ShouldError: true,
+		},
+		{
+			Name: "list builds error",
+			Args: []string{commands.DefaultImagePrefixKey},
+			GivenObjects: []runtime.Object{
+				riffBuild,
+			},
+			WithReactors: []rifftesting.ReactionFunc{
+				rifftesting.InduceFailure("list", "functions"),
+			},
+			ExpectUpdates: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      "riff-build",
+					},
+					Data: map[string]string{
+						"other": "value",
+					},
+				},
+			},
+			ShouldError: true,
+		},
+	}
+
+	table.Run(t, commands.NewBuildConfigUnsetCommand)
+}
@@ -1,11 +1,11 @@
 /*
- * Copyright 2019 The original author or authors
+ * Copyright 2019 the original author or authors.
  *
  * Licensed under the Apache License, Version 2.0 (the "License");
  * you may not use this file except in compliance with the License.
  * You may obtain a copy of the License at
  *
- *     https://www.apache.org/licenses/LICENSE-2.0
+ *      https://www.apache.org/licenses/LICENSE-2.0
  *
  * Unless required by applicable law or agreed to in writing, software
  * distributed under the License is distributed on an "AS IS" BASIS,
@@ -14,12 +14,22 @@
  * limitations under the License.
  */
 
-package kail_test
+package commands_test
 
 import (
 	"testing"
+
+	"github.com/projectriff/cli/pkg/build/commands"
+	rifftesting "github.com/projectriff/cli/pkg/testing"
 )
 
-func TestStub(t *testing.T) {
-	// TODO add tests for this package and remove this file
+func TestBuildCommand(t *testing.T) {
+	table := rifftesting.CommandTable{
+		{
+			Name: "empty",
+			Args: []string{},
+		},
+	}
+
+	table.Run(t, commands.NewBuildCommand)
 }
@@ -37,6 +37,8 @@ container image may then be deployed to core or knative runtime.
 		Aliases: []string{"containers"},
 	}
 
+	cli.CompleteNameArgs(cmd, cli.ContainersCompletion)
+
 	cmd.AddCommand(NewContainerListCommand(ctx, c))
 	cmd.AddCommand(NewContainerCreateCommand(ctx, c))
 	cmd.AddCommand(NewContainerDeleteCommand(ctx, c))
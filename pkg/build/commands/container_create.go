@@ -25,9 +25,11 @@ import (
 	"github.com/projectriff/cli/pkg/cli"
 	"github.com/projectriff/cli/pkg/k8s"
 	"github.com/projectriff/cli/pkg/race"
+	"github.com/projectriff/cli/pkg/validation"
 	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 type ContainerCreateOptions struct {
@@ -45,6 +47,7 @@ var (
 	_ cli.Validatable = (*ContainerCreateOptions)(nil)
 	_ cli.Executable  = (*ContainerCreateOptions)(nil)
 	_ cli.DryRunable  = (*ContainerCreateOptions)(nil)
+	_ cli.Promptable  = (*ContainerCreateOptions)(nil)
 )
 
 func (opts *ContainerCreateOptions) Validate(ctx context.Context) *cli.FieldError {
@@ -54,6 +57,8 @@ func (opts *ContainerCreateOptions) Validate(ctx context.Context) *cli.FieldErro
 
 	if opts.Image == "" {
 		errs = errs.Also(cli.ErrMissingField(cli.ImageFlagName))
+	} else {
+		errs = errs.Also(validation.BuildImageRef(opts.Image, cli.ImageFlagName))
 	}
 
 	if opts.Tail {
@@ -83,7 +88,15 @@ func (opts *ContainerCreateOptions) Exec(ctx context.Context, c *cli.Config) err
 	}
 
 	if opts.DryRun {
-		cli.DryRunResource(ctx, container, container.GetGroupVersionKind())
+		get := func() (runtime.Object, error) {
+			return c.Build().Containers(opts.Namespace).Get(container.Name, metav1.GetOptions{})
+		}
+		create := func() (runtime.Object, error) {
+			return k8s.DryRunCreate(c.Build().RESTClient(), "containers", container)
+		}
+		if err := cli.DryRunCreate(ctx, c, container, container.GetGroupVersionKind(), get, create); err != nil {
+			return err
+		}
 	} else {
 		var err error
 		container, err = c.Build().Containers(opts.Namespace).Create(container)
@@ -113,6 +126,10 @@ func (opts *ContainerCreateOptions) Exec(ctx context.Context, c *cli.Config) err
 	return nil
 }
 
+func (opts *ContainerCreateOptions) Prompt(ctx context.Context, c *cli.Config, p *cli.Prompter) error {
+	return p.Optional(cli.ImageFlagName)
+}
+
 func (opts *ContainerCreateOptions) IsDryRun() bool {
 	return opts.DryRun
 }
@@ -138,11 +155,12 @@ for containers.
 		cli.NameArg(&opts.Name),
 	)
 
+	cli.InteractiveFlag(ctx, cmd, c, opts)
 	cli.NamespaceFlag(cmd, c, &opts.Namespace)
 	cmd.Flags().StringVar(&opts.Image, cli.StripDash(cli.ImageFlagName), "_", "`repository` where the built images are pushed")
 	cmd.Flags().BoolVar(&opts.Tail, cli.StripDash(cli.TailFlagName), false, "watch build logs")
 	cmd.Flags().StringVar(&opts.WaitTimeout, cli.StripDash(cli.WaitTimeoutFlagName), "10m", "`duration` to wait for the container to become ready when watching logs")
-	cmd.Flags().BoolVar(&opts.DryRun, cli.StripDash(cli.DryRunFlagName), false, "print kubernetes resources to stdout rather than apply them to the cluster, messages normally on stdout will be sent to stderr")
+	cli.DryRunFlags(cmd, c, &opts.DryRun)
 
 	return cmd
 }
@@ -49,6 +49,14 @@ func TestContainerCreateOptions(t *testing.T) {
 			},
 			ShouldValidate: true,
 		},
+		{
+			Name: "invalid image",
+			Options: &commands.ContainerCreateOptions{
+				ResourceOptions: rifftesting.ValidResourceOptions,
+				Image:           "registry.example.com//image:",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("registry.example.com//image:", cli.ImageFlagName),
+		},
 		{
 			Name: "tail",
 			Options: &commands.ContainerCreateOptions{
@@ -166,6 +174,52 @@ spec:
   image: registry.example.com/repo:tag
 status: {}
 
+Created container "my-container"
+`,
+		},
+		{
+			Name: "image server dry run",
+			Args: []string{containerName, cli.ImageFlagName, imageTag, cli.DryRunFlagName + "=" + cli.ServerDryRun},
+			ExpectOutput: `
+---
+apiVersion: build.projectriff.io/v1alpha1
+kind: Container
+metadata:
+  creationTimestamp: null
+  name: my-container
+  namespace: default
+  uid: dry-run-uid
+spec:
+  image: registry.example.com/repo:tag
+status: {}
+
+Created container "my-container"
+`,
+		},
+		{
+			Name: "image diff",
+			Args: []string{containerName, cli.ImageFlagName, imageTag, cli.DiffFlagName},
+			GivenObjects: []runtime.Object{
+				&buildv1alpha1.Container{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      containerName,
+					},
+					Spec: buildv1alpha1.ContainerSpec{
+						Image: imageDefault,
+					},
+				},
+			},
+			ExpectOutput: `
+--- live/Container/my-container
++++ dry-run/Container/my-container
+@@ -5,5 +5,5 @@
+   name: my-container
+   namespace: default
+ spec:
+-  image: _
++  image: registry.example.com/repo:tag
+ status: {}
 Created container "my-container"
 `,
 		},
@@ -46,7 +46,10 @@ namespace. To manage the default image prefix, read and write access to the
 		Aliases: []string{"credentials", "cred", "creds"},
 	}
 
+	cli.CompleteNameArgs(cmd, cli.CredentialsCompletion)
+
 	cmd.AddCommand(NewCredentialListCommand(ctx, c))
+	cmd.AddCommand(NewCredentialShowCommand(ctx, c))
 	cmd.AddCommand(NewCredentialApplyCommand(ctx, c))
 	cmd.AddCommand(NewCredentialDeleteCommand(ctx, c))
 
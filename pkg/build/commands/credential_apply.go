@@ -18,17 +18,22 @@ package commands
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/projectriff/cli/pkg/cli"
+	"github.com/projectriff/cli/pkg/k8s"
 	"github.com/projectriff/system/pkg/apis/build"
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 type CredentialApplyOptions struct {
@@ -39,13 +44,17 @@ type CredentialApplyOptions struct {
 
 	GcrTokenPath string
 
-	Registry         string
-	RegistryUser     string
-	RegistryPassword []byte
+	DockerConfigPath string
+
+	Registry          string
+	RegistryUser      string
+	RegistryPassword  []byte
+	RegistryTokenPath string
 
 	DefaultImagePrefix    string
 	SetDefaultImagePrefix bool
 
+	Verify bool
 	DryRun bool
 }
 
@@ -53,6 +62,7 @@ var (
 	_ cli.Validatable = (*CredentialApplyOptions)(nil)
 	_ cli.Executable  = (*CredentialApplyOptions)(nil)
 	_ cli.DryRunable  = (*CredentialApplyOptions)(nil)
+	_ cli.Promptable  = (*CredentialApplyOptions)(nil)
 )
 
 func (opts *CredentialApplyOptions) Validate(ctx context.Context) *cli.FieldError {
@@ -76,6 +86,12 @@ func (opts *CredentialApplyOptions) Validate(ctx context.Context) *cli.FieldErro
 		unused = append(unused, cli.GcrFlagName)
 	}
 
+	if opts.DockerConfigPath != "" {
+		used = append(used, cli.DockerConfigFlagName)
+	} else {
+		unused = append(unused, cli.DockerConfigFlagName)
+	}
+
 	if opts.Registry != "" {
 		used = append(used, cli.RegistryFlagName)
 	} else {
@@ -96,6 +112,18 @@ func (opts *CredentialApplyOptions) Validate(ctx context.Context) *cli.FieldErro
 		errs = errs.Also(cli.ErrMissingField(cli.RegistryUserFlagName))
 	}
 
+	if opts.RegistryTokenPath != "" {
+		if opts.Registry == "" {
+			errs = errs.Also(cli.ErrMissingField(cli.RegistryFlagName))
+		}
+		if opts.RegistryUser == "" {
+			errs = errs.Also(cli.ErrMissingField(cli.RegistryUserFlagName))
+		}
+		if len(opts.RegistryPassword) != 0 {
+			errs = errs.Also(cli.ErrMultipleOneOf("<registry-password>", cli.RegistryTokenFileFlagName))
+		}
+	}
+
 	if opts.SetDefaultImagePrefix && opts.DefaultImagePrefix == "" && opts.Registry != "" {
 		errs = errs.Also(cli.ErrInvalidValue(fmt.Sprintf("cannot be used with %s, without %s", cli.RegistryFlagName, cli.DefaultImagePrefixFlagName), cli.SetDefaultImagePrefixFlagName))
 	}
@@ -104,16 +132,37 @@ func (opts *CredentialApplyOptions) Validate(ctx context.Context) *cli.FieldErro
 }
 
 func (opts *CredentialApplyOptions) Exec(ctx context.Context, c *cli.Config) error {
-	// get desired credential and image prefix
-	secret, imagePrefix, err := makeCredential(opts)
+	// get desired credentials and image prefix
+	var secrets []*corev1.Secret
+	var imagePrefix string
+	var err error
+	if opts.DockerConfigPath != "" {
+		secrets, imagePrefix, err = makeDockerConfigCredentials(c, opts)
+	} else {
+		var secret *corev1.Secret
+		secret, imagePrefix, err = makeCredential(opts)
+		secrets = []*corev1.Secret{secret}
+	}
 	if err != nil {
 		return err
 	}
 
-	if err := applyCredential(ctx, c, opts, secret); err != nil {
-		return err
+	if opts.Verify {
+		for _, secret := range secrets {
+			registry := secret.Annotations["build.pivotal.io/docker"]
+			if err := c.Registry.Verify(ctx, registry, secret.StringData["username"], secret.StringData["password"]); err != nil {
+				return err
+			}
+			c.Successf("Verified credentials %q for registry %q\n", secret.Name, registry)
+		}
+	}
+
+	for _, secret := range secrets {
+		if err := applyCredential(ctx, c, opts, secret); err != nil {
+			return err
+		}
+		c.Successf("Apply credentials %q\n", secret.Name)
 	}
-	c.Successf("Apply credentials %q\n", opts.Name)
 
 	if opts.DefaultImagePrefix != "" || opts.SetDefaultImagePrefix {
 		if opts.DefaultImagePrefix != "" {
@@ -123,7 +172,7 @@ func (opts *CredentialApplyOptions) Exec(ctx context.Context, c *cli.Config) err
 			// guarded by opts.Validate()
 			c.Infof("Unable to derive default image prefix\n")
 		} else {
-			err := setDefaultImagePrefix(ctx, c, opts, imagePrefix)
+			err := setDefaultImagePrefix(ctx, c, opts.Namespace, imagePrefix, opts.DryRun)
 			if err != nil {
 				return err
 			}
@@ -134,6 +183,24 @@ func (opts *CredentialApplyOptions) Exec(ctx context.Context, c *cli.Config) err
 	return nil
 }
 
+func (opts *CredentialApplyOptions) Prompt(ctx context.Context, c *cli.Config, p *cli.Prompter) error {
+	if err := p.OneOf(cli.DockerHubFlagName, cli.GcrFlagName, cli.DockerConfigFlagName, cli.RegistryFlagName); err != nil {
+		return err
+	}
+	if opts.Registry != "" {
+		if err := p.Optional(cli.RegistryUserFlagName); err != nil {
+			return err
+		}
+		if opts.RegistryUser != "" {
+			// the password is read from stdin when a token is not given
+			if err := p.Optional(cli.RegistryTokenFileFlagName); err != nil {
+				return err
+			}
+		}
+	}
+	return p.Optional(cli.DefaultImagePrefixFlagName)
+}
+
 func (opts *CredentialApplyOptions) IsDryRun() bool {
 	return opts.DryRun
 }
@@ -157,6 +224,23 @@ The default image prefix depends on the repository and take the form:
 
 Other image prefix values may be defined by specifying ` + cli.DefaultImagePrefixFlagName + `.
 
+Existing docker logins are imported with ` + cli.DockerConfigFlagName + `, creating a credential named
+'<name>-<registry-host>' for each registry in the docker config file. Registries
+managed by a credential helper are skipped, as their passwords are not stored in
+the file. Logins with an identity token are skipped, as builds only use a
+username and password. When the file contains a Docker Hub login, it is used
+for the default image prefix.
+
+Registries that issue access tokens, like Harbor robot accounts or Azure
+Container Registry tokens, are configured with ` + cli.RegistryFlagName + ` and ` + cli.RegistryUserFlagName + `,
+reading the token from the file given by ` + cli.RegistryTokenFileFlagName + ` rather than a
+password from stdin.
+
+The username and password are checked with the registry before the credential
+is saved by specifying ` + cli.VerifyFlagName + `. Credentials are not saved if the registry
+rejects them, can not be reached, does not present a trusted certificate or
+allows anonymous access without checking them.
+
 While multiple credentials can be created in a single namespace, only a single
 default image prefix can be set.
 `),
@@ -167,13 +251,16 @@ default image prefix can be set.
 			fmt.Sprintf("%s credential apply my-gcr-creds %s path/to/token.json %s", c.Name, cli.GcrFlagName, cli.SetDefaultImagePrefixFlagName),
 			fmt.Sprintf("%s credential apply my-registry-creds %s http://registry.example.com %s my-username", c.Name, cli.RegistryFlagName, cli.RegistryUserFlagName),
 			fmt.Sprintf("%s credential apply my-registry-creds %s http://registry.example.com %s my-username %s registry.example.com/my-username", c.Name, cli.RegistryFlagName, cli.RegistryUserFlagName, cli.DefaultImagePrefixFlagName),
+			fmt.Sprintf("%s credential apply my-harbor-creds %s https://harbor.example.com %s 'robot$my-robot' %s path/to/token", c.Name, cli.RegistryFlagName, cli.RegistryUserFlagName, cli.RegistryTokenFileFlagName),
+			fmt.Sprintf("%s credential apply my-logins %s ~/.docker/config.json", c.Name, cli.DockerConfigFlagName),
+			fmt.Sprintf("%s credential apply my-docker-hub-creds %s my-docker-id %s", c.Name, cli.DockerHubFlagName, cli.VerifyFlagName),
 		}, "\n"),
 		PreRunE: cli.Sequence(
 			func(cmd *cobra.Command, args []string) error {
 				if opts.DockerHubId != "" {
 					return cli.ReadStdin(c, &opts.DockerHubPassword, "Docker Hub password")(cmd, args)
 				}
-				if opts.RegistryUser != "" {
+				if opts.RegistryUser != "" && opts.RegistryTokenPath == "" {
 					return cli.ReadStdin(c, &opts.RegistryPassword, "Registry password")(cmd, args)
 				}
 				return nil
@@ -187,14 +274,18 @@ default image prefix can be set.
 		cli.NameArg(&opts.Name),
 	)
 
+	cli.InteractiveFlag(ctx, cmd, c, opts)
 	cli.NamespaceFlag(cmd, c, &opts.Namespace)
 	cmd.Flags().StringVar(&opts.DockerHubId, cli.StripDash(cli.DockerHubFlagName), "", "Docker Hub `username`, the password must be provided via stdin")
 	cmd.Flags().StringVar(&opts.GcrTokenPath, cli.StripDash(cli.GcrFlagName), "", "path to Google Container Registry service account token `file`")
+	cmd.Flags().StringVar(&opts.DockerConfigPath, cli.StripDash(cli.DockerConfigFlagName), "", "path to a docker config `file` to import registry logins from")
 	cmd.Flags().StringVar(&opts.Registry, cli.StripDash(cli.RegistryFlagName), "", "registry `url`")
 	cmd.Flags().StringVar(&opts.RegistryUser, cli.StripDash(cli.RegistryUserFlagName), "", "`username` for a registry, the password must be provided via stdin")
+	cmd.Flags().StringVar(&opts.RegistryTokenPath, cli.StripDash(cli.RegistryTokenFileFlagName), "", fmt.Sprintf("path to a `file` containing an access token for the registry, used with %s instead of a password", cli.RegistryUserFlagName))
 	cmd.Flags().StringVar(&opts.DefaultImagePrefix, cli.StripDash(cli.DefaultImagePrefixFlagName), "", fmt.Sprintf("default `repository` prefix for built images, implies %s", cli.SetDefaultImagePrefixFlagName))
 	cmd.Flags().BoolVar(&opts.SetDefaultImagePrefix, cli.StripDash(cli.SetDefaultImagePrefixFlagName), false, "use this registry as the default for built images")
-	cmd.Flags().BoolVar(&opts.DryRun, cli.StripDash(cli.DryRunFlagName), false, "print kubernetes resources to stdout rather than apply them to the cluster, messages normally on stdout will be sent to stderr")
+	cmd.Flags().BoolVar(&opts.Verify, cli.StripDash(cli.VerifyFlagName), false, "check the credentials with the registry before saving them")
+	cli.DryRunFlags(cmd, c, &opts.DryRun)
 
 	return cmd
 }
@@ -251,6 +342,25 @@ func makeCredential(opts *CredentialApplyOptions) (*corev1.Secret, string, error
 		}
 		defaultPrefix = fmt.Sprintf("gcr.io/%s", tokenMap["project_id"])
 
+	case opts.RegistryTokenPath != "":
+		token, err := ioutil.ReadFile(opts.RegistryTokenPath)
+		if err != nil {
+			return nil, "", err
+		}
+		secret.Labels = map[string]string{
+			build.CredentialLabelKey: "token",
+		}
+		secret.Annotations = map[string]string{
+			"build.knative.dev/docker-0": opts.Registry,
+			"build.pivotal.io/docker":    opts.Registry,
+		}
+		secret.Type = corev1.SecretTypeBasicAuth
+		secret.StringData = map[string]string{
+			"username": opts.RegistryUser,
+			"password": strings.TrimSpace(string(token)),
+		}
+		// unable to determine default prefix for registry
+
 	case opts.RegistryUser != "":
 		secret.Labels = map[string]string{
 			build.CredentialLabelKey: "basic-auth",
@@ -270,11 +380,111 @@ func makeCredential(opts *CredentialApplyOptions) (*corev1.Secret, string, error
 	return secret, defaultPrefix, nil
 }
 
-func setDefaultImagePrefix(ctx context.Context, c *cli.Config, opts *CredentialApplyOptions, defaultImagePrefix string) error {
+type dockerConfigFile struct {
+	Auths map[string]dockerConfigAuth `json:"auths"`
+}
+
+type dockerConfigAuth struct {
+	Auth          string `json:"auth,omitempty"`
+	Username      string `json:"username,omitempty"`
+	Password      string `json:"password,omitempty"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+}
+
+var invalidNameChars = regexp.MustCompile("[^a-z0-9-]+")
+
+// makeDockerConfigCredentials creates a credential for each registry login in
+// a docker config file. Logins for Docker Hub are normalized to match
+// credentials created with --docker-hub.
+func makeDockerConfigCredentials(c *cli.Config, opts *CredentialApplyOptions) ([]*corev1.Secret, string, error) {
+	content, err := ioutil.ReadFile(opts.DockerConfigPath)
+	if err != nil {
+		return nil, "", err
+	}
+	config := &dockerConfigFile{}
+	if err := json.Unmarshal(content, config); err != nil {
+		return nil, "", fmt.Errorf("invalid docker config %q: %v", opts.DockerConfigPath, err)
+	}
+
+	registries := make([]string, 0, len(config.Auths))
+	for registry := range config.Auths {
+		registries = append(registries, registry)
+	}
+	sort.Strings(registries)
+
+	secrets := []*corev1.Secret{}
+	// registries indexed by the name of their credential
+	names := map[string]string{}
+	defaultPrefix := ""
+	for _, registry := range registries {
+		auth := config.Auths[registry]
+		username, password := auth.Username, auth.Password
+		if auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid auth for registry %q in docker config %q: %v", registry, opts.DockerConfigPath, err)
+			}
+			parts := strings.SplitN(string(decoded), ":", 2)
+			if len(parts) != 2 {
+				return nil, "", fmt.Errorf("invalid auth for registry %q in docker config %q", registry, opts.DockerConfigPath)
+			}
+			username, password = parts[0], parts[1]
+		}
+		if auth.IdentityToken != "" {
+			// builds present a username and password, identity tokens must be exchanged with the registry
+			c.Infof("Skipping registry %q, logins with an identity token can not be used by builds\n", registry)
+			continue
+		}
+		if username == "" || password == "" {
+			// the login is managed by a credential helper
+			continue
+		}
+
+		host := registryHost(registry)
+		name := strings.Trim(fmt.Sprintf("%s-%s", opts.Name, invalidNameChars.ReplaceAllString(strings.ToLower(host), "-")), "-")
+		if other, ok := names[name]; ok {
+			return nil, "", fmt.Errorf("registries %q and %q in docker config %q are both applied as credential %q, remove one of the logins", other, registry, opts.DockerConfigPath, name)
+		}
+		names[name] = registry
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: opts.Namespace,
+				Name:      name,
+				Labels: map[string]string{
+					build.CredentialLabelKey: "basic-auth",
+				},
+				Annotations: map[string]string{
+					"build.knative.dev/docker-0": registry,
+					"build.pivotal.io/docker":    registry,
+				},
+			},
+			Type: corev1.SecretTypeBasicAuth,
+			StringData: map[string]string{
+				"username": username,
+				"password": password,
+			},
+		}
+		if host == "docker.io" {
+			secret.Labels[build.CredentialLabelKey] = "docker-hub"
+			secret.Annotations["build.knative.dev/docker-0"] = "https://index.docker.io/v1/"
+			secret.Annotations["build.pivotal.io/docker"] = "https://index.docker.io/v1/"
+			defaultPrefix = fmt.Sprintf("docker.io/%s", username)
+		}
+		secrets = append(secrets, secret)
+	}
+
+	if len(secrets) == 0 {
+		return nil, "", fmt.Errorf("no registry logins found in docker config %q, logins managed by a credential helper can not be imported", opts.DockerConfigPath)
+	}
+
+	return secrets, defaultPrefix, nil
+}
+
+func setDefaultImagePrefix(ctx context.Context, c *cli.Config, namespace, defaultImagePrefix string, dryRun bool) error {
 	configMapName := "riff-build"
 	defaultImagePrefixKey := "default-image-prefix"
 
-	riffBuildConfig, err := c.Core().ConfigMaps(opts.Namespace).Get(configMapName, metav1.GetOptions{})
+	riffBuildConfig, err := c.Core().ConfigMaps(namespace).Get(configMapName, metav1.GetOptions{})
 	if err != nil {
 		if !apierrs.IsNotFound(err) {
 			return err
@@ -283,41 +493,43 @@ func setDefaultImagePrefix(ctx context.Context, c *cli.Config, opts *CredentialA
 		// create riff-build configmaps
 		riffBuildConfig := &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
-				Namespace: opts.Namespace,
+				Namespace: namespace,
 				Name:      configMapName,
 			},
 			Data: map[string]string{
 				defaultImagePrefixKey: defaultImagePrefix,
 			},
 		}
-		if opts.DryRun {
-			cli.DryRunResource(ctx, riffBuildConfig, corev1.SchemeGroupVersion.WithKind("ConfigMap"))
-		} else {
-			_, err := c.Core().ConfigMaps(opts.Namespace).Create(riffBuildConfig)
-			if err != nil {
-				return err
+		if dryRun {
+			create := func() (runtime.Object, error) {
+				return k8s.DryRunCreate(c.Core().RESTClient(), "configmaps", riffBuildConfig)
 			}
+			return cli.DryRunApply(ctx, c, nil, riffBuildConfig, corev1.SchemeGroupVersion.WithKind("ConfigMap"), create)
 		}
-		return nil
+		_, err := c.Core().ConfigMaps(namespace).Create(riffBuildConfig)
+		return err
 	}
 
 	// update riff-build config
+	live := riffBuildConfig
 	riffBuildConfig = riffBuildConfig.DeepCopy()
+	if riffBuildConfig.Data == nil {
+		riffBuildConfig.Data = map[string]string{}
+	}
 	riffBuildConfig.Data[defaultImagePrefixKey] = defaultImagePrefix
-	if opts.DryRun {
-		cli.DryRunResource(ctx, riffBuildConfig, corev1.SchemeGroupVersion.WithKind("ConfigMap"))
-	} else {
-		_, err := c.Core().ConfigMaps(opts.Namespace).Update(riffBuildConfig)
-		if err != nil {
-			return err
+	if dryRun {
+		update := func() (runtime.Object, error) {
+			return k8s.DryRunUpdate(c.Core().RESTClient(), "configmaps", riffBuildConfig)
 		}
+		return cli.DryRunApply(ctx, c, live, riffBuildConfig, corev1.SchemeGroupVersion.WithKind("ConfigMap"), update)
 	}
-	return nil
+	_, err = c.Core().ConfigMaps(namespace).Update(riffBuildConfig)
+	return err
 }
 
 func applyCredential(ctx context.Context, c *cli.Config, opts *CredentialApplyOptions, desiredSecret *corev1.Secret) error {
 	// look for existing secret
-	existing, err := c.Core().Secrets(opts.Namespace).Get(opts.Name, metav1.GetOptions{})
+	existing, err := c.Core().Secrets(opts.Namespace).Get(desiredSecret.Name, metav1.GetOptions{})
 	if err != nil {
 		if !apierrs.IsNotFound(err) {
 			return err
@@ -325,37 +537,36 @@ func applyCredential(ctx context.Context, c *cli.Config, opts *CredentialApplyOp
 
 		// create secret
 		if opts.DryRun {
-			cli.DryRunResource(ctx, desiredSecret, corev1.SchemeGroupVersion.WithKind("Secret"))
-		} else {
-			_, err := c.Core().Secrets(opts.Namespace).Create(desiredSecret)
-			if err != nil {
-				return err
+			create := func() (runtime.Object, error) {
+				return k8s.DryRunCreate(c.Core().RESTClient(), "secrets", desiredSecret)
 			}
+			return cli.DryRunApply(ctx, c, nil, desiredSecret, corev1.SchemeGroupVersion.WithKind("Secret"), create)
 		}
-
-		return nil
+		_, err := c.Core().Secrets(opts.Namespace).Create(desiredSecret)
+		return err
 	}
 
 	// ensure we are not mutating a non-riff secret
 	if _, ok := existing.Labels[build.CredentialLabelKey]; !ok {
-		return fmt.Errorf("credential %q exists, but is not owned by riff", opts.Name)
+		return fmt.Errorf("credential %q exists, but is not owned by riff", desiredSecret.Name)
+	}
+	// the type of a secret is immutable
+	if existing.Type != desiredSecret.Type {
+		return fmt.Errorf("credential %q exists with type %q, delete it to apply a credential of type %q", desiredSecret.Name, existing.Type, desiredSecret.Type)
 	}
 
 	// update existing secret
-	existing = existing.DeepCopy()
-	existing.Labels[build.CredentialLabelKey] = desiredSecret.Labels[build.CredentialLabelKey]
-	existing.Annotations = desiredSecret.Annotations
-	existing.Type = desiredSecret.Type
-	existing.StringData = desiredSecret.StringData
-	existing.Data = desiredSecret.Data
+	secret := existing.DeepCopy()
+	secret.Labels[build.CredentialLabelKey] = desiredSecret.Labels[build.CredentialLabelKey]
+	secret.Annotations = desiredSecret.Annotations
+	secret.StringData = desiredSecret.StringData
+	secret.Data = desiredSecret.Data
 	if opts.DryRun {
-		cli.DryRunResource(ctx, existing, corev1.SchemeGroupVersion.WithKind("Secret"))
-	} else {
-		_, err := c.Core().Secrets(opts.Namespace).Update(existing)
-		if err != nil {
-			return err
+		update := func() (runtime.Object, error) {
+			return k8s.DryRunUpdate(c.Core().RESTClient(), "secrets", secret)
 		}
+		return cli.DryRunApply(ctx, c, existing, secret, corev1.SchemeGroupVersion.WithKind("Secret"), update)
 	}
-
-	return nil
+	_, err = c.Core().Secrets(opts.Namespace).Update(secret)
+	return err
 }
@@ -17,13 +17,17 @@
 package commands_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
 	"github.com/projectriff/cli/pkg/build/commands"
 	"github.com/projectriff/cli/pkg/cli"
+	"github.com/projectriff/cli/pkg/registry"
 	rifftesting "github.com/projectriff/cli/pkg/testing"
+	registrytesting "github.com/projectriff/cli/pkg/testing/registry"
 	"github.com/projectriff/system/pkg/apis/build"
+	"github.com/stretchr/testify/mock"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -36,7 +40,7 @@ func TestCredentialApplyOptions(t *testing.T) {
 			Options: &commands.CredentialApplyOptions{
 				ResourceOptions: rifftesting.ValidResourceOptions,
 			},
-			ExpectFieldError: cli.ErrMissingOneOf(cli.DockerHubFlagName, cli.GcrFlagName, cli.RegistryFlagName),
+			ExpectFieldError: cli.ErrMissingOneOf(cli.DockerHubFlagName, cli.GcrFlagName, cli.DockerConfigFlagName, cli.RegistryFlagName),
 		},
 		{
 			Name: "invalid namespaced resource",
@@ -44,7 +48,7 @@ func TestCredentialApplyOptions(t *testing.T) {
 				ResourceOptions: rifftesting.InvalidResourceOptions,
 			},
 			ExpectFieldError: rifftesting.InvalidResourceOptionsFieldError.Also(
-				cli.ErrMissingOneOf(cli.DockerHubFlagName, cli.GcrFlagName, cli.RegistryFlagName),
+				cli.ErrMissingOneOf(cli.DockerHubFlagName, cli.GcrFlagName, cli.DockerConfigFlagName, cli.RegistryFlagName),
 			),
 		},
 		{
@@ -101,6 +105,55 @@ func TestCredentialApplyOptions(t *testing.T) {
 			// allow password to be blank
 			ShouldValidate: true,
 		},
+		{
+			Name: "docker config",
+			Options: &commands.CredentialApplyOptions{
+				ResourceOptions:  rifftesting.ValidResourceOptions,
+				DockerConfigPath: "config.json",
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "registry token",
+			Options: &commands.CredentialApplyOptions{
+				ResourceOptions:   rifftesting.ValidResourceOptions,
+				Registry:          "example.com",
+				RegistryUser:      "robot$projectriff",
+				RegistryTokenPath: "token",
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "registry token missing registry",
+			Options: &commands.CredentialApplyOptions{
+				ResourceOptions:   rifftesting.ValidResourceOptions,
+				DockerHubId:       "projectriff",
+				DockerHubPassword: []byte("1password"),
+				RegistryUser:      "robot$projectriff",
+				RegistryTokenPath: "token",
+			},
+			ExpectFieldError: cli.ErrMissingField(cli.RegistryFlagName),
+		},
+		{
+			Name: "registry token missing user",
+			Options: &commands.CredentialApplyOptions{
+				ResourceOptions:   rifftesting.ValidResourceOptions,
+				Registry:          "example.com",
+				RegistryTokenPath: "token",
+			},
+			ExpectFieldError: cli.ErrMissingField(cli.RegistryUserFlagName),
+		},
+		{
+			Name: "registry token and password",
+			Options: &commands.CredentialApplyOptions{
+				ResourceOptions:   rifftesting.ValidResourceOptions,
+				Registry:          "example.com",
+				RegistryUser:      "robot$projectriff",
+				RegistryPassword:  []byte("1password"),
+				RegistryTokenPath: "token",
+			},
+			ExpectFieldError: cli.ErrMultipleOneOf("<registry-password>", cli.RegistryTokenFileFlagName),
+		},
 		{
 			Name: "multiple registries",
 			Options: &commands.CredentialApplyOptions{
@@ -108,12 +161,13 @@ func TestCredentialApplyOptions(t *testing.T) {
 				DockerHubId:       "projectriff",
 				DockerHubPassword: []byte("1password"),
 				GcrTokenPath:      "gcr-credentials.json",
+				DockerConfigPath:  "config.json",
 				Registry:          "example.com",
 				RegistryUser:      "projectriff",
 				RegistryPassword:  []byte("1password"),
 			},
 			ExpectFieldError: rifftesting.InvalidResourceOptionsFieldError.Also(
-				cli.ErrMultipleOneOf(cli.DockerHubFlagName, cli.GcrFlagName, cli.RegistryFlagName),
+				cli.ErrMultipleOneOf(cli.DockerHubFlagName, cli.GcrFlagName, cli.DockerConfigFlagName, cli.RegistryFlagName),
 			),
 		},
 		{
@@ -268,6 +322,283 @@ Apply credentials "test-credential"
 Apply credentials "test-credential"
 `,
 		},
+		{
+			Name:  "create secret registry interactive",
+			Args:  []string{credentialName, cli.InteractiveFlagName},
+			Stdin: []byte(fmt.Sprintf("%s\n%s\n%s\n\n\n%s", cli.RegistryFlagName, registryURL, registryUser, registryPassword)),
+			ExpectCreates: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      credentialName,
+						Namespace: defaultNamespace,
+						Labels:    map[string]string{credentialLabel: "basic-auth"},
+						Annotations: map[string]string{
+							"build.knative.dev/docker-0": registryURL,
+							"build.pivotal.io/docker":    registryURL,
+						},
+					},
+					Type: corev1.SecretTypeBasicAuth,
+					StringData: map[string]string{
+						"username": registryUser,
+						"password": registryPassword,
+					},
+				},
+			},
+			ExpectOutput: `
+Choose one of:
+  1) --docker-hub  Docker Hub username, the password must be provided via stdin
+  2) --gcr  path to Google Container Registry service account token file
+  3) --docker-config  path to a docker config file to import registry logins from
+  4) --registry  registry url
+> --registry registry url
+> --registry-user username for a registry, the password must be provided via stdin
+> --registry-token-file path to a file containing an access token for the registry, used with --registry-user instead of a password
+> --default-image-prefix default repository prefix for built images, implies --set-default-image-prefix
+> 
+To run the same command without prompting:
+  apply test-credential --registry https://example.com --registry-user projectriff
+
+Apply credentials "test-credential"
+`,
+		},
+		{
+			Name: "create secret registry token",
+			Args: []string{credentialName, cli.RegistryFlagName, registryURL, cli.RegistryUserFlagName, "robot$projectriff", cli.RegistryTokenFileFlagName, "./testdata/registry-token"},
+			ExpectCreates: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      credentialName,
+						Namespace: defaultNamespace,
+						Labels:    map[string]string{credentialLabel: "token"},
+						Annotations: map[string]string{
+							"build.knative.dev/docker-0": registryURL,
+							"build.pivotal.io/docker":    registryURL,
+						},
+					},
+					Type: corev1.SecretTypeBasicAuth,
+					StringData: map[string]string{
+						"username": "robot$projectriff",
+						"password": "my-robot-token",
+					},
+				},
+			},
+			ExpectOutput: `
+Apply credentials "test-credential"
+`,
+		},
+		{
+			Name:        "create secret registry token, bad token path",
+			Args:        []string{credentialName, cli.RegistryFlagName, registryURL, cli.RegistryUserFlagName, "robot$projectriff", cli.RegistryTokenFileFlagName, "./testdata/registry-token-badpath"},
+			ShouldError: true,
+		},
+		{
+			Name: "create secrets docker config",
+			Args: []string{credentialName, cli.DockerConfigFlagName, "./testdata/docker-config.json", cli.SetDefaultImagePrefixFlagName},
+			ExpectCreates: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-credential-docker-io",
+						Namespace: defaultNamespace,
+						Labels:    map[string]string{credentialLabel: "docker-hub"},
+						Annotations: map[string]string{
+							"build.knative.dev/docker-0": "https://index.docker.io/v1/",
+							"build.pivotal.io/docker":    "https://index.docker.io/v1/",
+						},
+					},
+					Type: corev1.SecretTypeBasicAuth,
+					StringData: map[string]string{
+						"username": dockerHubId,
+						"password": dockerHubPassword,
+					},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-credential-registry-example-com-5000",
+						Namespace: defaultNamespace,
+						Labels:    map[string]string{credentialLabel: "basic-auth"},
+						Annotations: map[string]string{
+							"build.knative.dev/docker-0": "registry.example.com:5000",
+							"build.pivotal.io/docker":    "registry.example.com:5000",
+						},
+					},
+					Type: corev1.SecretTypeBasicAuth,
+					StringData: map[string]string{
+						"username": "my-user",
+						"password": "registry-password",
+					},
+				},
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      "riff-build",
+					},
+					Data: map[string]string{
+						"default-image-prefix": "docker.io/projectriff",
+					},
+				},
+			},
+			ExpectOutput: `
+Skipping registry "myregistry.azurecr.io", logins with an identity token can not be used by builds
+Apply credentials "test-credential-docker-io"
+Apply credentials "test-credential-registry-example-com-5000"
+Set default image prefix to "docker.io/projectriff"
+`,
+		},
+		{
+			Name: "create secrets docker config verified",
+			Args: []string{credentialName, cli.DockerConfigFlagName, "./testdata/docker-config.json", cli.VerifyFlagName},
+			Prepare: func(t *testing.T, ctx context.Context, c *cli.Config) (context.Context, error) {
+				verifier := &registrytesting.Verifier{}
+				c.Registry = verifier
+				verifier.On("Verify", mock.Anything, "https://index.docker.io/v1/", dockerHubId, dockerHubPassword).Return(nil)
+				verifier.On("Verify", mock.Anything, "registry.example.com:5000", "my-user", "registry-password").Return(nil)
+				return ctx, nil
+			},
+			CleanUp: func(t *testing.T, ctx context.Context, c *cli.Config) error {
+				verifier := c.Registry.(*registrytesting.Verifier)
+				verifier.AssertExpectations(t)
+				return nil
+			},
+			ExpectCreates: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-credential-docker-io",
+						Namespace: defaultNamespace,
+						Labels:    map[string]string{credentialLabel: "docker-hub"},
+						Annotations: map[string]string{
+							"build.knative.dev/docker-0": "https://index.docker.io/v1/",
+							"build.pivotal.io/docker":    "https://index.docker.io/v1/",
+						},
+					},
+					Type: corev1.SecretTypeBasicAuth,
+					StringData: map[string]string{
+						"username": dockerHubId,
+						"password": dockerHubPassword,
+					},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-credential-registry-example-com-5000",
+						Namespace: defaultNamespace,
+						Labels:    map[string]string{credentialLabel: "basic-auth"},
+						Annotations: map[string]string{
+							"build.knative.dev/docker-0": "registry.example.com:5000",
+							"build.pivotal.io/docker":    "registry.example.com:5000",
+						},
+					},
+					Type: corev1.SecretTypeBasicAuth,
+					StringData: map[string]string{
+						"username": "my-user",
+						"password": "registry-password",
+					},
+				},
+			},
+			ExpectOutput: `
+Skipping registry "myregistry.azurecr.io", logins with an identity token can not be used by builds
+Verified credentials "test-credential-docker-io" for registry "https://index.docker.io/v1/"
+Verified credentials "test-credential-registry-example-com-5000" for registry "registry.example.com:5000"
+Apply credentials "test-credential-docker-io"
+Apply credentials "test-credential-registry-example-com-5000"
+`,
+		},
+		{
+			Name:        "create secrets docker config, duplicate registries",
+			Args:        []string{credentialName, cli.DockerConfigFlagName, "./testdata/docker-config-duplicates.json"},
+			ShouldError: true,
+			Verify: func(t *testing.T, output string, err error) {
+				expected := `registries "https://index.docker.io/v1/" and "index.docker.io" in docker config "./testdata/docker-config-duplicates.json" are both applied as credential "test-credential-docker-io", remove one of the logins`
+				if actual := err.Error(); expected != actual {
+					t.Errorf("expected error %q, actual %q", expected, actual)
+				}
+			},
+		},
+		{
+			Name: "create secrets docker config, identity tokens only",
+			Args: []string{credentialName, cli.DockerConfigFlagName, "./testdata/docker-config-identity-token.json"},
+			ExpectOutput: `
+Skipping registry "myregistry.azurecr.io", logins with an identity token can not be used by builds
+`,
+			ShouldError: true,
+		},
+		{
+			Name:        "create secrets docker config, credential helpers",
+			Args:        []string{credentialName, cli.DockerConfigFlagName, "./testdata/docker-config-helpers.json"},
+			ShouldError: true,
+		},
+		{
+			Name:        "create secrets docker config, invalid config",
+			Args:        []string{credentialName, cli.DockerConfigFlagName, "./testdata/docker-config-invalid.json"},
+			ShouldError: true,
+		},
+		{
+			Name:        "create secrets docker config, bad config path",
+			Args:        []string{credentialName, cli.DockerConfigFlagName, "./testdata/docker-config-badpath.json"},
+			ShouldError: true,
+		},
+		{
+			Name:  "create secret verified",
+			Args:  []string{credentialName, cli.RegistryFlagName, registryURL, cli.RegistryUserFlagName, registryUser, cli.VerifyFlagName},
+			Stdin: []byte(registryPassword),
+			Prepare: func(t *testing.T, ctx context.Context, c *cli.Config) (context.Context, error) {
+				verifier := &registrytesting.Verifier{}
+				c.Registry = verifier
+				verifier.On("Verify", mock.Anything, registryURL, registryUser, registryPassword).Return(nil)
+				return ctx, nil
+			},
+			CleanUp: func(t *testing.T, ctx context.Context, c *cli.Config) error {
+				verifier := c.Registry.(*registrytesting.Verifier)
+				verifier.AssertExpectations(t)
+				return nil
+			},
+			ExpectCreates: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      credentialName,
+						Namespace: defaultNamespace,
+						Labels:    map[string]string{credentialLabel: "basic-auth"},
+						Annotations: map[string]string{
+							"build.knative.dev/docker-0": registryURL,
+							"build.pivotal.io/docker":    registryURL,
+						},
+					},
+					Type: corev1.SecretTypeBasicAuth,
+					StringData: map[string]string{
+						"username": registryUser,
+						"password": registryPassword,
+					},
+				},
+			},
+			ExpectOutput: `
+Verified credentials "test-credential" for registry "https://example.com"
+Apply credentials "test-credential"
+`,
+		},
+		{
+			Name:  "create secret verification failed",
+			Args:  []string{credentialName, cli.RegistryFlagName, registryURL, cli.RegistryUserFlagName, registryUser, cli.VerifyFlagName},
+			Stdin: []byte(registryPassword),
+			Prepare: func(t *testing.T, ctx context.Context, c *cli.Config) (context.Context, error) {
+				verifier := &registrytesting.Verifier{}
+				c.Registry = verifier
+				verifier.On("Verify", mock.Anything, registryURL, registryUser, registryPassword).Return(&registry.VerifyError{
+					Registry: registryURL,
+					Reason:   registry.ReasonUnauthorized,
+					Err:      fmt.Errorf("the registry rejected the username and password"),
+				})
+				return ctx, nil
+			},
+			CleanUp: func(t *testing.T, ctx context.Context, c *cli.Config) error {
+				verifier := c.Registry.(*registrytesting.Verifier)
+				verifier.AssertExpectations(t)
+				return nil
+			},
+			ShouldError: true,
+			Verify: func(t *testing.T, output string, err error) {
+				if !registry.IsUnauthorized(err) {
+					t.Errorf("expected unauthorized error, got %v", err)
+				}
+			},
+		},
 		{
 			Name:  "update secret",
 			Args:  []string{credentialName, cli.RegistryFlagName, registryURL, cli.RegistryUserFlagName, registryUser},
@@ -312,6 +643,31 @@ Apply credentials "test-credential"
 Apply credentials "test-credential"
 `,
 		},
+		{
+			Name:  "update secret, type changed",
+			Args:  []string{credentialName, cli.RegistryFlagName, registryURL, cli.RegistryUserFlagName, registryUser},
+			Stdin: []byte(registryPassword),
+			GivenObjects: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      credentialName,
+						Namespace: defaultNamespace,
+						Labels:    map[string]string{credentialLabel: "identity-token"},
+					},
+					Type: corev1.SecretTypeDockerConfigJson,
+					StringData: map[string]string{
+						".dockerconfigjson": `{"auths":{"myregistry.azurecr.io":{"identitytoken":"my-identity-token"}}}`,
+					},
+				},
+			},
+			ShouldError: true,
+			Verify: func(t *testing.T, output string, err error) {
+				expected := `credential "test-credential" exists with type "kubernetes.io/dockerconfigjson", delete it to apply a credential of type "kubernetes.io/basic-auth"`
+				if actual := err.Error(); expected != actual {
+					t.Errorf("expected error %q, actual %q", expected, actual)
+				}
+			},
+		},
 		{
 			Name:  "get error",
 			Args:  []string{credentialName, cli.RegistryFlagName, registryURL, cli.RegistryUserFlagName, registryUser},
@@ -754,6 +1110,7 @@ Set default image prefix to "docker.io/projectriff"
 						Namespace: defaultNamespace,
 						Labels:    map[string]string{credentialLabel: ""},
 					},
+					Type: corev1.SecretTypeBasicAuth,
 				},
 				&corev1.ConfigMap{
 					ObjectMeta: metav1.ObjectMeta{
@@ -62,12 +62,17 @@ func (opts *CredentialListOptions) Exec(ctx context.Context, c *cli.Config) erro
 		return nil
 	}
 
+	builds, err := listCredentialBuilds(c, opts.Namespace)
+	if err != nil {
+		return err
+	}
+
 	tablePrinter := printers.NewTablePrinter(printers.PrintOptions{
 		WithNamespace: opts.AllNamespaces,
 	}).With(func(h printers.PrintHandler) {
 		columns := opts.printColumns()
-		h.TableHandler(columns, opts.printList)
-		h.TableHandler(columns, opts.print)
+		h.TableHandler(columns, opts.printList(builds))
+		h.TableHandler(columns, opts.print(builds))
 	})
 
 	secrets = secrets.DeepCopy()
@@ -84,6 +89,10 @@ func NewCredentialListCommand(ctx context.Context, c *cli.Config) *cobra.Command
 		Short: "table listing of credentials",
 		Long: strings.TrimSpace(`
 List credentials in a namespace or across all namespaces.
+
+The builds column counts the functions, applications and containers in the
+namespace that push to a registry covered by the credential. Credentials that
+no build uses are marked as unused.
 `),
 		Example: strings.Join([]string{
 			fmt.Sprintf("%s credential list", c.Name),
@@ -98,30 +107,40 @@ List credentials in a namespace or across all namespaces.
 	return cmd
 }
 
-func (opts *CredentialListOptions) printList(credentials *corev1.SecretList, printOpts printers.PrintOptions) ([]metav1beta1.TableRow, error) {
-	rows := make([]metav1beta1.TableRow, 0, len(credentials.Items))
-	for i := range credentials.Items {
-		r, err := opts.print(&credentials.Items[i], printOpts)
-		if err != nil {
-			return nil, err
+func (opts *CredentialListOptions) printList(builds credentialBuilds) func(*corev1.SecretList, printers.PrintOptions) ([]metav1beta1.TableRow, error) {
+	print := opts.print(builds)
+	return func(credentials *corev1.SecretList, printOpts printers.PrintOptions) ([]metav1beta1.TableRow, error) {
+		rows := make([]metav1beta1.TableRow, 0, len(credentials.Items))
+		for i := range credentials.Items {
+			r, err := print(&credentials.Items[i], printOpts)
+			if err != nil {
+				return nil, err
+			}
+			rows = append(rows, r...)
 		}
-		rows = append(rows, r...)
+		return rows, nil
 	}
-	return rows, nil
 }
 
-func (opts *CredentialListOptions) print(credential *corev1.Secret, _ printers.PrintOptions) ([]metav1beta1.TableRow, error) {
-	now := time.Now()
-	row := metav1beta1.TableRow{
-		Object: runtime.RawExtension{Object: credential.DeepCopy()},
+func (opts *CredentialListOptions) print(builds credentialBuilds) func(*corev1.Secret, printers.PrintOptions) ([]metav1beta1.TableRow, error) {
+	return func(credential *corev1.Secret, _ printers.PrintOptions) ([]metav1beta1.TableRow, error) {
+		now := time.Now()
+		row := metav1beta1.TableRow{
+			Object: runtime.RawExtension{Object: credential.DeepCopy()},
+		}
+		used := cli.Swarnf("<unused>")
+		if count := len(builds.UsedBy(credential)); count != 0 {
+			used = fmt.Sprintf("%d", count)
+		}
+		row.Cells = append(row.Cells,
+			credential.Name,
+			credential.Labels[build.CredentialLabelKey],
+			credential.Annotations["build.pivotal.io/docker"],
+			used,
+			cli.FormatTimestampSince(credential.CreationTimestamp, now),
+		)
+		return []metav1beta1.TableRow{row}, nil
 	}
-	row.Cells = append(row.Cells,
-		credential.Name,
-		credential.Labels[build.CredentialLabelKey],
-		credential.Annotations["build.pivotal.io/docker"],
-		cli.FormatTimestampSince(credential.CreationTimestamp, now),
-	)
-	return []metav1beta1.TableRow{row}, nil
 }
 
 func (opts *CredentialListOptions) printColumns() []metav1beta1.TableColumnDefinition {
@@ -129,6 +148,7 @@ func (opts *CredentialListOptions) printColumns() []metav1beta1.TableColumnDefin
 		{Name: "Name", Type: "string"},
 		{Name: "Type", Type: "string"},
 		{Name: "Registry", Type: "string"},
+		{Name: "Builds", Type: "string"},
 		{Name: "Age", Type: "string"},
 	}
 }
@@ -24,6 +24,7 @@ import (
 	"github.com/projectriff/cli/pkg/cli"
 	rifftesting "github.com/projectriff/cli/pkg/testing"
 	"github.com/projectriff/system/pkg/apis/build"
+	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -92,8 +93,8 @@ No credentials found.
 				},
 			},
 			ExpectOutput: `
-NAME              TYPE         REGISTRY                      AGE
-test-credential   docker-hub   https://index.docker.io/v1/   <unknown>
+NAME              TYPE         REGISTRY                      BUILDS     AGE
+test-credential   docker-hub   https://index.docker.io/v1/   <unused>   <unknown>
 `,
 		},
 		{
@@ -155,10 +156,10 @@ No credentials found.
 				},
 			},
 			ExpectOutput: `
-NAME         TYPE         REGISTRY                        AGE
-docker-hub   docker-hub   https://index.docker.io/v1/     <unknown>
-gcr          gcr          https://gcr.io                  <unknown>
-registry     basic-auth   https://registry.example.com/   <unknown>
+NAME         TYPE         REGISTRY                        BUILDS     AGE
+docker-hub   docker-hub   https://index.docker.io/v1/     <unused>   <unknown>
+gcr          gcr          https://gcr.io                  <unused>   <unknown>
+registry     basic-auth   https://registry.example.com/   <unused>   <unknown>
 `,
 		},
 		{
@@ -189,9 +190,9 @@ registry     basic-auth   https://registry.example.com/   <unknown>
 				},
 			},
 			ExpectOutput: `
-NAMESPACE         NAME                    TYPE         REGISTRY                      AGE
-default           test-credential         docker-hub   https://index.docker.io/v1/   <unknown>
-other-namespace   test-other-credential   docker-hub   https://index.docker.io/v1/   <unknown>
+NAMESPACE         NAME                    TYPE         REGISTRY                      BUILDS     AGE
+default           test-credential         docker-hub   https://index.docker.io/v1/   <unused>   <unknown>
+other-namespace   test-other-credential   docker-hub   https://index.docker.io/v1/   <unused>   <unknown>
 `,
 		},
 		{
@@ -207,6 +208,75 @@ other-namespace   test-other-credential   docker-hub   https://index.docker.io/v
 			},
 			ExpectOutput: `
 No credentials found.
+`,
+		},
+		{
+			Name: "counts builds using a credential",
+			Args: []string{},
+			GivenObjects: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "docker-hub",
+						Namespace: defaultNamespace,
+						Labels:    map[string]string{credentialLabel: "docker-hub"},
+						Annotations: map[string]string{
+							"build.knative.dev/docker-0": "https://index.docker.io/v1/",
+							"build.pivotal.io/docker":    "https://index.docker.io/v1/",
+						},
+					},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "gcr",
+						Namespace: defaultNamespace,
+						Labels:    map[string]string{credentialLabel: "gcr"},
+						Annotations: map[string]string{
+							"build.knative.dev/docker-0": "https://gcr.io",
+							"build.pivotal.io/docker":    "https://gcr.io",
+						},
+					},
+				},
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "riff-build",
+						Namespace: defaultNamespace,
+					},
+					Data: map[string]string{
+						"default-image-prefix": "docker.io/projectriff",
+					},
+				},
+				&buildv1alpha1.Function{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-function",
+						Namespace: defaultNamespace,
+					},
+					Spec: buildv1alpha1.FunctionSpec{
+						Image: "_",
+					},
+				},
+				&buildv1alpha1.Application{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-application",
+						Namespace: defaultNamespace,
+					},
+					Spec: buildv1alpha1.ApplicationSpec{
+						Image: "projectriff/my-application",
+					},
+				},
+				&buildv1alpha1.Container{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-container",
+						Namespace: otherNamespace,
+					},
+					Spec: buildv1alpha1.ContainerSpec{
+						Image: "gcr.io/projectriff/my-container",
+					},
+				},
+			},
+			ExpectOutput: `
+NAME         TYPE         REGISTRY                      BUILDS     AGE
+docker-hub   docker-hub   https://index.docker.io/v1/   2          <unknown>
+gcr          gcr          https://gcr.io                <unused>   <unknown>
 `,
 		},
 		{
@@ -217,6 +287,23 @@ No credentials found.
 			},
 			ShouldError: true,
 		},
+		{
+			Name: "list builds error",
+			Args: []string{},
+			GivenObjects: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      credentialName,
+						Namespace: defaultNamespace,
+						Labels:    map[string]string{credentialLabel: "docker-hub"},
+					},
+				},
+			},
+			WithReactors: []rifftesting.ReactionFunc{
+				rifftesting.InduceFailure("list", "functions"),
+			},
+			ShouldError: true,
+		},
 	}
 
 	table.Run(t, commands.NewCredentialListCommand)
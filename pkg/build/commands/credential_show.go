@@ -0,0 +1,369 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/projectriff/cli/pkg/cli/printers"
+	"github.com/projectriff/system/pkg/apis/build"
+	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const credentialRegistryAnnotationPrefix = "build.knative.dev/docker-"
+
+type CredentialShowOptions struct {
+	cli.ResourceOptions
+}
+
+var (
+	_ cli.Validatable = (*CredentialShowOptions)(nil)
+	_ cli.Executable  = (*CredentialShowOptions)(nil)
+)
+
+func (opts *CredentialShowOptions) Validate(ctx context.Context) *cli.FieldError {
+	errs := cli.EmptyFieldError
+
+	errs = errs.Also(opts.ResourceOptions.Validate(ctx))
+
+	return errs
+}
+
+func (opts *CredentialShowOptions) Exec(ctx context.Context, c *cli.Config) error {
+	credential, err := c.Core().Secrets(opts.Namespace).Get(opts.Name, metav1.GetOptions{})
+	if err == nil && credential.Labels[build.CredentialLabelKey] == "" {
+		// a secret that is not a riff credential
+		err = apierrs.NewNotFound(corev1.Resource("secrets"), opts.Name)
+	}
+	if err != nil {
+		if !apierrs.IsNotFound(err) {
+			return err
+		}
+		c.Errorf("Credential %q not found\n", fmt.Sprintf("%s/%s", opts.Namespace, opts.Name))
+		return cli.SilenceError(err)
+	}
+
+	defaultImagePrefix, err := getDefaultImagePrefix(c, opts.Namespace)
+	if err != nil {
+		return err
+	}
+	builds, err := listCredentialBuilds(c, opts.Namespace)
+	if err != nil {
+		return err
+	}
+
+	printer := printers.GetNewTabWriter(c.Stdout)
+	fmt.Fprintf(printer, "Name:\t%s\n", credential.Name)
+	fmt.Fprintf(printer, "Type:\t%s\n", credential.Labels[build.CredentialLabelKey])
+	fmt.Fprintf(printer, "Username:\t%s\n", cli.FormatEmptyString(credentialValue(credential, "username")))
+	registries := credentialRegistries(credential)
+	if len(registries) == 0 {
+		fmt.Fprintf(printer, "Registries:\t%s\n", cli.FormatEmptyString(""))
+	}
+	for i, registry := range registries {
+		label := ""
+		if i == 0 {
+			label = "Registries:"
+		}
+		fmt.Fprintf(printer, "%s\t%s\n", label, registry)
+	}
+	switch {
+	case defaultImagePrefix == "":
+		fmt.Fprintf(printer, "Default image prefix:\t%s\n", cli.FormatEmptyString(""))
+	case credentialProducedImagePrefix(credential, defaultImagePrefix):
+		fmt.Fprintf(printer, "Default image prefix:\t%s %s\n", defaultImagePrefix, cli.Ssuccessf("(from this credential)"))
+	default:
+		fmt.Fprintf(printer, "Default image prefix:\t%s %s\n", defaultImagePrefix, cli.Sfaintf("(not from this credential)"))
+	}
+	printer.Flush()
+
+	used := builds.UsedBy(credential)
+	if len(used) == 0 {
+		c.Printf("\n")
+		c.Infof("No builds push to registries covered by this credential.\n")
+		return nil
+	}
+	c.Printf("\n")
+	printer = printers.GetNewTabWriter(c.Stdout)
+	defer printer.Flush()
+	fmt.Fprintf(printer, "BUILD\tIMAGE\n")
+	for _, b := range used {
+		fmt.Fprintf(printer, "%s/%s\t%s\n", b.Kind, b.Name, b.Image)
+	}
+
+	return nil
+}
+
+func NewCredentialShowCommand(ctx context.Context, c *cli.Config) *cobra.Command {
+	opts := &CredentialShowOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "show credential details",
+		Long: strings.TrimSpace(`
+Display details for a credential.
+
+Every registry the credential covers is shown along with the username. The
+password is never displayed.
+
+The namespace's default image prefix is shown, noting whether it was derived
+from this credential. Functions, applications and containers whose images are
+pushed to a registry covered by the credential are listed.
+`),
+		Example: strings.Join([]string{
+			fmt.Sprintf("%s credential show my-creds", c.Name),
+		}, "\n"),
+		PreRunE: cli.ValidateOptions(ctx, opts),
+		RunE:    cli.ExecOptions(ctx, c, opts),
+	}
+
+	cli.Args(cmd,
+		cli.NameArg(&opts.Name),
+	)
+
+	cli.NamespaceFlag(cmd, c, &opts.Namespace)
+
+	return cmd
+}
+
+// credentialValue returns a key from the secret, whether or not the api server
+// has yet moved it from string data.
+func credentialValue(credential *corev1.Secret, key string) string {
+	if value, ok := credential.StringData[key]; ok {
+		return value
+	}
+	return string(credential.Data[key])
+}
+
+// credentialRegistries returns the registries from the docker annotations in
+// index order.
+func credentialRegistries(credential *corev1.Secret) []string {
+	type indexed struct {
+		index    int
+		registry string
+	}
+	found := []indexed{}
+	for key, value := range credential.Annotations {
+		if !strings.HasPrefix(key, credentialRegistryAnnotationPrefix) {
+			continue
+		}
+		index, err := strconv.Atoi(strings.TrimPrefix(key, credentialRegistryAnnotationPrefix))
+		if err != nil {
+			continue
+		}
+		found = append(found, indexed{index: index, registry: value})
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].index < found[j].index
+	})
+	registries := make([]string, len(found))
+	for i := range found {
+		registries[i] = found[i].registry
+	}
+	return registries
+}
+
+// credentialImagePrefix derives the default image prefix credential apply sets
+// for the credential. Basic auth credentials do not imply a prefix.
+func credentialImagePrefix(credential *corev1.Secret) string {
+	switch credential.Labels[build.CredentialLabelKey] {
+	case "docker-hub":
+		return fmt.Sprintf("docker.io/%s", credentialValue(credential, "username"))
+	case "gcr":
+		token := map[string]string{}
+		if err := json.Unmarshal([]byte(credentialValue(credential, "password")), &token); err != nil {
+			return ""
+		}
+		return fmt.Sprintf("gcr.io/%s", token["project_id"])
+	}
+	return ""
+}
+
+// credentialProducedImagePrefix reports whether the default image prefix
+// matches the prefix derived from the credential. For credentials without a
+// derived prefix, the prefix's registry must be covered by the credential.
+func credentialProducedImagePrefix(credential *corev1.Secret, defaultImagePrefix string) bool {
+	if prefix := credentialImagePrefix(credential); prefix != "" {
+		return prefix == defaultImagePrefix
+	}
+	return credentialCoversImage(credential, defaultImagePrefix)
+}
+
+// credentialCoversImage reports whether the image's registry is one of the
+// registries of the credential.
+func credentialCoversImage(credential *corev1.Secret, image string) bool {
+	host := imageRegistryHost(image)
+	for _, registry := range credentialRegistries(credential) {
+		if registryHost(registry) == host {
+			return true
+		}
+	}
+	return false
+}
+
+// registryHost normalizes a registry from a credential annotation to a host.
+func registryHost(registry string) string {
+	host := registry
+	if u, err := url.Parse(registry); err == nil && u.Host != "" {
+		host = u.Host
+	} else {
+		host = strings.SplitN(host, "/", 2)[0]
+	}
+	switch host {
+	case "index.docker.io", "registry-1.docker.io":
+		return "docker.io"
+	}
+	return host
+}
+
+// imageRegistryHost returns the registry host of an image repository, images
+// without a registry are hosted by Docker Hub.
+func imageRegistryHost(image string) string {
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 1 {
+		return "docker.io"
+	}
+	if host := parts[0]; strings.ContainsAny(host, ".:") || host == "localhost" {
+		return registryHost(host)
+	}
+	return "docker.io"
+}
+
+func getDefaultImagePrefix(c *cli.Config, namespace string) (string, error) {
+	riffBuildConfig, err := c.Core().ConfigMaps(namespace).Get("riff-build", metav1.GetOptions{})
+	if err != nil {
+		if apierrs.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return riffBuildConfig.Data["default-image-prefix"], nil
+}
+
+type credentialBuild struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Image     string
+}
+
+type credentialBuilds []credentialBuild
+
+// imageResource is a function, application or container along with the image
+// it was last resolved to, if any.
+type imageResource struct {
+	kind        string
+	resource    buildv1alpha1.ImageResource
+	targetImage string
+}
+
+// listImageResources lists the functions, applications and containers in the
+// namespace, or all namespaces if empty.
+func listImageResources(c *cli.Config, namespace string) ([]imageResource, error) {
+	resources := []imageResource{}
+
+	functions, err := c.Build().Functions(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range functions.Items {
+		function := &functions.Items[i]
+		resources = append(resources, imageResource{"function", function, function.Status.TargetImage})
+	}
+	applications, err := c.Build().Applications(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range applications.Items {
+		application := &applications.Items[i]
+		resources = append(resources, imageResource{"application", application, application.Status.TargetImage})
+	}
+	containers, err := c.Build().Containers(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range containers.Items {
+		container := &containers.Items[i]
+		resources = append(resources, imageResource{"container", container, container.Status.TargetImage})
+	}
+
+	return resources, nil
+}
+
+// listCredentialBuilds lists the functions, applications and containers in the
+// namespace, or all namespaces if empty, along with the image each is pushed to.
+func listCredentialBuilds(c *cli.Config, namespace string) (credentialBuilds, error) {
+	resources, err := listImageResources(c, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	prefixes := map[string]string{}
+	builds := credentialBuilds{}
+	for _, r := range resources {
+		meta := r.resource.GetObjectMeta()
+		image := r.targetImage
+		if image == "" {
+			image = r.resource.GetImage()
+		}
+		if image == "_" || strings.HasPrefix(image, "_/") {
+			prefix, ok := prefixes[meta.GetNamespace()]
+			if !ok {
+				prefix, err = getDefaultImagePrefix(c, meta.GetNamespace())
+				if err != nil {
+					return nil, err
+				}
+				prefixes[meta.GetNamespace()] = prefix
+			}
+			if image, err = buildv1alpha1.ResolveDefaultImage(r.resource, prefix); err != nil {
+				// without a default image prefix the image is not pushed anywhere
+				continue
+			}
+		}
+		builds = append(builds, credentialBuild{
+			Kind:      r.kind,
+			Namespace: meta.GetNamespace(),
+			Name:      meta.GetName(),
+			Image:     image,
+		})
+	}
+
+	return builds, nil
+}
+
+// UsedBy returns the builds in the credential's namespace that push to a
+// registry covered by the credential.
+func (builds credentialBuilds) UsedBy(credential *corev1.Secret) credentialBuilds {
+	used := credentialBuilds{}
+	for _, b := range builds {
+		if b.Namespace == credential.Namespace && credentialCoversImage(credential, b.Image) {
+			used = append(used, b)
+		}
+	}
+	return used
+}
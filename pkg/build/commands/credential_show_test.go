@@ -0,0 +1,319 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/projectriff/cli/pkg/build/commands"
+	rifftesting "github.com/projectriff/cli/pkg/testing"
+	"github.com/projectriff/system/pkg/apis/build"
+	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestCredentialShowOptions(t *testing.T) {
+	table := rifftesting.OptionsTable{
+		{
+			Name: "invalid resource",
+			Options: &commands.CredentialShowOptions{
+				ResourceOptions: rifftesting.InvalidResourceOptions,
+			},
+			ExpectFieldError: rifftesting.InvalidResourceOptionsFieldError,
+		},
+		{
+			Name: "valid resource",
+			Options: &commands.CredentialShowOptions{
+				ResourceOptions: rifftesting.ValidResourceOptions,
+			},
+			ShouldValidate: true,
+		},
+	}
+
+	table.Run(t)
+}
+
+func TestCredentialShowCommand(t *testing.T) {
+	defaultNamespace := "default"
+	otherNamespace := "other-namespace"
+	credentialName := "my-creds"
+
+	dockerHub := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      credentialName,
+			Namespace: defaultNamespace,
+			Labels:    map[string]string{build.CredentialLabelKey: "docker-hub"},
+			Annotations: map[string]string{
+				"build.knative.dev/docker-0": "https://index.docker.io/v1/",
+				"build.pivotal.io/docker":    "https://index.docker.io/v1/",
+			},
+		},
+		Type: corev1.SecretTypeBasicAuth,
+		Data: map[string][]byte{
+			"username": []byte("projectriff"),
+			"password": []byte("docker-password"),
+		},
+	}
+	gcr := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      credentialName,
+			Namespace: defaultNamespace,
+			Labels:    map[string]string{build.CredentialLabelKey: "gcr"},
+			Annotations: map[string]string{
+				"build.knative.dev/docker-0":  "https://gcr.io",
+				"build.knative.dev/docker-1":  "https://us.gcr.io",
+				"build.knative.dev/docker-2":  "https://eu.gcr.io",
+				"build.knative.dev/docker-3":  "https://asia.gcr.io",
+				"build.knative.dev/docker-10": "https://marketplace.gcr.io",
+				"build.pivotal.io/docker":     "https://gcr.io",
+			},
+		},
+		Type: corev1.SecretTypeBasicAuth,
+		Data: map[string][]byte{
+			"username": []byte("_json_key"),
+			"password": []byte(`{"project_id":"my-gcp-project"}`),
+		},
+	}
+	riffBuild := func(prefix string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "riff-build",
+				Namespace: defaultNamespace,
+			},
+			Data: map[string]string{
+				"default-image-prefix": prefix,
+			},
+		}
+	}
+
+	table := rifftesting.CommandTable{
+		{
+			Name:        "invalid args",
+			Args:        []string{},
+			ShouldError: true,
+		},
+		{
+			Name: "show docker hub credential",
+			Args: []string{credentialName},
+			GivenObjects: []runtime.Object{
+				dockerHub,
+				riffBuild("docker.io/projectriff"),
+				&buildv1alpha1.Function{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-function",
+						Namespace: defaultNamespace,
+					},
+					Spec: buildv1alpha1.FunctionSpec{
+						Image: "_",
+					},
+				},
+				&buildv1alpha1.Application{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-application",
+						Namespace: defaultNamespace,
+					},
+					Spec: buildv1alpha1.ApplicationSpec{
+						Image: "_/apps/my-application",
+					},
+					Status: buildv1alpha1.ApplicationStatus{
+						BuildStatus: buildv1alpha1.BuildStatus{
+							TargetImage: "docker.io/projectriff/apps/my-application",
+						},
+					},
+				},
+				&buildv1alpha1.Container{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-container",
+						Namespace: defaultNamespace,
+					},
+					Spec: buildv1alpha1.ContainerSpec{
+						Image: "gcr.io/my-gcp-project/my-container",
+					},
+				},
+				&buildv1alpha1.Function{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-other-function",
+						Namespace: otherNamespace,
+					},
+					Spec: buildv1alpha1.FunctionSpec{
+						Image: "projectriff/my-other-function",
+					},
+				},
+			},
+			ExpectOutput: `
+Name:                   my-creds
+Type:                   docker-hub
+Username:               projectriff
+Registries:             https://index.docker.io/v1/
+Default image prefix:   docker.io/projectriff (from this credential)
+
+BUILD                        IMAGE
+function/my-function         docker.io/projectriff/my-function
+application/my-application   docker.io/projectriff/apps/my-application
+`,
+		},
+		{
+			Name: "show gcr credential",
+			Args: []string{credentialName},
+			GivenObjects: []runtime.Object{
+				gcr,
+				riffBuild("docker.io/projectriff"),
+				&buildv1alpha1.Container{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-container",
+						Namespace: defaultNamespace,
+					},
+					Spec: buildv1alpha1.ContainerSpec{
+						Image: "eu.gcr.io/my-gcp-project/my-container",
+					},
+				},
+			},
+			ExpectOutput: `
+Name:                   my-creds
+Type:                   gcr
+Username:               _json_key
+Registries:             https://gcr.io
+                        https://us.gcr.io
+                        https://eu.gcr.io
+                        https://asia.gcr.io
+                        https://marketplace.gcr.io
+Default image prefix:   docker.io/projectriff (not from this credential)
+
+BUILD                    IMAGE
+container/my-container   eu.gcr.io/my-gcp-project/my-container
+`,
+		},
+		{
+			Name: "show registry credential",
+			Args: []string{credentialName},
+			GivenObjects: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      credentialName,
+						Namespace: defaultNamespace,
+						Labels:    map[string]string{build.CredentialLabelKey: "basic-auth"},
+						Annotations: map[string]string{
+							"build.knative.dev/docker-0": "https://registry.example.com:5000",
+							"build.pivotal.io/docker":    "https://registry.example.com:5000",
+						},
+					},
+					Type: corev1.SecretTypeBasicAuth,
+					StringData: map[string]string{
+						"username": "my-user",
+						"password": "registry-password",
+					},
+				},
+				riffBuild("registry.example.com:5000/my-user"),
+			},
+			ExpectOutput: `
+Name:                   my-creds
+Type:                   basic-auth
+Username:               my-user
+Registries:             https://registry.example.com:5000
+Default image prefix:   registry.example.com:5000/my-user (from this credential)
+
+No builds push to registries covered by this credential.
+`,
+		},
+		{
+			Name: "no default image prefix",
+			Args: []string{credentialName},
+			GivenObjects: []runtime.Object{
+				dockerHub,
+				&buildv1alpha1.Function{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-function",
+						Namespace: defaultNamespace,
+					},
+					Spec: buildv1alpha1.FunctionSpec{
+						Image: "_",
+					},
+				},
+			},
+			ExpectOutput: `
+Name:                   my-creds
+Type:                   docker-hub
+Username:               projectriff
+Registries:             https://index.docker.io/v1/
+Default image prefix:   <empty>
+
+No builds push to registries covered by this credential.
+`,
+		},
+		{
+			Name: "not found",
+			Args: []string{credentialName},
+			ExpectOutput: `
+Credential "default/my-creds" not found
+`,
+			ShouldError: true,
+		},
+		{
+			Name: "ignore non-riff secrets",
+			Args: []string{credentialName},
+			GivenObjects: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      credentialName,
+						Namespace: defaultNamespace,
+					},
+				},
+			},
+			ExpectOutput: `
+Credential "default/my-creds" not found
+`,
+			ShouldError: true,
+		},
+		{
+			Name: "get error",
+			Args: []string{credentialName},
+			GivenObjects: []runtime.Object{
+				dockerHub,
+			},
+			WithReactors: []rifftesting.ReactionFunc{
+				rifftesting.InduceFailure("get", "secrets"),
+			},
+			ShouldError: true,
+		},
+		{
+			Name: "get riff-build error",
+			Args: []string{credentialName},
+			GivenObjects: []runtime.Object{
+				dockerHub,
+			},
+			WithReactors: []rifftesting.ReactionFunc{
+				rifftesting.InduceFailure("get", "configmaps"),
+			},
+			ShouldError: true,
+		},
+		{
+			Name: "list builds error",
+			Args: []string{credentialName},
+			GivenObjects: []runtime.Object{
+				dockerHub,
+			},
+			WithReactors: []rifftesting.ReactionFunc{
+				rifftesting.InduceFailure("list", "applications"),
+			},
+			ShouldError: true,
+		},
+	}
+
+	table.Run(t, commands.NewCredentialShowCommand)
+}
@@ -51,6 +51,8 @@ the source code. Unlike applications, functions:
 		Aliases: []string{"functions", "func", "fn"},
 	}
 
+	cli.CompleteNameArgs(cmd, cli.FunctionsCompletion)
+
 	cmd.AddCommand(NewFunctionListCommand(ctx, c))
 	cmd.AddCommand(NewFunctionCreateCommand(ctx, c))
 	cmd.AddCommand(NewFunctionDeleteCommand(ctx, c))
@@ -26,11 +26,14 @@ import (
 	"github.com/buildpack/pack"
 	"github.com/projectriff/cli/pkg/cli"
 	"github.com/projectriff/cli/pkg/k8s"
+	"github.com/projectriff/cli/pkg/kail"
 	"github.com/projectriff/cli/pkg/race"
+	"github.com/projectriff/cli/pkg/validation"
 	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiruntime "k8s.io/apimachinery/pkg/runtime"
 )
 
 type FunctionCreateOptions struct {
@@ -58,6 +61,7 @@ var (
 	_ cli.Validatable = (*FunctionCreateOptions)(nil)
 	_ cli.Executable  = (*FunctionCreateOptions)(nil)
 	_ cli.DryRunable  = (*FunctionCreateOptions)(nil)
+	_ cli.Promptable  = (*FunctionCreateOptions)(nil)
 )
 
 func (opts *FunctionCreateOptions) Validate(ctx context.Context) *cli.FieldError {
@@ -67,6 +71,8 @@ func (opts *FunctionCreateOptions) Validate(ctx context.Context) *cli.FieldError
 
 	if opts.Image == "" {
 		errs = errs.Also(cli.ErrMissingField(cli.ImageFlagName))
+	} else {
+		errs = errs.Also(validation.BuildImageRef(opts.Image, cli.ImageFlagName))
 	}
 
 	if opts.CacheSize != "" {
@@ -185,7 +191,15 @@ func (opts *FunctionCreateOptions) Exec(ctx context.Context, c *cli.Config) erro
 	}
 
 	if opts.DryRun {
-		cli.DryRunResource(ctx, function, function.GetGroupVersionKind())
+		get := func() (apiruntime.Object, error) {
+			return c.Build().Functions(opts.Namespace).Get(function.Name, metav1.GetOptions{})
+		}
+		create := func() (apiruntime.Object, error) {
+			return k8s.DryRunCreate(c.Build().RESTClient(), "functions", function)
+		}
+		if err := cli.DryRunCreate(ctx, c, function, function.GetGroupVersionKind(), get, create); err != nil {
+			return err
+		}
 	} else {
 		var err error
 		function, err = c.Build().Functions(opts.Namespace).Create(function)
@@ -202,7 +216,7 @@ func (opts *FunctionCreateOptions) Exec(ctx context.Context, c *cli.Config) erro
 				return k8s.WaitUntilReady(ctx, c.Build().RESTClient(), "functions", function)
 			},
 			func(ctx context.Context) error {
-				return c.Kail.FunctionLogs(ctx, function, cli.TailSinceCreateDefault, c.Stdout)
+				return c.Kail.FunctionLogs(ctx, function, kail.LogOptions{Since: cli.TailSinceCreateDefault}, c.Stdout)
 			},
 		)
 		if err == context.DeadlineExceeded {
@@ -218,6 +232,18 @@ func (opts *FunctionCreateOptions) Exec(ctx context.Context, c *cli.Config) erro
 	return nil
 }
 
+func (opts *FunctionCreateOptions) Prompt(ctx context.Context, c *cli.Config, p *cli.Prompter) error {
+	if err := p.OneOf(cli.GitRepoFlagName, cli.LocalPathFlagName); err != nil {
+		return err
+	}
+	if opts.GitRepo != "" {
+		if err := p.Optional(cli.GitRevisionFlagName); err != nil {
+			return err
+		}
+	}
+	return p.Optional(cli.ImageFlagName)
+}
+
 func (opts *FunctionCreateOptions) IsDryRun() bool {
 	return opts.DryRun
 }
@@ -268,6 +294,7 @@ The riff.toml file takes the form:
 		cli.NameArg(&opts.Name),
 	)
 
+	cli.InteractiveFlag(ctx, cmd, c, opts)
 	cli.NamespaceFlag(cmd, c, &opts.Namespace)
 	cmd.Flags().StringVar(&opts.Image, cli.StripDash(cli.ImageFlagName), "_", "`repository` where the built images are pushed")
 	cmd.Flags().StringVar(&opts.CacheSize, cli.StripDash(cli.CacheSizeFlagName), "", "`size` of persistent volume to cache resources between builds")
@@ -280,7 +307,7 @@ The riff.toml file takes the form:
 	cmd.Flags().StringVar(&opts.SubPath, cli.StripDash(cli.SubPathFlagName), "", "path to `directory` within the git repo to checkout")
 	cmd.Flags().BoolVar(&opts.Tail, cli.StripDash(cli.TailFlagName), false, "watch build logs")
 	cmd.Flags().StringVar(&opts.WaitTimeout, cli.StripDash(cli.WaitTimeoutFlagName), "10m", "`duration` to wait for the function to become ready when watching logs")
-	cmd.Flags().BoolVar(&opts.DryRun, cli.StripDash(cli.DryRunFlagName), false, "print kubernetes resources to stdout rather than apply them to the cluster, messages normally on stdout will be sent to stderr")
+	cli.DryRunFlags(cmd, c, &opts.DryRun)
 
 	return cmd
 }
@@ -19,13 +19,16 @@ package cli
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
 
 const (
+	KeyArgumentName   = "key"
 	NameArgumentName  = "name"
 	NamesArgumentName = "name(s)"
+	ValueArgumentName = "value"
 )
 
 var ErrIgnoreArg = fmt.Errorf("ignore argument")
@@ -34,6 +37,7 @@ type Arg struct {
 	Name     string
 	Arity    int
 	Optional bool
+	Values   []string
 	Set      func(cmd *cobra.Command, args []string, offset int) error
 }
 
@@ -79,6 +83,9 @@ func Args(cmd *cobra.Command, argDefs ...Arg) {
 		cmd.Annotations[fmt.Sprintf("args[%d].name", i)] = argDef.Name
 		cmd.Annotations[fmt.Sprintf("args[%d].arity", i)] = fmt.Sprintf("%d", argDef.Arity)
 		cmd.Annotations[fmt.Sprintf("args[%d].optional", i)] = fmt.Sprintf("%v", argDef.Optional)
+		if len(argDef.Values) != 0 {
+			cmd.Annotations[fmt.Sprintf("args[%d].values", i)] = strings.Join(argDef.Values, ",")
+		}
 	}
 }
 
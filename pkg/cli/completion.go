@@ -0,0 +1,362 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/projectriff/cli/pkg/k8s"
+	"github.com/projectriff/system/pkg/apis/build"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// resources whose names are used to complete arguments and flags
+const (
+	ApplicationsCompletion     = "applications"
+	ContainersCompletion       = "containers"
+	CoreDeployersCompletion    = "core-deployers"
+	CredentialsCompletion      = "credentials"
+	FunctionsCompletion        = "functions"
+	KnativeAdaptersCompletion  = "knative-adapters"
+	KnativeDeployersCompletion = "knative-deployers"
+	NamespacesCompletion       = "namespaces"
+	ProcessorsCompletion       = "processors"
+	StreamsCompletion          = "streams"
+)
+
+const (
+	completionResourceAnnotation = "completion.resource"
+	completionValuesAnnotation   = "completion.values"
+)
+
+// CompleteNameArgs completes the name arguments of the command's subcommands
+// with the names of existing resources. Create commands are skipped as the
+// resource should not exist yet.
+func CompleteNameArgs(cmd *cobra.Command, resource string) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[completionResourceAnnotation] = resource
+}
+
+// CompleteFlagResource completes the value of the flag with the names of
+// existing resources in the command's namespace.
+func CompleteFlagResource(cmd *cobra.Command, flagName, resource string) {
+	completionFlags(cmd, flagName).SetAnnotation(StripDash(flagName), completionResourceAnnotation, []string{resource})
+}
+
+// CompleteFlagValues completes the value of the flag with a fixed set of
+// values.
+func CompleteFlagValues(cmd *cobra.Command, flagName string, values ...string) {
+	completionFlags(cmd, flagName).SetAnnotation(StripDash(flagName), completionValuesAnnotation, values)
+}
+
+// completionFlags is the flag set defining the flag, persistent flags are not
+// part of the command's flags until they are parsed.
+func completionFlags(cmd *cobra.Command, flagName string) *pflag.FlagSet {
+	if cmd.Flags().Lookup(StripDash(flagName)) == nil {
+		return cmd.PersistentFlags()
+	}
+	return cmd.Flags()
+}
+
+// BashCompleteFlags directs bash completion to call the function to complete
+// the value of flags that are completed dynamically.
+func BashCompleteFlags(cmd *cobra.Command, function string) {
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		_, values := f.Annotations[completionValuesAnnotation]
+		_, resource := f.Annotations[completionResourceAnnotation]
+		if values || resource {
+			cmd.Flags().SetAnnotation(f.Name, cobra.BashCompCustom, []string{function})
+		}
+	})
+	for _, sub := range cmd.Commands() {
+		BashCompleteFlags(sub, function)
+	}
+}
+
+// Complete returns the candidates for the last arg, where args are everything
+// on the command line after the root command. Subcommands, flag names, flag
+// values and arguments are completed. Failures to look up resources result in
+// no candidates.
+func Complete(c *Config, root *cobra.Command, args []string) (candidates []string) {
+	defer func() {
+		// loading the kube config panics when it is invalid
+		if r := recover(); r != nil {
+			candidates = nil
+		}
+	}()
+
+	toComplete := ""
+	if len(args) != 0 {
+		toComplete = args[len(args)-1]
+		args = args[:len(args)-1]
+	}
+
+	cmd, args, err := root.Find(args)
+	if err != nil {
+		return nil
+	}
+	// merge persistent flags from the parent commands
+	cmd.InheritedFlags()
+
+	// complete the value of a flag given as '--flag=value' or '--flag value'
+	var flag *pflag.Flag
+	valuePrefix := ""
+	if strings.HasPrefix(toComplete, "-") && strings.Contains(toComplete, "=") {
+		parts := strings.SplitN(toComplete, "=", 2)
+		if flag = lookupFlag(cmd, parts[0]); flag == nil {
+			return nil
+		}
+		valuePrefix = parts[0] + "="
+		toComplete = parts[1]
+	} else if len(args) != 0 {
+		last := args[len(args)-1]
+		if strings.HasPrefix(last, "-") && !strings.Contains(last, "=") {
+			if f := lookupFlag(cmd, last); f != nil && f.NoOptDefVal == "" {
+				flag = f
+				args = args[:len(args)-1]
+			}
+		}
+	}
+
+	// parse the flags so the namespace is known, errors are ignored as the
+	// command line is incomplete
+	cmd.ParseFlags(args)
+	if f := cmd.Flags().Lookup(StripDash(KubeConfigFlagName)); f != nil && f.Changed {
+		c.Client = k8s.NewClient(c.KubeConfigFile, &c.KubeConfigOverrides, nil)
+	}
+
+	switch {
+	case flag != nil:
+		candidates = completeFlag(c, cmd, flag)
+	case strings.HasPrefix(toComplete, "-"):
+		candidates = flagNames(cmd)
+	case cmd.HasAvailableSubCommands():
+		if len(cmd.Flags().Args()) != 0 {
+			// an unknown command, possibly a plugin
+			return nil
+		}
+		candidates = subcommandNames(cmd)
+	default:
+		candidates = completeArg(c, cmd, cmd.Flags().Args())
+	}
+
+	matched := []string{}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, toComplete) {
+			matched = append(matched, valuePrefix+candidate)
+		}
+	}
+	return matched
+}
+
+func lookupFlag(cmd *cobra.Command, arg string) *pflag.Flag {
+	if strings.HasPrefix(arg, "--") {
+		return cmd.Flags().Lookup(arg[2:])
+	}
+	if len(arg) == 2 {
+		return cmd.Flags().ShorthandLookup(arg[1:])
+	}
+	return nil
+}
+
+func flagNames(cmd *cobra.Command) []string {
+	names := []string{}
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if f.Hidden {
+			return
+		}
+		names = append(names, "--"+f.Name)
+	})
+	return names
+}
+
+func subcommandNames(cmd *cobra.Command) []string {
+	names := []string{}
+	for _, sub := range cmd.Commands() {
+		if sub.IsAvailableCommand() {
+			names = append(names, sub.Name())
+		}
+	}
+	return names
+}
+
+func completeFlag(c *Config, cmd *cobra.Command, flag *pflag.Flag) []string {
+	if values, ok := flag.Annotations[completionValuesAnnotation]; ok {
+		return values
+	}
+	if resource, ok := flag.Annotations[completionResourceAnnotation]; ok && len(resource) == 1 {
+		return resourceNames(c, cmd, resource[0])
+	}
+	return nil
+}
+
+// completeArg completes the next argument for the command, given the
+// arguments already on the command line.
+func completeArg(c *Config, cmd *cobra.Command, args []string) []string {
+	length, _ := strconv.Atoi(cmd.Annotations["args.length"])
+	offset := len(args)
+	for i := 0; i < length; i++ {
+		arity, _ := strconv.Atoi(cmd.Annotations[fmt.Sprintf("args[%d].arity", i)])
+		if arity != -1 && offset >= arity {
+			offset -= arity
+			continue
+		}
+
+		if values, ok := cmd.Annotations[fmt.Sprintf("args[%d].values", i)]; ok {
+			return strings.Split(values, ",")
+		}
+		name := cmd.Annotations[fmt.Sprintf("args[%d].name", i)]
+		if (name != NameArgumentName && name != NamesArgumentName) || cmd.Name() == "create" {
+			return nil
+		}
+		for parent := cmd; parent != nil; parent = parent.Parent() {
+			if resource, ok := parent.Annotations[completionResourceAnnotation]; ok {
+				return exclude(resourceNames(c, cmd, resource), args)
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// resourceNames lists the names of resources in the namespace from the
+// command's namespace flag, or the default namespace.
+func resourceNames(c *Config, cmd *cobra.Command, resource string) []string {
+	namespace := ""
+	if f := cmd.Flags().Lookup(StripDash(NamespaceFlagName)); f != nil {
+		namespace = f.Value.String()
+	}
+	if namespace == "" && resource != NamespacesCompletion {
+		namespace = c.DefaultNamespace()
+	}
+
+	names := []string{}
+	add := func(meta metav1.Object) {
+		names = append(names, meta.GetName())
+	}
+	switch resource {
+	case ApplicationsCompletion:
+		list, err := c.Build().Applications(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return nil
+		}
+		for i := range list.Items {
+			add(&list.Items[i])
+		}
+	case ContainersCompletion:
+		list, err := c.Build().Containers(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return nil
+		}
+		for i := range list.Items {
+			add(&list.Items[i])
+		}
+	case CoreDeployersCompletion:
+		list, err := c.CoreRuntime().Deployers(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return nil
+		}
+		for i := range list.Items {
+			add(&list.Items[i])
+		}
+	case CredentialsCompletion:
+		list, err := c.Core().Secrets(namespace).List(metav1.ListOptions{
+			LabelSelector: build.CredentialLabelKey,
+		})
+		if err != nil {
+			return nil
+		}
+		for i := range list.Items {
+			add(&list.Items[i])
+		}
+	case FunctionsCompletion:
+		list, err := c.Build().Functions(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return nil
+		}
+		for i := range list.Items {
+			add(&list.Items[i])
+		}
+	case KnativeAdaptersCompletion:
+		list, err := c.KnativeRuntime().Adapters(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return nil
+		}
+		for i := range list.Items {
+			add(&list.Items[i])
+		}
+	case KnativeDeployersCompletion:
+		list, err := c.KnativeRuntime().Deployers(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return nil
+		}
+		for i := range list.Items {
+			add(&list.Items[i])
+		}
+	case NamespacesCompletion:
+		list, err := c.Core().Namespaces().List(metav1.ListOptions{})
+		if err != nil {
+			return nil
+		}
+		for i := range list.Items {
+			add(&list.Items[i])
+		}
+	case ProcessorsCompletion:
+		list, err := c.StreamingRuntime().Processors(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return nil
+		}
+		for i := range list.Items {
+			add(&list.Items[i])
+		}
+	case StreamsCompletion:
+		list, err := c.StreamingRuntime().Streams(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return nil
+		}
+		for i := range list.Items {
+			add(&list.Items[i])
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// exclude removes values that are already on the command line.
+func exclude(candidates, given []string) []string {
+	remaining := []string{}
+	for _, candidate := range candidates {
+		found := false
+		for _, g := range given {
+			if candidate == g {
+				found = true
+				break
+			}
+		}
+		if !found {
+			remaining = append(remaining, candidate)
+		}
+	}
+	return remaining
+}
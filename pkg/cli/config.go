@@ -30,21 +30,40 @@ import (
 	"github.com/projectriff/cli/pkg/k8s"
 	"github.com/projectriff/cli/pkg/kail"
 	"github.com/projectriff/cli/pkg/pack"
+	"github.com/projectriff/cli/pkg/portforward"
+	"github.com/projectriff/cli/pkg/registry"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
+// DebugVerbosity is the verbosity level that shows the logs of pack and kail,
+// levels below it trace requests to the cluster, see k8s.TraceTransport.
+const DebugVerbosity = k8s.TraceBodies + 1
+
 type Config struct {
 	CompiledEnv
-	ViperConfigFile string
-	KubeConfigFile  string
+	ViperConfigFile     string
+	KubeConfigFile      string
+	KubeConfigOverrides clientcmd.ConfigOverrides
+	Profile             string
+	FlagDefaults        map[string]string
+	Verbosity           int
+	QPS                 float32
+	Burst               int
+	ErrorFormat         string
+	DryRunMode          string
+	Diff                bool
+	configFileLoaded    bool
 	k8s.Client
-	Exec   func(ctx context.Context, command string, args ...string) *exec.Cmd
-	Pack   pack.Client
-	Kail   kail.Logger
-	Stdin  io.Reader
-	Stdout io.Writer
-	Stderr io.Writer
+	Exec        func(ctx context.Context, command string, args ...string) *exec.Cmd
+	Pack        pack.Client
+	Kail        kail.Logger
+	PortForward portforward.Forwarder
+	Registry    registry.Verifier
+	Stdin       io.Reader
+	Stdout      io.Writer
+	Stderr      io.Writer
 }
 
 func NewDefaultConfig() *Config {
@@ -99,8 +118,20 @@ func Initialize() *Config {
 	return c
 }
 
+// LoadConfigFile reads the config file and the active profile, unless they
+// were already read. Cobra's initializers do not run when showing help, so help
+// that depends on the profile loads it explicitly.
+func (c *Config) LoadConfigFile() {
+	c.initViperConfig()
+}
+
 // initViperConfig reads in config file and ENV variables if set.
 func (c *Config) initViperConfig() {
+	if c.configFileLoaded {
+		return
+	}
+	c.configFileLoaded = true
+
 	if c.ViperConfigFile != "" {
 		// Use config file from the flag.
 		viper.SetConfigFile(c.ViperConfigFile)
@@ -131,6 +162,13 @@ func (c *Config) initViperConfig() {
 	if err == nil {
 		c.Einfof("Using config file: %s\n", viper.ConfigFileUsed())
 	}
+	if err := c.applyProfile(); err != nil {
+		c.Eerrorf("%s\n", err)
+		os.Exit(1)
+	}
+	if c.Profile != "" {
+		c.Einfof("Using profile: %s\n", c.Profile)
+	}
 }
 
 // initKubeConfig defines the default location for the kubectl config file
@@ -152,10 +190,18 @@ func (c *Config) initKubeConfig() {
 
 func (c *Config) init() {
 	if c.Client == nil {
-		c.Client = k8s.NewClient(c.KubeConfigFile)
+		if err := c.validateClientFlags(); err != nil {
+			c.Eerrorf("%s\n", err)
+			os.Exit(1)
+		}
+		c.Client = k8s.NewClient(c.KubeConfigFile, &c.KubeConfigOverrides, &k8s.ClientOptions{
+			QPS:           c.QPS,
+			Burst:         c.Burst,
+			WrapTransport: k8s.TraceTransport(c.Stderr, c.Verbosity),
+		})
 	}
 	if c.Pack == nil {
-		packClient, err := pack.NewClient(c.Stdout)
+		packClient, err := pack.NewClient(c.Stdout, c.debugWriter())
 		if err != nil {
 			c.Eerrorf("%s\n", err)
 			os.Exit(1)
@@ -163,6 +209,41 @@ func (c *Config) init() {
 		c.Pack = packClient
 	}
 	if c.Kail == nil {
-		c.Kail = kail.NewDefault(c.Client)
+		c.Kail = kail.NewDefault(c.Client, c.debugWriter())
+	}
+	if c.PortForward == nil {
+		c.PortForward = portforward.NewDefault(c.Client)
+	}
+	if c.Registry == nil {
+		c.Registry = registry.NewDefault()
+	}
+}
+
+// debugWriter is where debug logs are written, or nil when debug logs are not
+// shown.
+func (c *Config) debugWriter() io.Writer {
+	if c.Verbosity < DebugVerbosity {
+		return nil
+	}
+	return c.Stderr
+}
+
+// validateClientFlags checks the values from the kubeconfig override and
+// client flags that would otherwise fail when the first request is made.
+func (c *Config) validateClientFlags() error {
+	if timeout := c.KubeConfigOverrides.Timeout; timeout != "" {
+		if _, err := clientcmd.ParseTimeout(timeout); err != nil {
+			return fmt.Errorf("invalid value for %s: %v", RequestTimeoutFlagName, err)
+		}
+	}
+	if c.QPS < 0 {
+		return fmt.Errorf("invalid value for %s: must not be negative", QPSFlagName)
+	}
+	if c.Burst < 0 {
+		return fmt.Errorf("invalid value for %s: must not be negative", BurstFlagName)
+	}
+	if len(c.KubeConfigOverrides.AuthInfo.ImpersonateGroups) != 0 && c.KubeConfigOverrides.AuthInfo.Impersonate == "" {
+		return fmt.Errorf("%s requires %s to impersonate a user", AsGroupFlagName, AsFlagName)
 	}
+	return nil
 }
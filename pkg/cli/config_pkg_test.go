@@ -199,3 +199,69 @@ func TestInit(t *testing.T) {
 		t.Errorf("Expected c.Kail tp be set, actually %v", c.Kail)
 	}
 }
+
+func TestValidateClientFlags(t *testing.T) {
+	tests := []struct {
+		name              string
+		timeout           string
+		impersonate       string
+		impersonateGroups []string
+		qps               float32
+		burst             int
+		expected          string
+	}{{
+		name: "empty",
+	}, {
+		name:    "timeout duration",
+		timeout: "30s",
+	}, {
+		name:    "timeout seconds",
+		timeout: "30",
+	}, {
+		name:     "invalid timeout",
+		timeout:  "soon",
+		expected: "invalid value for --request-timeout: ",
+	}, {
+		name:              "impersonate user and groups",
+		impersonate:       "jane",
+		impersonateGroups: []string{"developers"},
+	}, {
+		name:              "impersonate groups without user",
+		impersonateGroups: []string{"developers"},
+		expected:          "--as-group requires --as to impersonate a user",
+	}, {
+		name:  "qps and burst",
+		qps:   50,
+		burst: 100,
+	}, {
+		name:     "negative qps",
+		qps:      -1,
+		expected: "invalid value for --qps: must not be negative",
+	}, {
+		name:     "negative burst",
+		burst:    -1,
+		expected: "invalid value for --burst: must not be negative",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := NewDefaultConfig()
+			c.KubeConfigOverrides.Timeout = test.timeout
+			c.KubeConfigOverrides.AuthInfo.Impersonate = test.impersonate
+			c.KubeConfigOverrides.AuthInfo.ImpersonateGroups = test.impersonateGroups
+			c.QPS = test.qps
+			c.Burst = test.burst
+
+			err := c.validateClientFlags()
+			if test.expected == "" {
+				if err != nil {
+					t.Errorf("Expected no error, actually %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), test.expected) {
+				t.Errorf("Expected error starting with %q, actually %v", test.expected, err)
+			}
+		})
+	}
+}
@@ -21,17 +21,104 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/ghodss/yaml"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
+const (
+	// NoDryRun applies resources to the cluster
+	NoDryRun = "none"
+	// ClientDryRun prints resources as built by the CLI
+	ClientDryRun = "client"
+	// ServerDryRun submits resources to the API server in dry run mode and
+	// prints them as defaulted and admitted by the server
+	ServerDryRun = "server"
+)
+
 type DryRunable interface {
 	IsDryRun() bool
 }
 
+// DryRunFlags defines the --dry-run and --diff flags for a command that applies
+// resources to the cluster. The dryRun option is set when either flag is used,
+// the mode is recorded on the config for DryRunCreate and DryRunApply.
+func DryRunFlags(cmd *cobra.Command, c *Config, dryRun *bool) {
+	f := cmd.Flags().VarPF(&dryRunValue{c: c, dryRun: dryRun}, StripDash(DryRunFlagName), "", fmt.Sprintf("print kubernetes resources to stdout rather than apply them to the cluster, messages normally on stdout will be sent to stderr, %q runs defaulting and admission on the cluster without persisting the resources", ServerDryRun))
+	f.NoOptDefVal = ClientDryRun
+	CompleteFlagValues(cmd, DryRunFlagName, ClientDryRun, ServerDryRun, NoDryRun)
+	f = cmd.Flags().VarPF(&diffValue{c: c, dryRun: dryRun}, StripDash(DiffFlagName), "", "print a diff of the live kubernetes resources against the resources that would be applied, implies "+DryRunFlagName)
+	f.NoOptDefVal = "true"
+}
+
+type dryRunValue struct {
+	c      *Config
+	dryRun *bool
+}
+
+func (v *dryRunValue) String() string {
+	return v.c.DryRunMode
+}
+
+func (v *dryRunValue) Set(s string) error {
+	switch s {
+	case ClientDryRun, ServerDryRun, NoDryRun:
+	default:
+		// accept the values of the former bool flag
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("must be one of %q, %q or %q", ClientDryRun, ServerDryRun, NoDryRun)
+		}
+		s = NoDryRun
+		if b {
+			s = ClientDryRun
+		}
+	}
+	v.c.DryRunMode = s
+	*v.dryRun = s != NoDryRun || v.c.Diff
+	return nil
+}
+
+func (v *dryRunValue) Type() string {
+	return "mode"
+}
+
+type diffValue struct {
+	c      *Config
+	dryRun *bool
+}
+
+func (v *diffValue) String() string {
+	return strconv.FormatBool(v.c.Diff)
+}
+
+func (v *diffValue) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	v.c.Diff = b
+	*v.dryRun = b || (v.c.DryRunMode != "" && v.c.DryRunMode != NoDryRun)
+	return nil
+}
+
+func (v *diffValue) Type() string {
+	return "bool"
+}
+
+func (v *diffValue) IsBoolFlag() bool {
+	return true
+}
+
+// DryRunResource prints the resource as yaml to the reserved stdout.
 func DryRunResource(ctx context.Context, resource runtime.Object, gvk schema.GroupVersionKind) {
 	stdout := stdoutFromContext(ctx)
 	resource = defaultTypeMeta(resource, gvk)
@@ -39,6 +126,82 @@ func DryRunResource(ctx context.Context, resource runtime.Object, gvk schema.Gro
 	fmt.Fprintf(stdout, "---\n%s\n", b)
 }
 
+// DryRunCreate prints the resource that would be created for a dry run. With
+// --dry-run=server the resource is created in dry run mode and the resource
+// returned by the server is printed. With --diff the live resource is fetched
+// and a diff is printed instead.
+func DryRunCreate(ctx context.Context, c *Config, resource runtime.Object, gvk schema.GroupVersionKind, get, create func() (runtime.Object, error)) error {
+	var live runtime.Object
+	if c.Diff {
+		existing, err := get()
+		if err != nil && !apierrs.IsNotFound(err) {
+			return err
+		}
+		if err == nil {
+			live = existing
+		}
+	}
+	return DryRunApply(ctx, c, live, resource, gvk, create)
+}
+
+// DryRunApply prints the resource that would be applied over the live
+// resource for a dry run, the live resource is nil when it does not exist.
+// With --dry-run=server the resource is applied in dry run mode, see
+// k8s.DryRunCreate and k8s.DryRunUpdate, and the resource returned by the
+// server is printed. With --diff a diff of the live
+// resource is printed instead.
+func DryRunApply(ctx context.Context, c *Config, live, resource runtime.Object, gvk schema.GroupVersionKind, apply func() (runtime.Object, error)) error {
+	if c.DryRunMode == ServerDryRun {
+		applied, err := apply()
+		if err != nil {
+			return err
+		}
+		resource = applied
+	}
+	if !c.Diff {
+		DryRunResource(ctx, resource, gvk)
+		return nil
+	}
+
+	from, to := "", ""
+	if live != nil {
+		live = defaultTypeMeta(live.DeepCopyObject(), gvk)
+		b, _ := yaml.Marshal(live)
+		from = string(b)
+	}
+	resource = defaultTypeMeta(resource, gvk)
+	b, _ := yaml.Marshal(resource)
+	to = string(b)
+
+	name := ""
+	if accessor, err := meta.Accessor(resource); err == nil {
+		name = accessor.GetName()
+	}
+	if from == to {
+		c.Infof("No changes to %s %q\n", gvk.Kind, name)
+		return nil
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        diffLines(from),
+		B:        diffLines(to),
+		FromFile: fmt.Sprintf("live/%s/%s", gvk.Kind, name),
+		ToFile:   fmt.Sprintf("dry-run/%s/%s", gvk.Kind, name),
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(stdoutFromContext(ctx), diff)
+	return nil
+}
+
+func diffLines(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return difflib.SplitLines(strings.TrimSuffix(s, "\n"))
+}
+
 func defaultTypeMeta(resource runtime.Object, gvk schema.GroupVersionKind) runtime.Object {
 	apiVersion, kind := gvk.ToAPIVersionAndKind()
 	tm := metav1.TypeMeta{
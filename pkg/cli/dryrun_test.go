@@ -24,6 +24,10 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 func TestDryRunResource(t *testing.T) {
@@ -49,3 +53,216 @@ status: {}
 	}
 
 }
+
+func TestDryRunFlags(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		expectedMode string
+		expectedDiff bool
+		expectedBool bool
+		shouldError  bool
+	}{{
+		name: "default",
+	}, {
+		name:         "dry run",
+		args:         []string{DryRunFlagName},
+		expectedMode: ClientDryRun,
+		expectedBool: true,
+	}, {
+		name:         "server dry run",
+		args:         []string{DryRunFlagName + "=" + ServerDryRun},
+		expectedMode: ServerDryRun,
+		expectedBool: true,
+	}, {
+		name:         "no dry run",
+		args:         []string{DryRunFlagName + "=" + NoDryRun},
+		expectedMode: NoDryRun,
+	}, {
+		name:         "former bool value",
+		args:         []string{DryRunFlagName + "=true"},
+		expectedMode: ClientDryRun,
+		expectedBool: true,
+	}, {
+		name:         "diff",
+		args:         []string{DiffFlagName},
+		expectedDiff: true,
+		expectedBool: true,
+	}, {
+		name:         "server diff",
+		args:         []string{DryRunFlagName + "=" + ServerDryRun, DiffFlagName},
+		expectedMode: ServerDryRun,
+		expectedDiff: true,
+		expectedBool: true,
+	}, {
+		name:        "invalid mode",
+		args:        []string{DryRunFlagName + "=bogus"},
+		shouldError: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Config{}
+			dryRun := false
+			cmd := &cobra.Command{}
+			DryRunFlags(cmd, c, &dryRun)
+
+			err := cmd.ParseFlags(test.args)
+			if expected, actual := test.shouldError, err != nil; expected != actual {
+				t.Fatalf("expected error %v, actually %v", expected, err)
+			}
+			if err != nil {
+				return
+			}
+			if expected, actual := test.expectedMode, c.DryRunMode; expected != actual {
+				t.Errorf("expected mode %q, actually %q", expected, actual)
+			}
+			if expected, actual := test.expectedDiff, c.Diff; expected != actual {
+				t.Errorf("expected diff %v, actually %v", expected, actual)
+			}
+			if expected, actual := test.expectedBool, dryRun; expected != actual {
+				t.Errorf("expected dry run %v, actually %v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestDryRunApply(t *testing.T) {
+	live := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "riff-build",
+		},
+		Data: map[string]string{
+			"default-image-prefix": "registry.example.com/old",
+		},
+	}
+	resource := live.DeepCopy()
+	resource.Data["default-image-prefix"] = "registry.example.com/new"
+	gvk := corev1.SchemeGroupVersion.WithKind("ConfigMap")
+
+	tests := []struct {
+		name           string
+		mode           string
+		diff           bool
+		live           runtime.Object
+		resource       runtime.Object
+		applied        runtime.Object
+		expectedApply  bool
+		expectedStdout string
+		expectedStderr string
+	}{{
+		name:     "client",
+		mode:     ClientDryRun,
+		live:     live,
+		resource: resource,
+		expectedStdout: `
+---
+apiVersion: v1
+data:
+  default-image-prefix: registry.example.com/new
+kind: ConfigMap
+metadata:
+  creationTimestamp: null
+  name: riff-build
+  namespace: default
+`,
+	}, {
+		name:     "server",
+		mode:     ServerDryRun,
+		live:     live,
+		resource: resource,
+		applied: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "riff-build",
+				Labels:    map[string]string{"defaulted": "true"},
+			},
+		},
+		expectedApply: true,
+		expectedStdout: `
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  creationTimestamp: null
+  labels:
+    defaulted: "true"
+  name: riff-build
+  namespace: default
+`,
+	}, {
+		name:     "diff",
+		mode:     ClientDryRun,
+		diff:     true,
+		live:     live,
+		resource: resource,
+		expectedStdout: `
+--- live/ConfigMap/riff-build
++++ dry-run/ConfigMap/riff-build
+@@ -1,6 +1,6 @@
+ apiVersion: v1
+ data:
+-  default-image-prefix: registry.example.com/old
++  default-image-prefix: registry.example.com/new
+ kind: ConfigMap
+ metadata:
+   creationTimestamp: null
+`,
+	}, {
+		name:     "diff create",
+		mode:     ClientDryRun,
+		diff:     true,
+		resource: resource,
+		expectedStdout: `
+--- live/ConfigMap/riff-build
++++ dry-run/ConfigMap/riff-build
+@@ -0,0 +1,8 @@
++apiVersion: v1
++data:
++  default-image-prefix: registry.example.com/new
++kind: ConfigMap
++metadata:
++  creationTimestamp: null
++  name: riff-build
++  namespace: default
+`,
+	}, {
+		name:     "diff no changes",
+		mode:     ClientDryRun,
+		diff:     true,
+		live:     live,
+		resource: live.DeepCopy(),
+		expectedStderr: `
+No changes to ConfigMap "riff-build"
+`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			ctx := withStdout(context.Background(), stdout)
+			c := &Config{Stdout: stderr, DryRunMode: test.mode, Diff: test.diff}
+			applied := false
+			apply := func() (runtime.Object, error) {
+				applied = true
+				return test.applied, nil
+			}
+
+			if err := DryRunApply(ctx, c, test.live, test.resource, gvk, apply); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if expected, actual := test.expectedApply, applied; expected != actual {
+				t.Errorf("expected applied %v, actually %v", expected, actual)
+			}
+			if diff := cmp.Diff(strings.TrimSpace(test.expectedStdout), strings.TrimSpace(stdout.String())); diff != "" {
+				t.Errorf("Unexpected stdout (-expected, +actual): %s", diff)
+			}
+			if diff := cmp.Diff(strings.TrimSpace(test.expectedStderr), strings.TrimSpace(stderr.String())); diff != "" {
+				t.Errorf("Unexpected stderr (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
@@ -24,6 +24,10 @@ func (e *SilentError) Error() string {
 	return e.Err.Error()
 }
 
+func (e *SilentError) Unwrap() error {
+	return e.Err
+}
+
 func SilenceError(err error) error {
 	return &SilentError{
 		Err: err,
@@ -0,0 +1,234 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/knative/pkg/apis"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Exit codes for each class of error. Plugins exit with their own exit code.
+const (
+	// ExitCodeError is an error that does not fit another class
+	ExitCodeError = 1
+	// ExitCodeInvalid is an invalid flag, argument or resource
+	ExitCodeInvalid = 2
+	// ExitCodeNotFound is a resource that does not exist
+	ExitCodeNotFound = 3
+	// ExitCodeConflict is a resource that already exists or was changed concurrently
+	ExitCodeConflict = 4
+	// ExitCodeForbidden is a request the user is not allowed to make
+	ExitCodeForbidden = 5
+	// ExitCodeTimeout is a request or wait that did not complete in time
+	ExitCodeTimeout = 6
+	// ExitCodeServerError is a failure within the cluster
+	ExitCodeServerError = 7
+)
+
+// Error types reported for each class of error, see ExitCode.
+const (
+	ErrorType            = "Error"
+	InvalidErrorType     = "Invalid"
+	NotFoundErrorType    = "NotFound"
+	ConflictErrorType    = "Conflict"
+	ForbiddenErrorType   = "Forbidden"
+	TimeoutErrorType     = "Timeout"
+	ServerErrorErrorType = "ServerError"
+)
+
+// Formats for printing errors.
+const (
+	TextErrorFormat = "text"
+	JSONErrorFormat = "json"
+)
+
+var exitCodes = map[string]int{
+	ErrorType:            ExitCodeError,
+	InvalidErrorType:     ExitCodeInvalid,
+	NotFoundErrorType:    ExitCodeNotFound,
+	ConflictErrorType:    ExitCodeConflict,
+	ForbiddenErrorType:   ExitCodeForbidden,
+	TimeoutErrorType:     ExitCodeTimeout,
+	ServerErrorErrorType: ExitCodeServerError,
+}
+
+// UsageError is an error in how a command is invoked, like an unknown flag.
+type UsageError struct {
+	Err error
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorTypeOf classifies the error.
+func ErrorTypeOf(err error) string {
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		return InvalidErrorType
+	}
+	var usageErr *UsageError
+	if errors.As(err, &usageErr) {
+		return InvalidErrorType
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, wait.ErrWaitTimeout) {
+		return TimeoutErrorType
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return TimeoutErrorType
+	}
+	if status := apiStatus(err); status != nil {
+		switch status.Reason {
+		case metav1.StatusReasonNotFound:
+			return NotFoundErrorType
+		case metav1.StatusReasonAlreadyExists, metav1.StatusReasonConflict:
+			return ConflictErrorType
+		case metav1.StatusReasonForbidden, metav1.StatusReasonUnauthorized:
+			return ForbiddenErrorType
+		case metav1.StatusReasonTimeout, metav1.StatusReasonServerTimeout:
+			return TimeoutErrorType
+		case metav1.StatusReasonInvalid, metav1.StatusReasonBadRequest:
+			return InvalidErrorType
+		}
+		if status.Code >= http.StatusInternalServerError {
+			return ServerErrorErrorType
+		}
+	}
+	return ErrorType
+}
+
+// ExitCode is the exit code for the error, a plugin's exit code is preserved.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return exitCodes[ErrorTypeOf(err)]
+}
+
+// PrintError prints the error in the error format of the config. Silent errors
+// are not printed as text, as the command has already explained the error.
+func PrintError(c *Config, err error) {
+	if c.ErrorFormat == JSONErrorFormat {
+		b, _ := json.Marshal(newErrorOutput(err))
+		c.Eprintf("%s\n", b)
+		return
+	}
+	if IsSilent(err) {
+		return
+	}
+	c.Errorf("Error executing command:\n")
+	// errors can be multiple lines, indent each line
+	for _, line := range strings.Split(err.Error(), "\n") {
+		c.Errorf("  %s\n", line)
+	}
+}
+
+type errorOutput struct {
+	Type     string             `json:"type"`
+	ExitCode int                `json:"exitCode"`
+	Message  string             `json:"message"`
+	Fields   []fieldErrorOutput `json:"fields,omitempty"`
+	Status   *metav1.Status     `json:"status,omitempty"`
+}
+
+type fieldErrorOutput struct {
+	Message string   `json:"message"`
+	Paths   []string `json:"paths"`
+	Details string   `json:"details,omitempty"`
+}
+
+func newErrorOutput(err error) errorOutput {
+	output := errorOutput{
+		Type:     ErrorTypeOf(err),
+		ExitCode: ExitCode(err),
+		Message:  err.Error(),
+		Status:   apiStatus(err),
+	}
+	var fieldErr *apis.FieldError
+	if errors.As(err, &fieldErr) {
+		output.Fields = fieldErrors(fieldErr)
+	}
+	return output
+}
+
+// fieldPathMarker prefixes each path of a field error, marking the first line of each error in
+// the message. Details that follow may span many lines.
+const fieldPathMarker = "\x1f"
+
+// fieldErrors lists each error within the field error. The nested errors of an apis.FieldError
+// are only exposed through its message, where errors with the same message and details are
+// merged.
+func fieldErrors(err *apis.FieldError) []fieldErrorOutput {
+	fields := []fieldErrorOutput{}
+	for _, line := range strings.Split(err.ViaField(fieldPathMarker).Error(), "\n") {
+		sep := strings.Index(line, ": "+fieldPathMarker)
+		if sep < 0 {
+			if last := len(fields) - 1; last >= 0 {
+				fields[last].Details = strings.TrimPrefix(fields[last].Details+"\n"+line, "\n")
+			}
+			continue
+		}
+		field := fieldErrorOutput{
+			Message: line[:sep],
+			Paths:   []string{},
+		}
+		for _, path := range strings.Split(line[sep+len(": "+fieldPathMarker):], ", "+fieldPathMarker) {
+			field.Paths = append(field.Paths, strings.TrimPrefix(path, "."))
+		}
+		fields = append(fields, field)
+	}
+
+	for _, field := range fields {
+		sort.Strings(field.Paths)
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		if fields[i].Message == fields[j].Message {
+			return fields[i].Details < fields[j].Details
+		}
+		return fields[i].Message < fields[j].Message
+	})
+	return fields
+}
+
+func apiStatus(err error) *metav1.Status {
+	var statusErr apierrs.APIStatus
+	if !errors.As(err, &statusErr) {
+		return nil
+	}
+	status := statusErr.Status()
+	return &status
+}
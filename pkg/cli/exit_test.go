@@ -0,0 +1,218 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/google/go-cmp/cmp"
+	"github.com/projectriff/cli/pkg/cli"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestExitCode(t *testing.T) {
+	resource := schema.GroupResource{Group: "build.projectriff.io", Resource: "functions"}
+	tests := []struct {
+		name         string
+		err          error
+		expectedType string
+		expectedCode int
+	}{{
+		name:         "nil",
+		expectedType: cli.ErrorType,
+		expectedCode: 0,
+	}, {
+		name:         "error",
+		err:          fmt.Errorf("some error"),
+		expectedType: cli.ErrorType,
+		expectedCode: cli.ExitCodeError,
+	}, {
+		name:         "field error",
+		err:          cli.ErrMissingField(cli.NameArgumentName),
+		expectedType: cli.InvalidErrorType,
+		expectedCode: cli.ExitCodeInvalid,
+	}, {
+		name:         "usage error",
+		err:          &cli.UsageError{Err: fmt.Errorf("unknown flag: --bogus")},
+		expectedType: cli.InvalidErrorType,
+		expectedCode: cli.ExitCodeInvalid,
+	}, {
+		name:         "deadline exceeded",
+		err:          cli.SilenceError(context.DeadlineExceeded),
+		expectedType: cli.TimeoutErrorType,
+		expectedCode: cli.ExitCodeTimeout,
+	}, {
+		name:         "wait timeout",
+		err:          fmt.Errorf("waiting for deployer: %w", wait.ErrWaitTimeout),
+		expectedType: cli.TimeoutErrorType,
+		expectedCode: cli.ExitCodeTimeout,
+	}, {
+		name:         "not found",
+		err:          apierrs.NewNotFound(resource, "my-function"),
+		expectedType: cli.NotFoundErrorType,
+		expectedCode: cli.ExitCodeNotFound,
+	}, {
+		name:         "already exists",
+		err:          apierrs.NewAlreadyExists(resource, "my-function"),
+		expectedType: cli.ConflictErrorType,
+		expectedCode: cli.ExitCodeConflict,
+	}, {
+		name:         "forbidden",
+		err:          apierrs.NewForbidden(resource, "my-function", fmt.Errorf("no access")),
+		expectedType: cli.ForbiddenErrorType,
+		expectedCode: cli.ExitCodeForbidden,
+	}, {
+		name:         "unauthorized",
+		err:          apierrs.NewUnauthorized("no token"),
+		expectedType: cli.ForbiddenErrorType,
+		expectedCode: cli.ExitCodeForbidden,
+	}, {
+		name:         "server timeout",
+		err:          apierrs.NewServerTimeout(resource, "create", 1),
+		expectedType: cli.TimeoutErrorType,
+		expectedCode: cli.ExitCodeTimeout,
+	}, {
+		name:         "invalid",
+		err:          apierrs.NewBadRequest("bad"),
+		expectedType: cli.InvalidErrorType,
+		expectedCode: cli.ExitCodeInvalid,
+	}, {
+		name:         "internal error",
+		err:          apierrs.NewInternalError(fmt.Errorf("boom")),
+		expectedType: cli.ServerErrorErrorType,
+		expectedCode: cli.ExitCodeServerError,
+	}, {
+		name:         "service unavailable",
+		err:          apierrs.NewServiceUnavailable("down"),
+		expectedType: cli.ServerErrorErrorType,
+		expectedCode: cli.ExitCodeServerError,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.err != nil {
+				if expected, actual := test.expectedType, cli.ErrorTypeOf(test.err); expected != actual {
+					t.Errorf("Expected error type %q, actually %q", expected, actual)
+				}
+			}
+			if expected, actual := test.expectedCode, cli.ExitCode(test.err); expected != actual {
+				t.Errorf("Expected exit code %d, actually %d", expected, actual)
+			}
+		})
+	}
+}
+
+func TestExitCode_Plugin(t *testing.T) {
+	err := exec.Command("sh", "-c", "exit 42").Run()
+	if err == nil {
+		t.Fatalf("Expected error")
+	}
+	if expected, actual := 42, cli.ExitCode(cli.SilenceError(err)); expected != actual {
+		t.Errorf("Expected exit code %d, actually %d", expected, actual)
+	}
+}
+
+func TestPrintError(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	resource := schema.GroupResource{Group: "build.projectriff.io", Resource: "functions"}
+	tests := []struct {
+		name     string
+		format   string
+		err      error
+		expected string
+	}{{
+		name:   "text",
+		format: cli.TextErrorFormat,
+		err:    fmt.Errorf("line 1\nline 2"),
+		expected: `
+Error executing command:
+  line 1
+  line 2
+`,
+	}, {
+		name:     "silent text",
+		format:   cli.TextErrorFormat,
+		err:      cli.SilenceError(fmt.Errorf("some error")),
+		expected: "\n",
+	}, {
+		name:   "json",
+		format: cli.JSONErrorFormat,
+		err:    cli.SilenceError(fmt.Errorf("some error")),
+		expected: `
+{"type":"Error","exitCode":1,"message":"some error"}
+`,
+	}, {
+		name:   "json field error",
+		format: cli.JSONErrorFormat,
+		err:    cli.ErrMissingField(cli.NameArgumentName).Also(cli.ErrInvalidValue("a:b", cli.ImageFlagName), cli.ErrMultipleOneOf(cli.GitRepoFlagName, cli.LocalPathFlagName)),
+		expected: `
+{"type":"Invalid","exitCode":2,"message":"expected exactly one, got both: --git-repo, --local-path\ninvalid value: a:b: --image\nmissing field(s): name","fields":[{"message":"expected exactly one, got both","paths":["--git-repo","--local-path"]},{"message":"invalid value: a:b","paths":["--image"]},{"message":"missing field(s)","paths":["name"]}]}
+`,
+	}, {
+		name:   "json nested field error",
+		format: cli.JSONErrorFormat,
+		err: (&cli.FieldError{Message: "invalid key", Paths: []string{"data[a: b]"}, Details: "details: line 1\nline 2"}).Also(
+			cli.ErrMissingField("image").ViaField("spec"),
+			cli.ErrMissingField(cli.NameArgumentName),
+		),
+		expected: `
+{"type":"Invalid","exitCode":2,"message":"invalid key: data[a: b]\ndetails: line 1\nline 2\nmissing field(s): name, spec.image","fields":[{"message":"invalid key","paths":["data[a: b]"],"details":"details: line 1\nline 2"},{"message":"missing field(s)","paths":["name","spec.image"]}]}
+`,
+	}, {
+		name:   "json indexed field error",
+		format: cli.JSONErrorFormat,
+		err: cli.ErrInvalidValue("a, b", "env").ViaIndex(0).ViaField("spec").Also(
+			cli.ErrMissingField(cli.CurrentField).ViaKey("a.b").ViaField("data"),
+		),
+		expected: `
+{"type":"Invalid","exitCode":2,"message":"invalid value: a, b: spec[0].env\nmissing field(s): data.[a.b]","fields":[{"message":"invalid value: a, b","paths":["spec[0].env"]},{"message":"missing field(s)","paths":["data.[a.b]"]}]}
+`,
+	}, {
+		name:   "json api status",
+		format: cli.JSONErrorFormat,
+		err:    apierrs.NewNotFound(resource, "my-function"),
+		expected: `
+{"type":"NotFound","exitCode":3,"message":"functions.build.projectriff.io \"my-function\" not found","status":{"metadata":{},"status":"Failure","message":"functions.build.projectriff.io \"my-function\" not found","reason":"NotFound","details":{"name":"my-function","group":"build.projectriff.io","kind":"functions"},"code":404}}
+`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			c := cli.NewDefaultConfig()
+			c.Stdout = output
+			c.Stderr = output
+			c.ErrorFormat = test.format
+
+			cli.PrintError(c, test.err)
+
+			if diff := cmp.Diff(test.expected, "\n"+output.String()); diff != "" {
+				t.Errorf("Unexpected output (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
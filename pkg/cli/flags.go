@@ -17,8 +17,10 @@
 package cli
 
 import (
+	"fmt"
 	"strings"
 
+	"github.com/projectriff/cli/pkg/kail"
 	"github.com/spf13/cobra"
 )
 
@@ -27,39 +29,68 @@ const (
 	AllNamespacesFlagName         = "--all-namespaces"
 	ApplicationRefFlagName        = "--application-ref"
 	ArtifactFlagName              = "--artifact"
+	AsFlagName                    = "--as"
+	AsGroupFlagName               = "--as-group"
+	BurstFlagName                 = "--burst"
 	CacheSizeFlagName             = "--cache-size"
+	ClusterFlagName               = "--cluster"
 	ConfigFlagName                = "--config"
 	ConfigurationRefFlagName      = "--configuration-ref"
+	ContainerFlagName             = "--container"
 	ContainerRefFlagName          = "--container-ref"
 	ContentTypeFlagName           = "--content-type"
+	ContextFlagName               = "--context"
+	CredentialFlagName            = "--credential"
 	DefaultImagePrefixFlagName    = "--default-image-prefix"
+	DiffFlagName                  = "--diff"
 	DirectoryFlagName             = "--directory"
+	DockerConfigFlagName          = "--docker-config"
 	DockerHubFlagName             = "--docker-hub"
 	DryRunFlagName                = "--dry-run"
 	EnvFlagName                   = "--env"
 	EnvFromFlagName               = "--env-from"
+	ErrorFormatFlagName           = "--error-format"
+	FormatFlagName                = "--format"
 	FunctionRefFlagName           = "--function-ref"
 	GcrFlagName                   = "--gcr"
 	GitRepoFlagName               = "--git-repo"
 	GitRevisionFlagName           = "--git-revision"
+	GrepFlagName                  = "--grep"
+	GroupFlagName                 = "--group"
 	HandlerFlagName               = "--handler"
 	ImageFlagName                 = "--image"
+	IngressHostFlagName           = "--ingress-host"
+	IngressPathFlagName           = "--ingress-path"
 	InputFlagName                 = "--input"
+	InteractiveFlagName           = "--interactive"
 	InvokerFlagName               = "--invoker"
 	KubeConfigFlagName            = "--kube-config"
 	LocalPathFlagName             = "--local-path"
 	NamespaceFlagName             = "--namespace"
 	NoColorFlagName               = "--no-color"
+	NoFollowFlagName              = "--no-follow"
 	OutputFlagName                = "--output"
+	PreviousFlagName              = "--previous"
+	ProfileFlagName               = "--profile"
 	ProviderFlagName              = "--provider"
+	QPSFlagName                   = "--qps"
 	RegistryFlagName              = "--registry"
+	RegistryTokenFileFlagName     = "--registry-token-file"
 	RegistryUserFlagName          = "--registry-user"
+	RequestTimeoutFlagName        = "--request-timeout"
+	RuntimeFlagName               = "--runtime"
+	SelectorFlagName              = "--selector"
 	ServiceRefFlagName            = "--service-ref"
 	SetDefaultImagePrefixFlagName = "--set-default-image-prefix"
 	ShellFlagName                 = "--shell"
 	SinceFlagName                 = "--since"
+	SinceTimeFlagName             = "--since-time"
 	SubPathFlagName               = "--sub-path"
 	TailFlagName                  = "--tail"
+	TimestampsFlagName            = "--timestamps"
+	UserFlagName                  = "--user"
+	VerbosityFlagName             = "--verbosity"
+	VerifyFlagName                = "--verify"
 	WaitTimeoutFlagName           = "--wait-timeout"
 )
 
@@ -101,6 +132,19 @@ func NamespaceFlag(cmd *cobra.Command, c *Config, namespace *string) {
 	}
 
 	cmd.Flags().StringVarP(namespace, StripDash(NamespaceFlagName), "n", "", "kubernetes `name`space (defaulted from kube config)")
+	CompleteFlagResource(cmd, NamespaceFlagName, NamespacesCompletion)
+}
+
+func TailFlags(cmd *cobra.Command, opts *TailOptions) {
+	cmd.Flags().StringVar(&opts.Since, StripDash(SinceFlagName), "", "time `duration` to start reading logs from")
+	cmd.Flags().StringVar(&opts.SinceTime, StripDash(SinceTimeFlagName), "", "RFC3339 `time` to start reading logs from")
+	cmd.Flags().BoolVar(&opts.Previous, StripDash(PreviousFlagName), false, "read logs from the previous instance of restarted containers, implies "+NoFollowFlagName)
+	cmd.Flags().BoolVar(&opts.NoFollow, StripDash(NoFollowFlagName), false, "read existing logs and exit, rather than waiting for new logs (reads all logs unless "+SinceFlagName+" or "+SinceTimeFlagName+" is set)")
+	cmd.Flags().StringArrayVar(&opts.Containers, StripDash(ContainerFlagName), []string{}, "`name` of container to read logs from, overrides the default containers (may be set multiple times)")
+	cmd.Flags().StringVar(&opts.Grep, StripDash(GrepFlagName), "", "only show log lines matching the `regex`")
+	cmd.Flags().StringVar(&opts.Output, StripDash(OutputFlagName), "", fmt.Sprintf("log output `format`, %q writes one object per line (default human readable)", kail.OutputJSON))
+	cmd.Flags().BoolVar(&opts.Timestamps, StripDash(TimestampsFlagName), false, "include the time each log line was written")
+	CompleteFlagValues(cmd, OutputFlagName, kail.OutputJSON)
 }
 
 func StripDash(flagName string) string {
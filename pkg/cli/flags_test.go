@@ -21,7 +21,9 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/projectriff/cli/pkg/cli"
+	"github.com/projectriff/cli/pkg/kail"
 	rifftesting "github.com/projectriff/cli/pkg/testing"
 	"github.com/spf13/cobra"
 )
@@ -175,6 +177,58 @@ func TestNamespaceFlag(t *testing.T) {
 	}
 }
 
+func TestTailFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected cli.TailOptions
+	}{{
+		name: "default",
+		args: []string{},
+		expected: cli.TailOptions{
+			Containers: []string{},
+		},
+	}, {
+		name: "all flags",
+		args: []string{
+			cli.SinceFlagName, "1h",
+			cli.SinceTimeFlagName, "2019-08-20T12:30:15Z",
+			cli.PreviousFlagName,
+			cli.NoFollowFlagName,
+			cli.ContainerFlagName, "my-container",
+			cli.ContainerFlagName, "my-other-container",
+			cli.GrepFlagName, "error",
+			cli.OutputFlagName, kail.OutputJSON,
+			cli.TimestampsFlagName,
+		},
+		expected: cli.TailOptions{
+			Since:      "1h",
+			SinceTime:  "2019-08-20T12:30:15Z",
+			Previous:   true,
+			NoFollow:   true,
+			Containers: []string{"my-container", "my-other-container"},
+			Grep:       "error",
+			Output:     kail.OutputJSON,
+			Timestamps: true,
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := cli.TailOptions{}
+			cmd := &cobra.Command{}
+			cli.TailFlags(cmd, &opts)
+
+			if err := cmd.ParseFlags(test.args); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.expected, opts); diff != "" {
+				t.Errorf("Unexpected options (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
+
 func TestStripDash(t *testing.T) {
 	tests := []struct {
 		name   string
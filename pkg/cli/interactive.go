@@ -0,0 +1,334 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Promptable options ask for values that are missing from the command line
+// when the command is run with --interactive.
+type Promptable interface {
+	Prompt(ctx context.Context, c *Config, p *Prompter) error
+}
+
+// InteractiveFlag defines the --interactive flag. When set, required
+// arguments that are missing are prompted for, the options are prompted for
+// missing flag values before the prior PreRunE, usually validation, and the
+// equivalent non-interactive command is printed.
+//
+// The prompts are installed when the flag is parsed, after the command's args
+// and flags are defined, so the flag may be defined before or after cli.Args
+// and NamespaceFlag. Choices listed from the cluster use the namespace flag, or
+// the default namespace.
+func InteractiveFlag(ctx context.Context, cmd *cobra.Command, c *Config, opts Promptable) {
+	v := &interactiveValue{ctx: ctx, cmd: cmd, c: c, opts: opts}
+	f := cmd.Flags().VarPF(v, StripDash(InteractiveFlagName), "", "prompt via stdin for required values that are not set, then print the equivalent command")
+	f.NoOptDefVal = "true"
+}
+
+type interactiveValue struct {
+	ctx       context.Context
+	cmd       *cobra.Command
+	c         *Config
+	opts      Promptable
+	enabled   bool
+	installed bool
+	// args given on the command line and prompted for
+	args []string
+}
+
+func (v *interactiveValue) String() string {
+	return strconv.FormatBool(v.enabled)
+}
+
+func (v *interactiveValue) Set(s string) error {
+	enabled, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	v.enabled = enabled
+	if enabled && !v.installed {
+		v.installed = true
+		v.install()
+	}
+	return nil
+}
+
+func (v *interactiveValue) Type() string {
+	return "bool"
+}
+
+func (v *interactiveValue) IsBoolFlag() bool {
+	return true
+}
+
+func (v *interactiveValue) install() {
+	cmd, c := v.cmd, v.c
+
+	priorArgs := cmd.Args
+	cmd.Args = func(cmd *cobra.Command, args []string) error {
+		p := &Prompter{c: c, cmd: cmd}
+		args, err := p.args(args)
+		if err != nil {
+			return err
+		}
+		v.args = args
+		if priorArgs != nil {
+			return priorArgs(cmd, args)
+		}
+		return nil
+	}
+
+	priorPreRunE := cmd.PreRunE
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if v.args != nil {
+			args = v.args
+		}
+		p := &Prompter{c: c, cmd: cmd}
+		if err := v.opts.Prompt(v.ctx, c, p); err != nil {
+			return err
+		}
+		c.Eprintf("\n")
+		c.Einfof("To run the same command without prompting:\n")
+		c.Eprintf("  %s\n\n", EquivalentCommand(cmd, args))
+		if priorPreRunE != nil {
+			return priorPreRunE(cmd, args)
+		}
+		return nil
+	}
+}
+
+// Prompter asks for flag values via stdin. Answers are set on the flag as if
+// they were given on the command line, so flags that are already set are not
+// prompted for.
+type Prompter struct {
+	c   *Config
+	cmd *cobra.Command
+}
+
+// Required prompts for the value of the flag until a value is given.
+func (p *Prompter) Required(flagName string) error {
+	return p.prompt(flagName, true)
+}
+
+// Optional prompts for the value of the flag, an empty answer keeps the
+// flag's default.
+func (p *Prompter) Optional(flagName string) error {
+	return p.prompt(flagName, false)
+}
+
+// OneOf prompts to choose which of the mutually exclusive flags to set, and
+// then for the value of that flag. Nothing is prompted for when any of the
+// flags is already set.
+func (p *Prompter) OneOf(flagNames ...string) error {
+	for _, flagName := range flagNames {
+		f, err := p.lookup(flagName)
+		if err != nil {
+			return err
+		}
+		if f.Changed {
+			return nil
+		}
+	}
+
+	p.c.Eprintf("Choose one of:\n")
+	for i, flagName := range flagNames {
+		f, _ := p.lookup(flagName)
+		_, usage := pflag.UnquoteUsage(f)
+		p.c.Eprintf("  %d) %s  %s\n", i+1, flagName, Sfaintf(usage))
+	}
+	for {
+		p.c.Eprintf("> ")
+		answer, err := p.readLine()
+		if answer == "" && err != nil {
+			return fmt.Errorf("expected one of %s", strings.Join(flagNames, ", "))
+		}
+		for i, flagName := range flagNames {
+			if answer == strconv.Itoa(i+1) || answer == flagName || answer == StripDash(flagName) {
+				return p.Required(flagName)
+			}
+		}
+		p.c.Eerrorf("Invalid choice %q\n", answer)
+	}
+}
+
+func (p *Prompter) prompt(flagName string, required bool) error {
+	f, err := p.lookup(flagName)
+	if err != nil {
+		return err
+	}
+	if f.Changed {
+		return nil
+	}
+
+	_, usage := pflag.UnquoteUsage(f)
+	p.c.Eprintf("%s %s\n", flagName, Sfaintf(usage))
+	choices := p.choices(f)
+	for i, choice := range choices {
+		p.c.Eprintf("  %d) %s\n", i+1, choice)
+	}
+	prompt := "> "
+	if !required && f.DefValue != "" && f.DefValue != "[]" {
+		prompt = fmt.Sprintf("[%s] > ", f.DefValue)
+	}
+
+	for {
+		p.c.Eprintf("%s", prompt)
+		answer, err := p.readLine()
+		if answer == "" {
+			if !required {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("expected a value for %s", flagName)
+			}
+			p.c.Eerrorf("A value is required\n")
+			continue
+		}
+		if i, err := strconv.Atoi(answer); err == nil && i > 0 && i <= len(choices) {
+			answer = choices[i-1]
+		}
+		if err := p.cmd.Flags().Set(f.Name, answer); err != nil {
+			p.c.Eerrorf("Invalid value %q: %v\n", answer, err)
+			continue
+		}
+		return nil
+	}
+}
+
+// args prompts for the leading required arguments, like the name, that are
+// missing from the command line.
+func (p *Prompter) args(args []string) ([]string, error) {
+	length, _ := strconv.Atoi(p.cmd.Annotations["args.length"])
+	for i := 0; i < length; i++ {
+		arity := p.cmd.Annotations[fmt.Sprintf("args[%d].arity", i)]
+		optional := p.cmd.Annotations[fmt.Sprintf("args[%d].optional", i)]
+		if arity != "1" || optional == "true" {
+			break
+		}
+		if i < len(args) {
+			continue
+		}
+		name := p.cmd.Annotations[fmt.Sprintf("args[%d].name", i)]
+		p.c.Eprintf("<%s>\n", name)
+		for {
+			p.c.Eprintf("> ")
+			answer, err := p.readLine()
+			if answer != "" {
+				args = append(args, answer)
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("expected a value for <%s>", name)
+			}
+			p.c.Eerrorf("A value is required\n")
+		}
+	}
+	return args, nil
+}
+
+func (p *Prompter) lookup(flagName string) (*pflag.Flag, error) {
+	f := p.cmd.Flags().Lookup(StripDash(flagName))
+	if f == nil {
+		return nil, fmt.Errorf("unknown flag %s", flagName)
+	}
+	return f, nil
+}
+
+// choices offers the values the flag is completed with, resources are listed
+// from the cluster.
+func (p *Prompter) choices(f *pflag.Flag) []string {
+	if values, ok := f.Annotations[completionValuesAnnotation]; ok {
+		return values
+	}
+	if resource, ok := f.Annotations[completionResourceAnnotation]; ok && len(resource) != 0 {
+		return resourceNames(p.c, p.cmd, resource[0])
+	}
+	return nil
+}
+
+// readLine reads a line from stdin a byte at a time, leaving the remainder of
+// stdin for the command, like a password that is read after prompting.
+func (p *Prompter) readLine() (string, error) {
+	line := []byte{}
+	b := make([]byte, 1)
+	for {
+		n, err := p.c.Stdin.Read(b)
+		if n == 1 {
+			if b[0] == '\n' {
+				return strings.TrimSpace(string(line)), nil
+			}
+			line = append(line, b[0])
+		}
+		if err != nil {
+			if err == io.EOF && len(line) != 0 {
+				return strings.TrimSpace(string(line)), nil
+			}
+			return strings.TrimSpace(string(line)), err
+		}
+	}
+}
+
+// EquivalentCommand formats the command line that runs the command with the
+// args and the flags that are set, except for --interactive.
+func EquivalentCommand(cmd *cobra.Command, args []string) string {
+	parts := []string{cmd.CommandPath()}
+	for _, arg := range args {
+		parts = append(parts, shellQuote(arg))
+	}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		name := "--" + f.Name
+		switch {
+		case f.Name == StripDash(InteractiveFlagName):
+		case f.Value.Type() == "stringArray":
+			values, _ := cmd.Flags().GetStringArray(f.Name)
+			for _, value := range values {
+				parts = append(parts, name, shellQuote(value))
+			}
+		case f.Value.Type() == "stringSlice":
+			values, _ := cmd.Flags().GetStringSlice(f.Name)
+			for _, value := range values {
+				parts = append(parts, name, shellQuote(value))
+			}
+		case f.NoOptDefVal != "" && f.Value.String() == f.NoOptDefVal:
+			parts = append(parts, name)
+		case f.NoOptDefVal != "":
+			parts = append(parts, name+"="+shellQuote(f.Value.String()))
+		default:
+			parts = append(parts, name, shellQuote(f.Value.String()))
+		}
+	})
+	return strings.Join(parts, " ")
+}
+
+var shellSafe = regexp.MustCompile(`^[a-zA-Z0-9_./:=@%+,-]+$`)
+
+func shellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
@@ -0,0 +1,264 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/projectriff/cli/pkg/cli"
+	rifftesting "github.com/projectriff/cli/pkg/testing"
+	streamv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type promptOptions func(p *cli.Prompter) error
+
+func (opts promptOptions) Prompt(ctx context.Context, c *cli.Config, p *cli.Prompter) error {
+	return opts(p)
+}
+
+func TestInteractiveFlag(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		defineFirst    bool
+		stdin          string
+		prompt         promptOptions
+		expectedImage  string
+		expectedInputs []string
+		expectedOutput string
+		err            error
+	}{{
+		name: "not interactive",
+		args: []string{"my-name"},
+		prompt: func(p *cli.Prompter) error {
+			return fmt.Errorf("unexpected prompt")
+		},
+	}, {
+		name:  "required",
+		args:  []string{"my-name", cli.InteractiveFlagName},
+		stdin: "\nmy-image\n",
+		prompt: func(p *cli.Prompter) error {
+			return p.Required(cli.ImageFlagName)
+		},
+		expectedImage: "my-image",
+		expectedOutput: `
+--image container image
+> A value is required
+> 
+To run the same command without prompting:
+  test my-name --image my-image
+`,
+	}, {
+		name:  "name",
+		args:  []string{cli.InteractiveFlagName, cli.ImageFlagName, "my-image"},
+		stdin: "\nmy-name\n",
+		prompt: func(p *cli.Prompter) error {
+			return p.Required(cli.ImageFlagName)
+		},
+		expectedImage: "my-image",
+		expectedOutput: `
+<name>
+> A value is required
+> 
+To run the same command without prompting:
+  test my-name --image my-image
+`,
+	}, {
+		name:        "name, defined before args and namespace",
+		args:        []string{cli.InteractiveFlagName},
+		defineFirst: true,
+		stdin:       "my-name\n1\n",
+		prompt: func(p *cli.Prompter) error {
+			return p.Required(cli.InputFlagName)
+		},
+		expectedInputs: []string{"my-stream"},
+		expectedOutput: `
+<name>
+> --input name of stream
+  1) my-stream
+  2) other-stream
+> 
+To run the same command without prompting:
+  test my-name --input my-stream
+`,
+	}, {
+		name:  "name, no answer",
+		args:  []string{cli.InteractiveFlagName},
+		stdin: "",
+		prompt: func(p *cli.Prompter) error {
+			return nil
+		},
+		err: fmt.Errorf("expected a value for <name>"),
+	}, {
+		name:  "required, no answer",
+		args:  []string{"my-name", cli.InteractiveFlagName},
+		stdin: "",
+		prompt: func(p *cli.Prompter) error {
+			return p.Required(cli.ImageFlagName)
+		},
+		err: fmt.Errorf("expected a value for %s", cli.ImageFlagName),
+	}, {
+		name:  "already set",
+		args:  []string{"my-name", cli.InteractiveFlagName, cli.ImageFlagName, "my-image"},
+		stdin: "",
+		prompt: func(p *cli.Prompter) error {
+			return p.Required(cli.ImageFlagName)
+		},
+		expectedImage: "my-image",
+		expectedOutput: `
+To run the same command without prompting:
+  test my-name --image my-image
+`,
+	}, {
+		name:  "optional",
+		args:  []string{"my-name", cli.InteractiveFlagName},
+		stdin: "\n",
+		prompt: func(p *cli.Prompter) error {
+			return p.Optional(cli.InputFlagName)
+		},
+		expectedInputs: []string{},
+		expectedOutput: `
+--input name of stream
+  1) my-stream
+  2) other-stream
+> 
+To run the same command without prompting:
+  test my-name
+`,
+	}, {
+		name:  "choice",
+		args:  []string{"my-name", cli.InteractiveFlagName},
+		stdin: "2\n",
+		prompt: func(p *cli.Prompter) error {
+			return p.Required(cli.InputFlagName)
+		},
+		expectedInputs: []string{"other-stream"},
+		expectedOutput: `
+--input name of stream
+  1) my-stream
+  2) other-stream
+> 
+To run the same command without prompting:
+  test my-name --input other-stream
+`,
+	}, {
+		name:  "one of",
+		args:  []string{"my-name", cli.InteractiveFlagName},
+		stdin: "bogus\n--input\nmy stream's\n",
+		prompt: func(p *cli.Prompter) error {
+			return p.OneOf(cli.ImageFlagName, cli.InputFlagName)
+		},
+		expectedInputs: []string{"my stream's"},
+		expectedOutput: `
+Choose one of:
+  1) --image  container image
+  2) --input  name of stream
+> Invalid choice "bogus"
+> --input name of stream
+  1) my-stream
+  2) other-stream
+> 
+To run the same command without prompting:
+  test my-name --input 'my stream'"'"'s'
+`,
+	}, {
+		name:  "one of, already set",
+		args:  []string{"my-name", cli.InteractiveFlagName, cli.ImageFlagName, "my-image"},
+		stdin: "",
+		prompt: func(p *cli.Prompter) error {
+			return p.OneOf(cli.ImageFlagName, cli.InputFlagName)
+		},
+		expectedImage: "my-image",
+		expectedOutput: `
+To run the same command without prompting:
+  test my-name --image my-image
+`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			c := cli.NewDefaultConfig()
+			c.Client = rifftesting.NewClient(
+				&streamv1alpha1.Stream{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-stream"},
+				},
+				&streamv1alpha1.Stream{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other-stream"},
+				},
+			)
+			c.Stdin = strings.NewReader(test.stdin)
+			c.Stdout = output
+			c.Stderr = output
+
+			name, namespace, image, inputs := "", "", "", []string{}
+			cmd := &cobra.Command{
+				Use: "test",
+				RunE: func(cmd *cobra.Command, args []string) error {
+					return nil
+				},
+			}
+			if test.defineFirst {
+				cli.InteractiveFlag(context.Background(), cmd, c, test.prompt)
+			}
+			cli.Args(cmd, cli.NameArg(&name))
+			cli.NamespaceFlag(cmd, c, &namespace)
+			if !test.defineFirst {
+				cli.InteractiveFlag(context.Background(), cmd, c, test.prompt)
+			}
+			cmd.Flags().StringVar(&image, cli.StripDash(cli.ImageFlagName), "", "container `image`")
+			cmd.Flags().StringArrayVar(&inputs, cli.StripDash(cli.InputFlagName), []string{}, "`name` of stream")
+			cli.CompleteFlagResource(cmd, cli.InputFlagName, cli.StreamsCompletion)
+
+			cmd.SetArgs(test.args)
+			cmd.SetOutput(&bytes.Buffer{})
+			err := cmd.Execute()
+
+			if expected, actual := fmt.Sprintf("%s", test.err), fmt.Sprintf("%s", err); expected != actual {
+				t.Errorf("Expected error %q, actually %q", expected, actual)
+			}
+			if err != nil {
+				return
+			}
+			if expected, actual := "my-name", name; expected != actual {
+				t.Errorf("Expected name %q, actually %q", expected, actual)
+			}
+			if expected, actual := "default", namespace; expected != actual {
+				t.Errorf("Expected namespace %q, actually %q", expected, actual)
+			}
+			if expected, actual := test.expectedImage, image; expected != actual {
+				t.Errorf("Expected image %q, actually %q", expected, actual)
+			}
+			if test.expectedInputs == nil {
+				test.expectedInputs = []string{}
+			}
+			if diff := cmp.Diff(test.expectedInputs, inputs); diff != "" {
+				t.Errorf("Unexpected inputs (-expected, +actual): %s", diff)
+			}
+			if diff := cmp.Diff(strings.TrimSpace(test.expectedOutput), strings.TrimSpace(output.String())); diff != "" {
+				t.Errorf("Unexpected output (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
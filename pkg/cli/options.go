@@ -18,8 +18,11 @@ package cli
 
 import (
 	"context"
+	"regexp"
+	"time"
 
 	"github.com/knative/pkg/apis"
+	"github.com/projectriff/cli/pkg/kail"
 	"github.com/projectriff/cli/pkg/validation"
 	"github.com/spf13/cobra"
 )
@@ -135,3 +138,75 @@ func (opts *DeleteOptions) Validate(ctx context.Context) *FieldError {
 
 	return errs
 }
+
+type TailOptions struct {
+	Since      string
+	SinceTime  string
+	Previous   bool
+	NoFollow   bool
+	Containers []string
+	Grep       string
+	Output     string
+	Timestamps bool
+}
+
+func (opts *TailOptions) Validate(ctx context.Context) *FieldError {
+	errs := &FieldError{}
+
+	if opts.Since != "" {
+		if _, err := time.ParseDuration(opts.Since); err != nil {
+			errs = errs.Also(ErrInvalidValue(opts.Since, SinceFlagName))
+		}
+	}
+	if opts.SinceTime != "" {
+		if sinceTime, err := time.Parse(time.RFC3339, opts.SinceTime); err != nil || sinceTime.After(time.Now()) {
+			// there are no logs from the future to read
+			errs = errs.Also(ErrInvalidValue(opts.SinceTime, SinceTimeFlagName))
+		}
+	}
+	if opts.Since != "" && opts.SinceTime != "" {
+		errs = errs.Also(ErrMultipleOneOf(SinceFlagName, SinceTimeFlagName))
+	}
+
+	errs = errs.Also(validation.K8sNames(opts.Containers, ContainerFlagName))
+
+	if opts.Grep != "" {
+		if _, err := regexp.Compile(opts.Grep); err != nil {
+			errs = errs.Also(ErrInvalidValue(opts.Grep, GrepFlagName))
+		}
+	}
+
+	if opts.Output != "" && opts.Output != kail.OutputJSON {
+		errs = errs.Also(ErrInvalidValue(opts.Output, OutputFlagName))
+	}
+
+	return errs
+}
+
+// LogOptions converts validated tail options into options for streaming logs
+func (opts *TailOptions) LogOptions() kail.LogOptions {
+	since := TailSinceDefault
+	if opts.NoFollow || opts.Previous {
+		// read all existing logs
+		since = 0
+	}
+	if opts.Since != "" {
+		// error is protected by Validate()
+		since, _ = time.ParseDuration(opts.Since)
+	}
+	sinceTime := time.Time{}
+	if opts.SinceTime != "" {
+		// error is protected by Validate()
+		sinceTime, _ = time.Parse(time.RFC3339, opts.SinceTime)
+	}
+	return kail.LogOptions{
+		Since:      since,
+		SinceTime:  sinceTime,
+		Previous:   opts.Previous,
+		NoFollow:   opts.NoFollow,
+		Containers: opts.Containers,
+		Grep:       opts.Grep,
+		Output:     opts.Output,
+		Timestamps: opts.Timestamps,
+	}
+}
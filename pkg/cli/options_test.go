@@ -21,8 +21,11 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/projectriff/cli/pkg/cli"
+	"github.com/projectriff/cli/pkg/kail"
 	rifftesting "github.com/projectriff/cli/pkg/testing"
 	"github.com/spf13/cobra"
 )
@@ -293,3 +296,153 @@ func TestDeleteOptions(t *testing.T) {
 
 	table.Run(t)
 }
+
+func TestTailOptions(t *testing.T) {
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+
+	table := rifftesting.OptionsTable{
+		{
+			Name:           "default",
+			Options:        &cli.TailOptions{},
+			ShouldValidate: true,
+		},
+		{
+			Name: "since time",
+			Options: &cli.TailOptions{
+				SinceTime: "2019-08-20T12:30:15Z",
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "since duration",
+			Options: &cli.TailOptions{
+				Since: "1m",
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "invalid duration",
+			Options: &cli.TailOptions{
+				Since: "1",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("1", cli.SinceFlagName),
+		},
+		{
+			Name: "invalid since time",
+			Options: &cli.TailOptions{
+				SinceTime: "1h",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("1h", cli.SinceTimeFlagName),
+		},
+		{
+			Name: "since and since time",
+			Options: &cli.TailOptions{
+				Since:     "1h",
+				SinceTime: "2019-08-20T12:30:15Z",
+			},
+			ExpectFieldError: cli.ErrMultipleOneOf(cli.SinceFlagName, cli.SinceTimeFlagName),
+		},
+		{
+			Name: "filtered json output",
+			Options: &cli.TailOptions{
+				Containers: []string{"my-container"},
+				Grep:       "error",
+				Output:     kail.OutputJSON,
+				Timestamps: true,
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "invalid container",
+			Options: &cli.TailOptions{
+				Containers: []string{"my.container"},
+			},
+			ExpectFieldError: cli.ErrInvalidArrayValue("my.container", cli.ContainerFlagName, 0),
+		},
+		{
+			Name: "invalid grep",
+			Options: &cli.TailOptions{
+				Grep: "(",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("(", cli.GrepFlagName),
+		},
+		{
+			Name: "invalid output",
+			Options: &cli.TailOptions{
+				Output: "yaml",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("yaml", cli.OutputFlagName),
+		},
+		{
+			Name: "since time in the future",
+			Options: &cli.TailOptions{
+				SinceTime: future,
+			},
+			ExpectFieldError: cli.ErrInvalidValue(future, cli.SinceTimeFlagName),
+		},
+	}
+
+	table.Run(t)
+}
+
+func TestTailOptions_LogOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     cli.TailOptions
+		expected kail.LogOptions
+	}{{
+		name: "default",
+		expected: kail.LogOptions{
+			Since: cli.TailSinceDefault,
+		},
+	}, {
+		name: "since",
+		opts: cli.TailOptions{
+			Since: "1h",
+		},
+		expected: kail.LogOptions{
+			Since: time.Hour,
+		},
+	}, {
+		name: "filtered json output",
+		opts: cli.TailOptions{
+			Containers: []string{"my-container"},
+			Grep:       "error",
+			Output:     kail.OutputJSON,
+			Timestamps: true,
+		},
+		expected: kail.LogOptions{
+			Since:      cli.TailSinceDefault,
+			Containers: []string{"my-container"},
+			Grep:       "error",
+			Output:     kail.OutputJSON,
+			Timestamps: true,
+		},
+	}, {
+		name: "no follow",
+		opts: cli.TailOptions{
+			NoFollow: true,
+		},
+		expected: kail.LogOptions{
+			NoFollow: true,
+		},
+	}, {
+		name: "previous since time",
+		opts: cli.TailOptions{
+			Previous:  true,
+			SinceTime: "2019-08-20T12:30:15Z",
+		},
+		expected: kail.LogOptions{
+			SinceTime: time.Date(2019, time.August, 20, 12, 30, 15, 0, time.UTC),
+			Previous:  true,
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := cmp.Diff(test.expected, test.opts.LogOptions()); diff != "" {
+				t.Errorf("Unexpected log options (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"sort"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	ProfileKey  = "profile"
+	ProfilesKey = "profiles"
+)
+
+// Profile is a named set of settings in the config file. The active profile is
+// selected by the profile key, which may be overridden by the environment.
+type Profile struct {
+	KubeConfig string            `json:"kube-config,omitempty" mapstructure:"kube-config"`
+	Context    string            `json:"context,omitempty" mapstructure:"context"`
+	Namespace  string            `json:"namespace,omitempty" mapstructure:"namespace"`
+	Runtimes   []string          `json:"runtimes,omitempty" mapstructure:"runtimes"`
+	Flags      map[string]string `json:"flags,omitempty" mapstructure:"flags"`
+}
+
+// applyProfile loads the active profile from the config file. Settings from
+// flags take precedence over the profile.
+func (c *Config) applyProfile() error {
+	name := viper.GetString(ProfileKey)
+	if name == "" {
+		return nil
+	}
+	key := fmt.Sprintf("%s.%s", ProfilesKey, name)
+	if !viper.IsSet(key) {
+		return fmt.Errorf("profile %q not found in config file", name)
+	}
+	profile := Profile{}
+	if err := viper.UnmarshalKey(key, &profile); err != nil {
+		return fmt.Errorf("invalid profile %q: %v", name, err)
+	}
+	c.Profile = name
+
+	if c.KubeConfigFile == "" && profile.KubeConfig != "" {
+		kubeConfigFile, err := homedir.Expand(profile.KubeConfig)
+		if err != nil {
+			return err
+		}
+		c.KubeConfigFile = kubeConfigFile
+	}
+	if c.KubeConfigOverrides.CurrentContext == "" {
+		c.KubeConfigOverrides.CurrentContext = profile.Context
+	}
+	if c.KubeConfigOverrides.Context.Namespace == "" {
+		c.KubeConfigOverrides.Context.Namespace = profile.Namespace
+	}
+	if len(profile.Runtimes) != 0 {
+		runtimes := map[string]bool{}
+		for _, runtime := range profile.Runtimes {
+			switch runtime {
+			case CoreRuntime, StreamingRuntime, KnativeRuntime:
+				runtimes[runtime] = true
+			default:
+				return fmt.Errorf("invalid runtime %q in profile %q", runtime, name)
+			}
+		}
+		c.Runtimes = runtimes
+	}
+	c.FlagDefaults = profile.Flags
+
+	return nil
+}
+
+// ApplyFlagDefaults sets the value of flags on the command from the active
+// profile. Flags set on the command line are not changed.
+func ApplyFlagDefaults(cmd *cobra.Command, c *Config) error {
+	names := []string{}
+	for name := range c.FlagDefaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		f := cmd.Flags().Lookup(name)
+		if f == nil || f.Changed {
+			continue
+		}
+		// set the value directly so the flag is not considered changed
+		if err := f.Value.Set(c.FlagDefaults[name]); err != nil {
+			return fmt.Errorf("invalid value %q for flag --%s from profile %q: %v", c.FlagDefaults[name], name, c.Profile, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/viper"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestApplyProfile(t *testing.T) {
+	tests := []struct {
+		name               string
+		env                string
+		kubeConfigFile     string
+		overrides          clientcmd.ConfigOverrides
+		profile            string
+		expectedProfile    string
+		expectedKubeConfig string
+		expectedOverrides  clientcmd.ConfigOverrides
+		expectedRuntimes   map[string]bool
+		expectedFlags      map[string]string
+		expectedErr        string
+	}{{
+		name:               "active profile",
+		expectedProfile:    "dev",
+		expectedKubeConfig: "testdata/.kube/config",
+		expectedOverrides: clientcmd.ConfigOverrides{
+			CurrentContext: "other-context",
+			Context:        clientcmdapi.Context{Namespace: "my-team"},
+		},
+		expectedRuntimes: env.Runtimes,
+		expectedFlags: map[string]string{
+			"wait-timeout": "5m",
+		},
+	}, {
+		name:            "profile from env",
+		env:             "kind",
+		expectedProfile: "kind",
+		expectedOverrides: clientcmd.ConfigOverrides{
+			CurrentContext: "kind-kind",
+		},
+		expectedRuntimes: map[string]bool{
+			CoreRuntime:      true,
+			StreamingRuntime: true,
+		},
+	}, {
+		name:           "flags take precedence",
+		kubeConfigFile: "other/config",
+		overrides: clientcmd.ConfigOverrides{
+			CurrentContext: "my-context",
+			Context:        clientcmdapi.Context{Namespace: "my-namespace"},
+		},
+		expectedProfile:    "dev",
+		expectedKubeConfig: "other/config",
+		expectedOverrides: clientcmd.ConfigOverrides{
+			CurrentContext: "my-context",
+			Context:        clientcmdapi.Context{Namespace: "my-namespace"},
+		},
+		expectedRuntimes: env.Runtimes,
+		expectedFlags: map[string]string{
+			"wait-timeout": "5m",
+		},
+	}, {
+		name:        "missing profile",
+		env:         "missing",
+		expectedErr: `profile "missing" not found in config file`,
+	}, {
+		name:        "invalid runtime",
+		env:         "invalid",
+		expectedErr: `invalid runtime "bogus" in profile "invalid"`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer viper.Reset()
+			if test.env != "" {
+				os.Setenv("RIFF_PROFILE", test.env)
+				defer os.Unsetenv("RIFF_PROFILE")
+			}
+
+			c := NewDefaultConfig()
+			c.KubeConfigFile = test.kubeConfigFile
+			c.KubeConfigOverrides = test.overrides
+			viper.SetConfigFile("testdata/profiles.yaml")
+			viper.SetEnvPrefix(c.Name)
+			viper.AutomaticEnv()
+			if err := viper.ReadInConfig(); err != nil {
+				t.Fatalf("unable to read config: %v", err)
+			}
+
+			err := c.applyProfile()
+			if test.expectedErr != "" {
+				if err == nil || err.Error() != test.expectedErr {
+					t.Errorf("Expected error %q, actually %v", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, actually %v", err)
+			}
+
+			if expected, actual := test.expectedProfile, c.Profile; expected != actual {
+				t.Errorf("Expected profile %q, actually %q", expected, actual)
+			}
+			if expected, actual := test.expectedKubeConfig, c.KubeConfigFile; expected != actual {
+				t.Errorf("Expected kube config %q, actually %q", expected, actual)
+			}
+			if diff := cmp.Diff(test.expectedOverrides, c.KubeConfigOverrides); diff != "" {
+				t.Errorf("Unexpected overrides (-expected, +actual): %s", diff)
+			}
+			if diff := cmp.Diff(test.expectedRuntimes, c.Runtimes); diff != "" {
+				t.Errorf("Unexpected runtimes (-expected, +actual): %s", diff)
+			}
+			if diff := cmp.Diff(test.expectedFlags, c.FlagDefaults); diff != "" {
+				t.Errorf("Unexpected flag defaults (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
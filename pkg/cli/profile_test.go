@@ -0,0 +1,105 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli_test
+
+import (
+	"testing"
+
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+func TestApplyFlagDefaults(t *testing.T) {
+	tests := []struct {
+		name            string
+		args            []string
+		defaults        map[string]string
+		expectedImage   string
+		expectedTimeout string
+		expectedChanged bool
+		expectedErr     string
+	}{{
+		name:            "no defaults",
+		expectedTimeout: "10m",
+	}, {
+		name: "defaults",
+		defaults: map[string]string{
+			"image":        "registry.example.com/image",
+			"wait-timeout": "5m",
+		},
+		expectedImage:   "registry.example.com/image",
+		expectedTimeout: "5m",
+	}, {
+		name: "flags take precedence",
+		args: []string{cli.WaitTimeoutFlagName, "1m"},
+		defaults: map[string]string{
+			"wait-timeout": "5m",
+		},
+		expectedTimeout: "1m",
+		expectedChanged: true,
+	}, {
+		name: "unknown flags are ignored",
+		defaults: map[string]string{
+			"bogus": "value",
+		},
+		expectedTimeout: "10m",
+	}, {
+		name: "invalid value",
+		defaults: map[string]string{
+			"tail": "yes",
+		},
+		expectedErr: `invalid value "yes" for flag --tail from profile "dev": strconv.ParseBool: parsing "yes": invalid syntax`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := cli.NewDefaultConfig()
+			c.Profile = "dev"
+			c.FlagDefaults = test.defaults
+
+			var image, timeout string
+			cmd := &cobra.Command{}
+			cmd.Flags().StringVar(&image, cli.StripDash(cli.ImageFlagName), "", "")
+			cmd.Flags().StringVar(&timeout, cli.StripDash(cli.WaitTimeoutFlagName), "10m", "")
+			cmd.Flags().Bool(cli.StripDash(cli.TailFlagName), false, "")
+			if err := cmd.ParseFlags(test.args); err != nil {
+				t.Fatalf("unable to parse flags: %v", err)
+			}
+
+			err := cli.ApplyFlagDefaults(cmd, c)
+			if test.expectedErr != "" {
+				if err == nil || err.Error() != test.expectedErr {
+					t.Errorf("Expected error %q, actually %v", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, actually %v", err)
+			}
+
+			if expected, actual := test.expectedImage, image; expected != actual {
+				t.Errorf("Expected image %q, actually %q", expected, actual)
+			}
+			if expected, actual := test.expectedTimeout, timeout; expected != actual {
+				t.Errorf("Expected wait timeout %q, actually %q", expected, actual)
+			}
+			if expected, actual := test.expectedChanged, cmd.Flags().Changed(cli.StripDash(cli.WaitTimeoutFlagName)); expected != actual {
+				t.Errorf("Expected wait timeout changed %v, actually %v", expected, actual)
+			}
+		})
+	}
+}
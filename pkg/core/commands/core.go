@@ -32,7 +32,7 @@ func NewCoreCommand(ctx context.Context, c *cli.Config) *cobra.Command {
 The core runtime uses stock kubernetes resources to deploy a workload. A
 Deployment is created along with a Service to forward traffic to the deployment.
 
-Ingress and autoscalers are not provided.
+Autoscalers are not provided. Ingress may optionally be created for a deployer.
 `),
 	}
 
@@ -39,16 +39,20 @@ Users wishing to perform checks on built images before deploying them can
 provide their own external process to watch the build for new images and only
 update the deployer image once those checks pass.
 
-The service to access the deployer is available in the deployer listing.
+The service to access the deployer is available in the deployer listing. If
+the deployer is exposed with an ingress, the ingress URL is also listed.
 `),
 		Aliases: []string{"deployers"},
 	}
 
+	cli.CompleteNameArgs(cmd, cli.CoreDeployersCompletion)
+
 	cmd.AddCommand(NewDeployerListCommand(ctx, c))
 	cmd.AddCommand(NewDeployerCreateCommand(ctx, c))
 	cmd.AddCommand(NewDeployerDeleteCommand(ctx, c))
 	cmd.AddCommand(NewDeployerStatusCommand(ctx, c))
 	cmd.AddCommand(NewDeployerTailCommand(ctx, c))
+	cmd.AddCommand(NewDeployerPortForwardCommand(ctx, c))
 
 	return cmd
 }
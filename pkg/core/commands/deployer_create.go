@@ -18,19 +18,26 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/projectriff/cli/pkg/cli"
 	"github.com/projectriff/cli/pkg/k8s"
+	"github.com/projectriff/cli/pkg/kail"
 	"github.com/projectriff/cli/pkg/parsers"
 	"github.com/projectriff/cli/pkg/race"
 	"github.com/projectriff/cli/pkg/validation"
+	"github.com/projectriff/system/pkg/apis/core"
 	corev1alpha1 "github.com/projectriff/system/pkg/apis/core/v1alpha1"
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
 )
 
 type DeployerCreateOptions struct {
@@ -44,6 +51,9 @@ type DeployerCreateOptions struct {
 	Env     []string
 	EnvFrom []string
 
+	IngressHost string
+	IngressPath string
+
 	Tail        bool
 	WaitTimeout string
 
@@ -54,6 +64,7 @@ var (
 	_ cli.Validatable = (*DeployerCreateOptions)(nil)
 	_ cli.Executable  = (*DeployerCreateOptions)(nil)
 	_ cli.DryRunable  = (*DeployerCreateOptions)(nil)
+	_ cli.Promptable  = (*DeployerCreateOptions)(nil)
 )
 
 func (opts *DeployerCreateOptions) Validate(ctx context.Context) *cli.FieldError {
@@ -85,6 +96,7 @@ func (opts *DeployerCreateOptions) Validate(ctx context.Context) *cli.FieldError
 
 	if opts.Image != "" {
 		used = append(used, cli.ImageFlagName)
+		errs = errs.Also(validation.ImageRef(opts.Image, cli.ImageFlagName))
 	} else {
 		unused = append(unused, cli.ImageFlagName)
 	}
@@ -98,7 +110,21 @@ func (opts *DeployerCreateOptions) Validate(ctx context.Context) *cli.FieldError
 	errs = errs.Also(validation.EnvVars(opts.Env, cli.EnvFlagName))
 	errs = errs.Also(validation.EnvVarFroms(opts.EnvFrom, cli.EnvFromFlagName))
 
-	if opts.Tail {
+	if opts.IngressHost != "" {
+		if out := k8svalidation.IsDNS1123Subdomain(opts.IngressHost); len(out) != 0 {
+			errs = errs.Also(cli.ErrInvalidValue(opts.IngressHost, cli.IngressHostFlagName))
+		}
+	}
+	if opts.IngressPath != "" {
+		if opts.IngressHost == "" {
+			errs = errs.Also(cli.ErrMissingField(cli.IngressHostFlagName))
+		}
+		if !strings.HasPrefix(opts.IngressPath, "/") {
+			errs = errs.Also(cli.ErrInvalidValue(opts.IngressPath, cli.IngressPathFlagName))
+		}
+	}
+
+	if opts.Tail || opts.IngressHost != "" {
 		if opts.WaitTimeout == "" {
 			errs = errs.Also(cli.ErrMissingField(cli.WaitTimeoutFlagName))
 		} else if _, err := time.ParseDuration(opts.WaitTimeout); err != nil {
@@ -109,6 +135,9 @@ func (opts *DeployerCreateOptions) Validate(ctx context.Context) *cli.FieldError
 	if opts.DryRun && opts.Tail {
 		errs = errs.Also(cli.ErrMultipleOneOf(cli.DryRunFlagName, cli.TailFlagName))
 	}
+	if opts.DryRun && opts.IngressHost != "" {
+		errs = errs.Also(cli.ErrMultipleOneOf(cli.DryRunFlagName, cli.IngressHostFlagName))
+	}
 
 	return errs
 }
@@ -159,7 +188,15 @@ func (opts *DeployerCreateOptions) Exec(ctx context.Context, c *cli.Config) erro
 	}
 
 	if opts.DryRun {
-		cli.DryRunResource(ctx, deployer, deployer.GetGroupVersionKind())
+		get := func() (runtime.Object, error) {
+			return c.CoreRuntime().Deployers(opts.Namespace).Get(deployer.Name, metav1.GetOptions{})
+		}
+		create := func() (runtime.Object, error) {
+			return k8s.DryRunCreate(c.CoreRuntime().RESTClient(), "deployers", deployer)
+		}
+		if err := cli.DryRunCreate(ctx, c, deployer, deployer.GetGroupVersionKind(), get, create); err != nil {
+			return err
+		}
 	} else {
 		var err error
 		deployer, err = c.CoreRuntime().Deployers(opts.Namespace).Create(deployer)
@@ -168,6 +205,21 @@ func (opts *DeployerCreateOptions) Exec(ctx context.Context, c *cli.Config) erro
 		}
 	}
 	c.Successf("Created deployer %q\n", deployer.Name)
+	if opts.IngressHost != "" {
+		// err guarded by Validate()
+		timeout, _ := time.ParseDuration(opts.WaitTimeout)
+		ingress, err := opts.createIngress(ctx, c, deployer, timeout)
+		if errors.Is(err, k8s.ErrWaitTimeout) || errors.Is(err, context.DeadlineExceeded) {
+			c.Errorf("Timeout after %q waiting for a service for %q\n", opts.WaitTimeout, opts.Name)
+			c.Infof("Deployer %q was created, but its service is not ready so no ingress was created\n", opts.Name)
+			c.Infof("To view status run: %s core deployer list %s %s\n", c.Name, cli.NamespaceFlagName, opts.Namespace)
+			err = cli.SilenceError(fmt.Errorf("timeout waiting for a service for deployer %q: %w", opts.Name, k8s.ErrWaitTimeout))
+		}
+		if err != nil {
+			return err
+		}
+		c.Successf("Created ingress %q for %s\n", ingress.Name, formatIngressURL(ingress))
+	}
 	if opts.Tail {
 		// err guarded by Validate()
 		timeout, _ := time.ParseDuration(opts.WaitTimeout)
@@ -176,7 +228,7 @@ func (opts *DeployerCreateOptions) Exec(ctx context.Context, c *cli.Config) erro
 				return k8s.WaitUntilReady(ctx, c.CoreRuntime().RESTClient(), "deployers", deployer)
 			},
 			func(ctx context.Context) error {
-				return c.Kail.CoreDeployerLogs(ctx, deployer, cli.TailSinceCreateDefault, c.Stdout)
+				return c.Kail.CoreDeployerLogs(ctx, deployer, kail.LogOptions{Since: cli.TailSinceCreateDefault}, c.Stdout)
 			},
 		)
 		if err == context.DeadlineExceeded {
@@ -192,6 +244,63 @@ func (opts *DeployerCreateOptions) Exec(ctx context.Context, c *cli.Config) erro
 	return nil
 }
 
+// createIngress exposes the deployer's service outside of the cluster. The service is created
+// asynchronously for the deployer, so the ingress cannot be created until the service name is
+// reflected on the deployer's status.
+func (opts *DeployerCreateOptions) createIngress(ctx context.Context, c *cli.Config, deployer *corev1alpha1.Deployer, timeout time.Duration) (*extensionsv1beta1.Ingress, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	obj, err := k8s.WaitUntil(ctx, c.CoreRuntime().RESTClient(), "deployers", deployer, func(obj runtime.Object) (bool, error) {
+		return obj.(*corev1alpha1.Deployer).Status.ServiceName != "", nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	deployer = obj.(*corev1alpha1.Deployer)
+
+	service, err := c.Core().Services(deployer.Namespace).Get(deployer.Status.ServiceName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(service.Spec.Ports) == 0 {
+		return nil, fmt.Errorf("service %q does not expose a port", service.Name)
+	}
+
+	ingress := &extensionsv1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: deployer.Namespace,
+			Name:      deployer.Name,
+			Labels: map[string]string{
+				core.DeployerLabelKey: deployer.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(deployer, corev1alpha1.SchemeGroupVersion.WithKind("Deployer")),
+			},
+		},
+		Spec: extensionsv1beta1.IngressSpec{
+			Rules: []extensionsv1beta1.IngressRule{{
+				Host: opts.IngressHost,
+				IngressRuleValue: extensionsv1beta1.IngressRuleValue{
+					HTTP: &extensionsv1beta1.HTTPIngressRuleValue{
+						Paths: []extensionsv1beta1.HTTPIngressPath{{
+							Path: opts.IngressPath,
+							Backend: extensionsv1beta1.IngressBackend{
+								ServiceName: service.Name,
+								ServicePort: intstr.FromInt(int(service.Spec.Ports[0].Port)),
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+	return c.Extensions().Ingresses(deployer.Namespace).Create(ingress)
+}
+
+func (opts *DeployerCreateOptions) Prompt(ctx context.Context, c *cli.Config, p *cli.Prompter) error {
+	return p.OneOf(cli.ApplicationRefFlagName, cli.ContainerRefFlagName, cli.FunctionRefFlagName, cli.ImageFlagName)
+}
+
 func (opts *DeployerCreateOptions) IsDryRun() bool {
 	return opts.DryRun
 }
@@ -211,12 +320,22 @@ deployers must be updated manually to roll out new images.
 
 The runtime environment can be configured by ` + cli.EnvFlagName + ` for static key-value pairs
 and ` + cli.EnvFromFlagName + ` to map values from a ConfigMap or Secret.
+
+The deployer is only reachable within the cluster by default. To expose the
+deployer outside of the cluster, an Ingress is created for the deployer's service
+with ` + cli.IngressHostFlagName + ` and optionally ` + cli.IngressPathFlagName + `. The Ingress is owned by the
+deployer and is deleted along with it. An ingress controller must be installed
+in the cluster for the Ingress to take effect.
+
+The Ingress is only created along with the deployer, there is no command to
+update a deployer. To change the host or path, delete and recreate the deployer.
 `),
 		Example: strings.Join([]string{
 			fmt.Sprintf("%s core deployer create my-app-deployer %s my-app", c.Name, cli.ApplicationRefFlagName),
 			fmt.Sprintf("%s core deployer create my-func-deployer %s my-func", c.Name, cli.FunctionRefFlagName),
 			fmt.Sprintf("%s core deployer create my-func-deployer %s my-container", c.Name, cli.ContainerRefFlagName),
 			fmt.Sprintf("%s core deployer create my-image-deployer %s registry.example.com/my-image:latest", c.Name, cli.ImageFlagName),
+			fmt.Sprintf("%s core deployer create my-func-deployer %s my-func %s my-func.example.com", c.Name, cli.FunctionRefFlagName, cli.IngressHostFlagName),
 		}, "\n"),
 		PreRunE: cli.ValidateOptions(ctx, opts),
 		RunE:    cli.ExecOptions(ctx, c, opts),
@@ -226,6 +345,7 @@ and ` + cli.EnvFromFlagName + ` to map values from a ConfigMap or Secret.
 		cli.NameArg(&opts.Name),
 	)
 
+	cli.InteractiveFlag(ctx, cmd, c, opts)
 	cli.NamespaceFlag(cmd, c, &opts.Namespace)
 	cmd.Flags().StringVar(&opts.Image, cli.StripDash(cli.ImageFlagName), "", "container `image` to deploy")
 	cmd.Flags().StringVar(&opts.ApplicationRef, cli.StripDash(cli.ApplicationRefFlagName), "", "`name` of application to deploy")
@@ -233,9 +353,15 @@ and ` + cli.EnvFromFlagName + ` to map values from a ConfigMap or Secret.
 	cmd.Flags().StringVar(&opts.FunctionRef, cli.StripDash(cli.FunctionRefFlagName), "", "`name` of function to deploy")
 	cmd.Flags().StringArrayVar(&opts.Env, cli.StripDash(cli.EnvFlagName), []string{}, fmt.Sprintf("environment `variable` defined as a key value pair separated by an equals sign, example %q (may be set multiple times)", fmt.Sprintf("%s MY_VAR=my-value", cli.EnvFlagName)))
 	cmd.Flags().StringArrayVar(&opts.EnvFrom, cli.StripDash(cli.EnvFromFlagName), []string{}, fmt.Sprintf("environment `variable` from a config map or secret, example %q, %q (may be set multiple times)", fmt.Sprintf("%s MY_SECRET_VALUE=secretKeyRef:my-secret-name:key-in-secret", cli.EnvFromFlagName), fmt.Sprintf("%s MY_CONFIG_MAP_VALUE=configMapKeyRef:my-config-map-name:key-in-config-map", cli.EnvFromFlagName)))
+	cmd.Flags().StringVar(&opts.IngressHost, cli.StripDash(cli.IngressHostFlagName), "", "`host` name to expose the deployer on with an ingress")
+	cmd.Flags().StringVar(&opts.IngressPath, cli.StripDash(cli.IngressPathFlagName), "", "url `path` to expose the deployer on with an ingress, requires "+cli.IngressHostFlagName)
 	cmd.Flags().BoolVar(&opts.Tail, cli.StripDash(cli.TailFlagName), false, "watch deployer logs")
-	cmd.Flags().StringVar(&opts.WaitTimeout, cli.StripDash(cli.WaitTimeoutFlagName), "10m", "`duration` to wait for the deployer to become ready when watching logs")
-	cmd.Flags().BoolVar(&opts.DryRun, cli.StripDash(cli.DryRunFlagName), false, "print kubernetes resources to stdout rather than apply them to the cluster, messages normally on stdout will be sent to stderr")
+	cmd.Flags().StringVar(&opts.WaitTimeout, cli.StripDash(cli.WaitTimeoutFlagName), "10m", "`duration` to wait for the deployer to become ready when watching logs or for its service when creating an ingress")
+	cli.DryRunFlags(cmd, c, &opts.DryRun)
+
+	cli.CompleteFlagResource(cmd, cli.ApplicationRefFlagName, cli.ApplicationsCompletion)
+	cli.CompleteFlagResource(cmd, cli.ContainerRefFlagName, cli.ContainersCompletion)
+	cli.CompleteFlagResource(cmd, cli.FunctionRefFlagName, cli.FunctionsCompletion)
 
 	return cmd
 }
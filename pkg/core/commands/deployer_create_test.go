@@ -24,13 +24,18 @@ import (
 	"github.com/projectriff/cli/pkg/cli"
 	"github.com/projectriff/cli/pkg/core/commands"
 	"github.com/projectriff/cli/pkg/k8s"
+	riffkail "github.com/projectriff/cli/pkg/kail"
 	rifftesting "github.com/projectriff/cli/pkg/testing"
 	kailtesting "github.com/projectriff/cli/pkg/testing/kail"
+	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+	"github.com/projectriff/system/pkg/apis/core"
 	corev1alpha1 "github.com/projectriff/system/pkg/apis/core/v1alpha1"
 	"github.com/stretchr/testify/mock"
 	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	cachetesting "k8s.io/client-go/tools/cache/testing"
 )
 
@@ -77,6 +82,14 @@ func TestDeployerCreateOptions(t *testing.T) {
 			},
 			ShouldValidate: true,
 		},
+		{
+			Name: "from invalid image",
+			Options: &commands.DeployerCreateOptions{
+				ResourceOptions: rifftesting.ValidResourceOptions,
+				Image:           "registry.example.com//image:",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("registry.example.com//image:", cli.ImageFlagName),
+		},
 		{
 			Name: "from application, container, funcation and image",
 			Options: &commands.DeployerCreateOptions{
@@ -182,6 +195,67 @@ func TestDeployerCreateOptions(t *testing.T) {
 			},
 			ExpectFieldError: cli.ErrMultipleOneOf(cli.DryRunFlagName, cli.TailFlagName),
 		},
+		{
+			Name: "with ingress",
+			Options: &commands.DeployerCreateOptions{
+				ResourceOptions: rifftesting.ValidResourceOptions,
+				Image:           "example.com/repo:tag",
+				IngressHost:     "my-deployer.example.com",
+				IngressPath:     "/my-path",
+				WaitTimeout:     "10m",
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "with ingress, invalid host",
+			Options: &commands.DeployerCreateOptions{
+				ResourceOptions: rifftesting.ValidResourceOptions,
+				Image:           "example.com/repo:tag",
+				IngressHost:     "my_deployer.example.com",
+				WaitTimeout:     "10m",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("my_deployer.example.com", cli.IngressHostFlagName),
+		},
+		{
+			Name: "with ingress, invalid path",
+			Options: &commands.DeployerCreateOptions{
+				ResourceOptions: rifftesting.ValidResourceOptions,
+				Image:           "example.com/repo:tag",
+				IngressHost:     "my-deployer.example.com",
+				IngressPath:     "my-path",
+				WaitTimeout:     "10m",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("my-path", cli.IngressPathFlagName),
+		},
+		{
+			Name: "with ingress, path without host",
+			Options: &commands.DeployerCreateOptions{
+				ResourceOptions: rifftesting.ValidResourceOptions,
+				Image:           "example.com/repo:tag",
+				IngressPath:     "/my-path",
+			},
+			ExpectFieldError: cli.ErrMissingField(cli.IngressHostFlagName),
+		},
+		{
+			Name: "with ingress, missing timeout",
+			Options: &commands.DeployerCreateOptions{
+				ResourceOptions: rifftesting.ValidResourceOptions,
+				Image:           "example.com/repo:tag",
+				IngressHost:     "my-deployer.example.com",
+			},
+			ExpectFieldError: cli.ErrMissingField(cli.WaitTimeoutFlagName),
+		},
+		{
+			Name: "dry run, ingress",
+			Options: &commands.DeployerCreateOptions{
+				ResourceOptions: rifftesting.ValidResourceOptions,
+				Image:           "example.com/repo:tag",
+				IngressHost:     "my-deployer.example.com",
+				WaitTimeout:     "10m",
+				DryRun:          true,
+			},
+			ExpectFieldError: cli.ErrMultipleOneOf(cli.DryRunFlagName, cli.IngressHostFlagName),
+		},
 	}
 
 	table.Run(t)
@@ -202,6 +276,10 @@ func TestDeployerCreateCommand(t *testing.T) {
 	envVarOther := fmt.Sprintf("%s=%s", envNameOther, envValueOther)
 	envVarFromConfigMap := "MY_VAR_FROM_CONFIGMAP=configMapKeyRef:my-configmap:my-key"
 	envVarFromSecret := "MY_VAR_FROM_SECRET=secretKeyRef:my-secret:my-key"
+	serviceName := "my-deployer-deployer"
+	ingressHost := "my-deployer.example.com"
+	ingressPath := "/my-path"
+	isController := true
 
 	table := rifftesting.CommandTable{
 		{
@@ -289,6 +367,54 @@ Created deployer "my-deployer"
 			},
 			ExpectOutput: `
 Created deployer "my-deployer"
+`,
+		},
+		{
+			Name: "interactive function ref",
+			Args: []string{cli.InteractiveFlagName},
+			GivenObjects: []runtime.Object{
+				&buildv1alpha1.Function{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      "another-func",
+					},
+				},
+				&buildv1alpha1.Function{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      functionRef,
+					},
+				},
+			},
+			Stdin: []byte("my-deployer\n3\n2\n"),
+			ExpectCreates: []runtime.Object{
+				&corev1alpha1.Deployer{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      deployerName,
+					},
+					Spec: corev1alpha1.DeployerSpec{
+						Build: &corev1alpha1.Build{
+							FunctionRef: functionRef,
+						},
+					},
+				},
+			},
+			ExpectOutput: `
+<name>
+> Choose one of:
+  1) --application-ref  name of application to deploy
+  2) --container-ref  name of container to deploy
+  3) --function-ref  name of function to deploy
+  4) --image  container image to deploy
+> --function-ref name of function to deploy
+  1) another-func
+  2) my-func
+> 
+To run the same command without prompting:
+  create my-deployer --function-ref my-func
+
+Created deployer "my-deployer"
 `,
 		},
 		{
@@ -414,6 +540,174 @@ Created deployer "my-deployer"
 			},
 			ShouldError: true,
 		},
+		{
+			Name: "create with ingress",
+			Args: []string{deployerName, cli.ImageFlagName, image, cli.IngressHostFlagName, ingressHost, cli.IngressPathFlagName, ingressPath},
+			GivenObjects: []runtime.Object{
+				&corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      serviceName,
+					},
+					Spec: corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 80}},
+					},
+				},
+			},
+			Prepare: func(t *testing.T, ctx context.Context, c *cli.Config) (context.Context, error) {
+				lw := cachetesting.NewFakeControllerSource()
+				ctx = k8s.WithListerWatcher(ctx, lw)
+				lw.Add(&corev1alpha1.Deployer{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      deployerName,
+					},
+					Status: corev1alpha1.DeployerStatus{
+						ServiceName: serviceName,
+					},
+				})
+				return ctx, nil
+			},
+			CleanUp: func(t *testing.T, ctx context.Context, c *cli.Config) error {
+				if lw, ok := k8s.GetListerWatcher(ctx, nil, "", nil).(*cachetesting.FakeControllerSource); ok {
+					lw.Shutdown()
+				}
+				return nil
+			},
+			ExpectCreates: []runtime.Object{
+				// creates are grouped by clientset, kubernetes resources are listed first
+				&extensionsv1beta1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      deployerName,
+						Labels: map[string]string{
+							core.DeployerLabelKey: deployerName,
+						},
+						OwnerReferences: []metav1.OwnerReference{
+							{
+								APIVersion:         "core.projectriff.io/v1alpha1",
+								Kind:               "Deployer",
+								Name:               deployerName,
+								Controller:         &isController,
+								BlockOwnerDeletion: &isController,
+							},
+						},
+					},
+					Spec: extensionsv1beta1.IngressSpec{
+						Rules: []extensionsv1beta1.IngressRule{{
+							Host: ingressHost,
+							IngressRuleValue: extensionsv1beta1.IngressRuleValue{
+								HTTP: &extensionsv1beta1.HTTPIngressRuleValue{
+									Paths: []extensionsv1beta1.HTTPIngressPath{{
+										Path: ingressPath,
+										Backend: extensionsv1beta1.IngressBackend{
+											ServiceName: serviceName,
+											ServicePort: intstr.FromInt(80),
+										},
+									}},
+								},
+							},
+						}},
+					},
+				},
+				&corev1alpha1.Deployer{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      deployerName,
+					},
+					Spec: corev1alpha1.DeployerSpec{
+						Template: &corev1.PodSpec{
+							Containers: []corev1.Container{{Image: image}},
+						},
+					},
+				},
+			},
+			ExpectOutput: `
+Created deployer "my-deployer"
+Created ingress "my-deployer" for http://my-deployer.example.com/my-path
+`,
+		},
+		{
+			Name: "create with ingress, service timeout",
+			Args: []string{deployerName, cli.ImageFlagName, image, cli.IngressHostFlagName, ingressHost, cli.WaitTimeoutFlagName, "5ms"},
+			Prepare: func(t *testing.T, ctx context.Context, c *cli.Config) (context.Context, error) {
+				lw := cachetesting.NewFakeControllerSource()
+				ctx = k8s.WithListerWatcher(ctx, lw)
+				return ctx, nil
+			},
+			CleanUp: func(t *testing.T, ctx context.Context, c *cli.Config) error {
+				if lw, ok := k8s.GetListerWatcher(ctx, nil, "", nil).(*cachetesting.FakeControllerSource); ok {
+					lw.Shutdown()
+				}
+				return nil
+			},
+			ExpectCreates: []runtime.Object{
+				&corev1alpha1.Deployer{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      deployerName,
+					},
+					Spec: corev1alpha1.DeployerSpec{
+						Template: &corev1.PodSpec{
+							Containers: []corev1.Container{{Image: image}},
+						},
+					},
+				},
+			},
+			ExpectOutput: `
+Created deployer "my-deployer"
+Timeout after "5ms" waiting for a service for "my-deployer"
+Deployer "my-deployer" was created, but its service is not ready so no ingress was created
+To view status run: riff core deployer list --namespace default
+`,
+			ShouldError: true,
+			Verify: func(t *testing.T, output string, err error) {
+				if actual := err; !cli.IsSilent(err) {
+					t.Errorf("expected error to be silent, actual %#v", actual)
+				}
+				if expected, actual := cli.TimeoutErrorType, cli.ErrorTypeOf(err); expected != actual {
+					t.Errorf("expected error type %q, actual %q", expected, actual)
+				}
+			},
+		},
+		{
+			Name: "create with ingress, missing service",
+			Args: []string{deployerName, cli.ImageFlagName, image, cli.IngressHostFlagName, ingressHost},
+			Prepare: func(t *testing.T, ctx context.Context, c *cli.Config) (context.Context, error) {
+				lw := cachetesting.NewFakeControllerSource()
+				ctx = k8s.WithListerWatcher(ctx, lw)
+				lw.Add(&corev1alpha1.Deployer{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      deployerName,
+					},
+					Status: corev1alpha1.DeployerStatus{
+						ServiceName: serviceName,
+					},
+				})
+				return ctx, nil
+			},
+			CleanUp: func(t *testing.T, ctx context.Context, c *cli.Config) error {
+				if lw, ok := k8s.GetListerWatcher(ctx, nil, "", nil).(*cachetesting.FakeControllerSource); ok {
+					lw.Shutdown()
+				}
+				return nil
+			},
+			ExpectCreates: []runtime.Object{
+				&corev1alpha1.Deployer{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      deployerName,
+					},
+					Spec: corev1alpha1.DeployerSpec{
+						Template: &corev1.PodSpec{
+							Containers: []corev1.Container{{Image: image}},
+						},
+					},
+				},
+			},
+			ShouldError: true,
+		},
 		{
 			Name: "tail logs",
 			Args: []string{deployerName, cli.ImageFlagName, image, cli.TailFlagName},
@@ -433,7 +727,7 @@ Created deployer "my-deployer"
 							Containers: []corev1.Container{{Image: image}},
 						},
 					},
-				}, cli.TailSinceCreateDefault, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				}, riffkail.LogOptions{Since: cli.TailSinceCreateDefault}, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
 					fmt.Fprintf(c.Stdout, "...log output...\n")
 				})
 				return ctx, nil
@@ -484,7 +778,7 @@ Created deployer "my-deployer"
 							Containers: []corev1.Container{{Image: image}},
 						},
 					},
-				}, cli.TailSinceCreateDefault, mock.Anything).Return(k8s.ErrWaitTimeout).Run(func(args mock.Arguments) {
+				}, riffkail.LogOptions{Since: cli.TailSinceCreateDefault}, mock.Anything).Return(k8s.ErrWaitTimeout).Run(func(args mock.Arguments) {
 					ctx := args[0].(context.Context)
 					fmt.Fprintf(c.Stdout, "...log output...\n")
 					// wait for context to be cancelled
@@ -547,7 +841,7 @@ To continue watching logs run: riff core deployer tail my-deployer --namespace d
 							Containers: []corev1.Container{{Image: image}},
 						},
 					},
-				}, cli.TailSinceCreateDefault, mock.Anything).Return(fmt.Errorf("kail error"))
+				}, riffkail.LogOptions{Since: cli.TailSinceCreateDefault}, mock.Anything).Return(fmt.Errorf("kail error"))
 				return ctx, nil
 			},
 			CleanUp: func(t *testing.T, ctx context.Context, c *cli.Config) error {
@@ -19,13 +19,17 @@ package commands
 import (
 	"context"
 	"fmt"
+	"path"
 	"strings"
 	"time"
 
 	"github.com/projectriff/cli/pkg/cli"
 	"github.com/projectriff/cli/pkg/cli/printers"
+	"github.com/projectriff/system/pkg/apis/core"
 	corev1alpha1 "github.com/projectriff/system/pkg/apis/core/v1alpha1"
 	"github.com/spf13/cobra"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -33,6 +37,9 @@ import (
 
 type DeployerListOptions struct {
 	cli.ListOptions
+
+	// ingresses indexed by namespace and deployer name
+	ingresses map[string]*extensionsv1beta1.Ingress
 }
 
 var (
@@ -59,6 +66,22 @@ func (opts *DeployerListOptions) Exec(ctx context.Context, c *cli.Config) error
 		return nil
 	}
 
+	ingresses, err := c.Extensions().Ingresses(opts.Namespace).List(metav1.ListOptions{
+		LabelSelector: core.DeployerLabelKey,
+	})
+	if err != nil {
+		if !apierrs.IsForbidden(err) && !apierrs.IsNotFound(err) {
+			return err
+		}
+		// ingresses are not required to list deployers
+		ingresses = &extensionsv1beta1.IngressList{}
+	}
+	opts.ingresses = map[string]*extensionsv1beta1.Ingress{}
+	for i := range ingresses.Items {
+		ingress := &ingresses.Items[i]
+		opts.ingresses[path.Join(ingress.Namespace, ingress.Labels[core.DeployerLabelKey])] = ingress
+	}
+
 	tablePrinter := printers.NewTablePrinter(printers.PrintOptions{
 		WithNamespace: opts.AllNamespaces,
 	}).With(func(h printers.PrintHandler) {
@@ -82,6 +105,8 @@ func NewDeployerListCommand(ctx context.Context, c *cli.Config) *cobra.Command {
 		Long: strings.TrimSpace(`
 List deployers in a namespace or across all namespaces.
 
+Deployers exposed with an ingress show the URL the deployer is reachable at.
+
 For detail regarding the status of a single deployer, run:
 
     ` + c.Name + ` core deployer status <deployer-name>
@@ -122,6 +147,7 @@ func (opts *DeployerListOptions) print(deployer *corev1alpha1.Deployer, _ printe
 		refType,
 		refValue,
 		cli.FormatEmptyString(deployer.Status.ServiceName),
+		cli.FormatEmptyString(opts.formatIngress(deployer)),
 		cli.FormatConditionStatus(deployer.Status.GetCondition(corev1alpha1.DeployerConditionReady)),
 		cli.FormatTimestampSince(deployer.CreationTimestamp, now),
 	)
@@ -134,6 +160,7 @@ func (opts *DeployerListOptions) printColumns() []metav1beta1.TableColumnDefinit
 		{Name: "Type", Type: "string"},
 		{Name: "Ref", Type: "string"},
 		{Name: "Service", Type: "string"},
+		{Name: "Ingress", Type: "string"},
 		{Name: "Status", Type: "string"},
 		{Name: "Age", Type: "string"},
 	}
@@ -155,3 +182,32 @@ func (opts *DeployerListOptions) formatRef(deployer *corev1alpha1.Deployer) (str
 	}
 	return cli.Swarnf("<unknown>"), cli.Swarnf("<unknown>")
 }
+
+func (opts *DeployerListOptions) formatIngress(deployer *corev1alpha1.Deployer) string {
+	ingress, ok := opts.ingresses[path.Join(deployer.Namespace, deployer.Name)]
+	if !ok {
+		return ""
+	}
+	return formatIngressURL(ingress)
+}
+
+// formatIngressURL returns the url for the first http path of the first host rule
+func formatIngressURL(ingress *extensionsv1beta1.Ingress) string {
+	for _, rule := range ingress.Spec.Rules {
+		if rule.Host == "" || rule.HTTP == nil {
+			continue
+		}
+		scheme := "http"
+		for _, tls := range ingress.Spec.TLS {
+			for _, host := range tls.Hosts {
+				if host == rule.Host {
+					scheme = "https"
+				}
+			}
+		}
+		for _, p := range rule.HTTP.Paths {
+			return fmt.Sprintf("%s://%s%s", scheme, rule.Host, p.Path)
+		}
+	}
+	return ""
+}
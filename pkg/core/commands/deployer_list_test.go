@@ -18,16 +18,22 @@ package commands_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	duckv1beta1 "github.com/knative/pkg/apis/duck/v1beta1"
 	"github.com/projectriff/cli/pkg/cli"
 	"github.com/projectriff/cli/pkg/core/commands"
 	rifftesting "github.com/projectriff/cli/pkg/testing"
+	"github.com/projectriff/system/pkg/apis/core"
 	corev1alpha1 "github.com/projectriff/system/pkg/apis/core/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgotesting "k8s.io/client-go/testing"
 )
 
 func TestDeployerListOptions(t *testing.T) {
@@ -87,8 +93,8 @@ No deployers found.
 				},
 			},
 			ExpectOutput: `
-NAME            TYPE        REF         SERVICE   STATUS      AGE
-test-deployer   <unknown>   <unknown>   <empty>   <unknown>   <unknown>
+NAME            TYPE        REF         SERVICE   INGRESS   STATUS      AGE
+test-deployer   <unknown>   <unknown>   <empty>   <empty>   <unknown>   <unknown>
 `,
 		},
 		{
@@ -124,9 +130,9 @@ No deployers found.
 				},
 			},
 			ExpectOutput: `
-NAMESPACE         NAME                  TYPE        REF         SERVICE   STATUS      AGE
-default           test-deployer         <unknown>   <unknown>   <empty>   <unknown>   <unknown>
-other-namespace   test-other-deployer   <unknown>   <unknown>   <empty>   <unknown>   <unknown>
+NAMESPACE         NAME                  TYPE        REF         SERVICE   INGRESS   STATUS      AGE
+default           test-deployer         <unknown>   <unknown>   <empty>   <empty>   <unknown>   <unknown>
+other-namespace   test-other-deployer   <unknown>   <unknown>   <empty>   <empty>   <unknown>   <unknown>
 `,
 		},
 		{
@@ -209,13 +215,82 @@ other-namespace   test-other-deployer   <unknown>   <unknown>   <empty>   <unkno
 						ServiceName:    "container-deployer",
 					},
 				},
+				&extensionsv1beta1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "func",
+						Namespace: defaultNamespace,
+						Labels:    map[string]string{core.DeployerLabelKey: "func"},
+					},
+					Spec: extensionsv1beta1.IngressSpec{
+						Rules: []extensionsv1beta1.IngressRule{{
+							Host: "square.example.com",
+							IngressRuleValue: extensionsv1beta1.IngressRuleValue{
+								HTTP: &extensionsv1beta1.HTTPIngressRuleValue{
+									Paths: []extensionsv1beta1.HTTPIngressPath{{
+										Path: "/square",
+										Backend: extensionsv1beta1.IngressBackend{
+											ServiceName: "func-deployer",
+											ServicePort: intstr.FromInt(80),
+										},
+									}},
+								},
+							},
+						}},
+					},
+				},
+				&extensionsv1beta1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "container",
+						Namespace: defaultNamespace,
+						Labels:    map[string]string{core.DeployerLabelKey: "container"},
+					},
+					Spec: extensionsv1beta1.IngressSpec{
+						TLS: []extensionsv1beta1.IngressTLS{{
+							Hosts: []string{"container.example.com"},
+						}},
+						Rules: []extensionsv1beta1.IngressRule{{
+							Host: "container.example.com",
+							IngressRuleValue: extensionsv1beta1.IngressRuleValue{
+								HTTP: &extensionsv1beta1.HTTPIngressRuleValue{
+									Paths: []extensionsv1beta1.HTTPIngressPath{{
+										Backend: extensionsv1beta1.IngressBackend{
+											ServiceName: "container-deployer",
+											ServicePort: intstr.FromInt(80),
+										},
+									}},
+								},
+							},
+						}},
+					},
+				},
+				&extensionsv1beta1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "not-a-deployer",
+						Namespace: defaultNamespace,
+					},
+					Spec: extensionsv1beta1.IngressSpec{
+						Rules: []extensionsv1beta1.IngressRule{{
+							Host: "app.example.com",
+							IngressRuleValue: extensionsv1beta1.IngressRuleValue{
+								HTTP: &extensionsv1beta1.HTTPIngressRuleValue{
+									Paths: []extensionsv1beta1.HTTPIngressPath{{
+										Backend: extensionsv1beta1.IngressBackend{
+											ServiceName: "app-deployer",
+											ServicePort: intstr.FromInt(80),
+										},
+									}},
+								},
+							},
+						}},
+					},
+				},
 			},
 			ExpectOutput: `
-NAME        TYPE          REF                 SERVICE              STATUS   AGE
-app         application   petclinic           app-deployer         Ready    <unknown>
-container   container     busybox             container-deployer   Ready    <unknown>
-func        function      square              func-deployer        Ready    <unknown>
-img         image         projectriff/upper   img-deployer         Ready    <unknown>
+NAME        TYPE          REF                 SERVICE              INGRESS                            STATUS   AGE
+app         application   petclinic           app-deployer         <empty>                            Ready    <unknown>
+container   container     busybox             container-deployer   https://container.example.com      Ready    <unknown>
+func        function      square              func-deployer        http://square.example.com/square   Ready    <unknown>
+img         image         projectriff/upper   img-deployer         <empty>                            Ready    <unknown>
 `,
 		},
 		{
@@ -226,7 +301,70 @@ img         image         projectriff/upper   img-deployer         Ready    <unk
 			},
 			ShouldError: true,
 		},
+		{
+			Name: "list ingresses error",
+			Args: []string{},
+			GivenObjects: []runtime.Object{
+				&corev1alpha1.Deployer{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      deployerName,
+						Namespace: defaultNamespace,
+					},
+				},
+			},
+			WithReactors: []rifftesting.ReactionFunc{
+				rifftesting.InduceFailure("list", "ingresses"),
+			},
+			ShouldError: true,
+		},
+		{
+			Name: "list ingresses forbidden",
+			Args: []string{},
+			GivenObjects: []runtime.Object{
+				&corev1alpha1.Deployer{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      deployerName,
+						Namespace: defaultNamespace,
+					},
+				},
+			},
+			WithReactors: []rifftesting.ReactionFunc{
+				failIngressList(apierrs.NewForbidden(extensionsv1beta1.Resource("ingresses"), "", fmt.Errorf("no access"))),
+			},
+			ExpectOutput: `
+NAME            TYPE        REF         SERVICE   INGRESS   STATUS      AGE
+test-deployer   <unknown>   <unknown>   <empty>   <empty>   <unknown>   <unknown>
+`,
+		},
+		{
+			Name: "list ingresses not found",
+			Args: []string{},
+			GivenObjects: []runtime.Object{
+				&corev1alpha1.Deployer{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      deployerName,
+						Namespace: defaultNamespace,
+					},
+				},
+			},
+			WithReactors: []rifftesting.ReactionFunc{
+				failIngressList(apierrs.NewNotFound(extensionsv1beta1.Resource("ingresses"), "")),
+			},
+			ExpectOutput: `
+NAME            TYPE        REF         SERVICE   INGRESS   STATUS      AGE
+test-deployer   <unknown>   <unknown>   <empty>   <empty>   <unknown>   <unknown>
+`,
+		},
 	}
 
 	table.Run(t, commands.NewDeployerListCommand)
 }
+
+func failIngressList(err error) rifftesting.ReactionFunc {
+	return func(action clientgotesting.Action) (bool, runtime.Object, error) {
+		if !action.Matches("list", "ingresses") {
+			return false, nil, nil
+		}
+		return true, nil, err
+	}
+}
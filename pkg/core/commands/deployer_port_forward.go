@@ -0,0 +1,179 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/projectriff/system/pkg/apis/core"
+	corev1alpha1 "github.com/projectriff/system/pkg/apis/core/v1alpha1"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const LocalPortArgumentName = "local-port"
+
+type DeployerPortForwardOptions struct {
+	cli.ResourceOptions
+
+	LocalPort string
+}
+
+var (
+	_ cli.Validatable = (*DeployerPortForwardOptions)(nil)
+	_ cli.Executable  = (*DeployerPortForwardOptions)(nil)
+)
+
+func (opts *DeployerPortForwardOptions) Validate(ctx context.Context) *cli.FieldError {
+	errs := cli.EmptyFieldError
+
+	errs = errs.Also(opts.ResourceOptions.Validate(ctx))
+
+	if opts.LocalPort != "" {
+		if port, err := strconv.Atoi(opts.LocalPort); err != nil || port < 1 || port > 65535 {
+			errs = errs.Also(cli.ErrInvalidValue(opts.LocalPort, LocalPortArgumentName))
+		}
+	}
+
+	return errs
+}
+
+func (opts *DeployerPortForwardOptions) Exec(ctx context.Context, c *cli.Config) error {
+	deployer, err := c.CoreRuntime().Deployers(opts.Namespace).Get(opts.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	pod, err := opts.findPod(c, deployer)
+	if err != nil {
+		return err
+	}
+	remotePort, err := opts.findRemotePort(c, deployer, pod)
+	if err != nil {
+		return err
+	}
+	localPort := remotePort
+	if opts.LocalPort != "" {
+		// error is protected by Validate()
+		port, _ := strconv.Atoi(opts.LocalPort)
+		localPort = int32(port)
+	}
+
+	c.Infof("Forwarding local port %d to port %d of deployer %q through pod %q\n", localPort, remotePort, deployer.Name, pod.Name)
+	return c.PortForward.PortForward(ctx, pod, localPort, remotePort, c.Stdout, c.Stderr)
+}
+
+// findPod resolves a running pod for the deployer
+func (opts *DeployerPortForwardOptions) findPod(c *cli.Config, deployer *corev1alpha1.Deployer) (*corev1.Pod, error) {
+	pods, err := c.Core().Pods(deployer.Namespace).List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", core.DeployerLabelKey, deployer.Name),
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].Name < pods.Items[j].Name
+	})
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil {
+			return pod, nil
+		}
+	}
+	return nil, fmt.Errorf("no running pods found for deployer %q", deployer.Name)
+}
+
+// findRemotePort resolves the container port traffic to the deployer's service is sent to. If
+// the service is not yet available, the first port exposed by the pod is used.
+func (opts *DeployerPortForwardOptions) findRemotePort(c *cli.Config, deployer *corev1alpha1.Deployer, pod *corev1.Pod) (int32, error) {
+	if deployer.Status.ServiceName != "" {
+		service, err := c.Core().Services(deployer.Namespace).Get(deployer.Status.ServiceName, metav1.GetOptions{})
+		if err != nil {
+			return 0, err
+		}
+		if len(service.Spec.Ports) != 0 {
+			targetPort := service.Spec.Ports[0].TargetPort
+			if targetPort.Type == intstr.Int && targetPort.IntVal != 0 {
+				return targetPort.IntVal, nil
+			}
+			if targetPort.Type == intstr.String && targetPort.StrVal != "" {
+				for _, container := range pod.Spec.Containers {
+					for _, port := range container.Ports {
+						if port.Name == targetPort.StrVal {
+							return port.ContainerPort, nil
+						}
+					}
+				}
+				return 0, fmt.Errorf("port %q not found in pod %q", targetPort.StrVal, pod.Name)
+			}
+			return service.Spec.Ports[0].Port, nil
+		}
+	}
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			return port.ContainerPort, nil
+		}
+	}
+	return 0, fmt.Errorf("no ports found for deployer %q", deployer.Name)
+}
+
+func NewDeployerPortForwardCommand(ctx context.Context, c *cli.Config) *cobra.Command {
+	opts := &DeployerPortForwardOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "port-forward",
+		Short: "forward a local port to a deployer",
+		Long: strings.TrimSpace(`
+Forward connections on a local port to a running pod of the deployer until
+canceled. To cancel, press Ctl-c in the shell or kill the process.
+
+The remote port is resolved from the deployer's service, or from the ports
+exposed by the pod if the service is not yet available. The local port defaults
+to the remote port.
+`),
+		Example: strings.Join([]string{
+			fmt.Sprintf("%s core deployer port-forward my-deployer", c.Name),
+			fmt.Sprintf("%s core deployer port-forward my-deployer 9000", c.Name),
+		}, "\n"),
+		PreRunE: cli.ValidateOptions(ctx, opts),
+		RunE:    cli.ExecOptions(ctx, c, opts),
+	}
+
+	cli.Args(cmd,
+		cli.NameArg(&opts.Name),
+		cli.Arg{
+			Name:     LocalPortArgumentName,
+			Arity:    1,
+			Optional: true,
+			Set: func(cmd *cobra.Command, args []string, offset int) error {
+				opts.LocalPort = args[offset]
+				return nil
+			},
+		},
+	)
+
+	cli.NamespaceFlag(cmd, c, &opts.Namespace)
+
+	return cmd
+}
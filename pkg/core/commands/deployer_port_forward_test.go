@@ -0,0 +1,294 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/projectriff/cli/pkg/core/commands"
+	rifftesting "github.com/projectriff/cli/pkg/testing"
+	portforwardtesting "github.com/projectriff/cli/pkg/testing/portforward"
+	"github.com/projectriff/system/pkg/apis/core"
+	corev1alpha1 "github.com/projectriff/system/pkg/apis/core/v1alpha1"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestDeployerPortForwardOptions(t *testing.T) {
+	table := rifftesting.OptionsTable{
+		{
+			Name: "invalid resource",
+			Options: &commands.DeployerPortForwardOptions{
+				ResourceOptions: rifftesting.InvalidResourceOptions,
+			},
+			ExpectFieldError: rifftesting.InvalidResourceOptionsFieldError,
+		},
+		{
+			Name: "valid resource",
+			Options: &commands.DeployerPortForwardOptions{
+				ResourceOptions: rifftesting.ValidResourceOptions,
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "local port",
+			Options: &commands.DeployerPortForwardOptions{
+				ResourceOptions: rifftesting.ValidResourceOptions,
+				LocalPort:       "9000",
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "invalid local port",
+			Options: &commands.DeployerPortForwardOptions{
+				ResourceOptions: rifftesting.ValidResourceOptions,
+				LocalPort:       "http",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("http", commands.LocalPortArgumentName),
+		},
+		{
+			Name: "out of range local port",
+			Options: &commands.DeployerPortForwardOptions{
+				ResourceOptions: rifftesting.ValidResourceOptions,
+				LocalPort:       "65536",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("65536", commands.LocalPortArgumentName),
+		},
+	}
+
+	table.Run(t)
+}
+
+func TestDeployerPortForwardCommand(t *testing.T) {
+	defaultNamespace := "default"
+	deployerName := "my-deployer"
+	serviceName := "my-deployer-deployer"
+	deployer := &corev1alpha1.Deployer{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: defaultNamespace,
+			Name:      deployerName,
+		},
+		Status: corev1alpha1.DeployerStatus{
+			ServiceName: serviceName,
+		},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: defaultNamespace,
+			Name:      serviceName,
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Port: 80, TargetPort: intstr.FromInt(8080)},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: defaultNamespace,
+			Name:      "my-deployer-pod",
+			Labels: map[string]string{
+				core.DeployerLabelKey: deployerName,
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Ports: []corev1.ContainerPort{
+						{Name: "http", ContainerPort: 8888},
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+		},
+	}
+	pendingPod := pod.DeepCopy()
+	pendingPod.Name = "my-deployer-a-pending-pod"
+	pendingPod.Status.Phase = corev1.PodPending
+
+	table := rifftesting.CommandTable{
+		{
+			Name:        "invalid args",
+			Args:        []string{},
+			ShouldError: true,
+		},
+		{
+			Name: "forward service port",
+			Args: []string{deployerName},
+			Prepare: func(t *testing.T, ctx context.Context, c *cli.Config) (context.Context, error) {
+				forwarder := &portforwardtesting.Forwarder{}
+				c.PortForward = forwarder
+				forwarder.On("PortForward", mock.Anything, pod, int32(8080), int32(8080), mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+					fmt.Fprintf(c.Stdout, "...forwarding...\n")
+				})
+				return ctx, nil
+			},
+			CleanUp: func(t *testing.T, ctx context.Context, c *cli.Config) error {
+				forwarder := c.PortForward.(*portforwardtesting.Forwarder)
+				forwarder.AssertExpectations(t)
+				return nil
+			},
+			GivenObjects: []runtime.Object{
+				deployer,
+				service,
+				pendingPod,
+				pod,
+			},
+			ExpectOutput: `
+Forwarding local port 8080 to port 8080 of deployer "my-deployer" through pod "my-deployer-pod"
+...forwarding...
+`,
+		},
+		{
+			Name: "forward to local port",
+			Args: []string{deployerName, "9000"},
+			Prepare: func(t *testing.T, ctx context.Context, c *cli.Config) (context.Context, error) {
+				forwarder := &portforwardtesting.Forwarder{}
+				c.PortForward = forwarder
+				forwarder.On("PortForward", mock.Anything, pod, int32(9000), int32(8080), mock.Anything, mock.Anything).Return(nil)
+				return ctx, nil
+			},
+			CleanUp: func(t *testing.T, ctx context.Context, c *cli.Config) error {
+				forwarder := c.PortForward.(*portforwardtesting.Forwarder)
+				forwarder.AssertExpectations(t)
+				return nil
+			},
+			GivenObjects: []runtime.Object{
+				deployer,
+				service,
+				pod,
+			},
+			ExpectOutput: `
+Forwarding local port 9000 to port 8080 of deployer "my-deployer" through pod "my-deployer-pod"
+`,
+		},
+		{
+			Name: "forward named service port",
+			Args: []string{deployerName},
+			Prepare: func(t *testing.T, ctx context.Context, c *cli.Config) (context.Context, error) {
+				forwarder := &portforwardtesting.Forwarder{}
+				c.PortForward = forwarder
+				forwarder.On("PortForward", mock.Anything, pod, int32(8888), int32(8888), mock.Anything, mock.Anything).Return(nil)
+				return ctx, nil
+			},
+			CleanUp: func(t *testing.T, ctx context.Context, c *cli.Config) error {
+				forwarder := c.PortForward.(*portforwardtesting.Forwarder)
+				forwarder.AssertExpectations(t)
+				return nil
+			},
+			GivenObjects: []runtime.Object{
+				deployer,
+				&corev1.Service{
+					ObjectMeta: service.ObjectMeta,
+					Spec: corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{
+							{Port: 80, TargetPort: intstr.FromString("http")},
+						},
+					},
+				},
+				pod,
+			},
+			ExpectOutput: `
+Forwarding local port 8888 to port 8888 of deployer "my-deployer" through pod "my-deployer-pod"
+`,
+		},
+		{
+			Name: "forward pod port without service",
+			Args: []string{deployerName},
+			Prepare: func(t *testing.T, ctx context.Context, c *cli.Config) (context.Context, error) {
+				forwarder := &portforwardtesting.Forwarder{}
+				c.PortForward = forwarder
+				forwarder.On("PortForward", mock.Anything, pod, int32(8888), int32(8888), mock.Anything, mock.Anything).Return(nil)
+				return ctx, nil
+			},
+			CleanUp: func(t *testing.T, ctx context.Context, c *cli.Config) error {
+				forwarder := c.PortForward.(*portforwardtesting.Forwarder)
+				forwarder.AssertExpectations(t)
+				return nil
+			},
+			GivenObjects: []runtime.Object{
+				&corev1alpha1.Deployer{
+					ObjectMeta: deployer.ObjectMeta,
+				},
+				pod,
+			},
+			ExpectOutput: `
+Forwarding local port 8888 to port 8888 of deployer "my-deployer" through pod "my-deployer-pod"
+`,
+		},
+		{
+			Name:        "unknown deployer",
+			Args:        []string{deployerName},
+			ShouldError: true,
+		},
+		{
+			Name: "no running pods",
+			Args: []string{deployerName},
+			GivenObjects: []runtime.Object{
+				deployer,
+				service,
+				pendingPod,
+			},
+			ShouldError: true,
+			Verify: func(t *testing.T, output string, err error) {
+				if expected, actual := `no running pods found for deployer "my-deployer"`, err.Error(); expected != actual {
+					t.Errorf("expected error %q, actually %q", expected, actual)
+				}
+			},
+		},
+		{
+			Name: "missing service",
+			Args: []string{deployerName},
+			GivenObjects: []runtime.Object{
+				deployer,
+				pod,
+			},
+			ShouldError: true,
+		},
+		{
+			Name: "port forward error",
+			Args: []string{deployerName},
+			Prepare: func(t *testing.T, ctx context.Context, c *cli.Config) (context.Context, error) {
+				forwarder := &portforwardtesting.Forwarder{}
+				c.PortForward = forwarder
+				forwarder.On("PortForward", mock.Anything, pod, int32(8080), int32(8080), mock.Anything, mock.Anything).Return(fmt.Errorf("port forward error"))
+				return ctx, nil
+			},
+			CleanUp: func(t *testing.T, ctx context.Context, c *cli.Config) error {
+				forwarder := c.PortForward.(*portforwardtesting.Forwarder)
+				forwarder.AssertExpectations(t)
+				return nil
+			},
+			GivenObjects: []runtime.Object{
+				deployer,
+				service,
+				pod,
+			},
+			ShouldError: true,
+		},
+	}
+
+	table.Run(t, commands.NewDeployerPortForwardCommand)
+}
@@ -17,6 +17,8 @@
 package k8s
 
 import (
+	"net/http"
+
 	projectriffclientset "github.com/projectriff/system/pkg/client/clientset/versioned"
 	buildv1alpha1 "github.com/projectriff/system/pkg/client/clientset/versioned/typed/build/v1alpha1"
 	corev1alpha1 "github.com/projectriff/system/pkg/client/clientset/versioned/typed/core/v1alpha1"
@@ -24,19 +26,26 @@ import (
 	streamv1alpha1 "github.com/projectriff/system/pkg/client/clientset/versioned/typed/streaming/v1alpha1"
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
+	appsv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
 	authv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	extensionsv1beta1 "k8s.io/client-go/kubernetes/typed/extensions/v1beta1"
+	rbacv1 "k8s.io/client-go/kubernetes/typed/rbac/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
-	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
 type Client interface {
 	DefaultNamespace() string
+	LoadDefaultNamespace() (string, error)
 	KubeRestConfig() *rest.Config
 	Core() corev1.CoreV1Interface
+	Apps() appsv1.AppsV1Interface
 	Auth() authv1client.AuthorizationV1Interface
+	Extensions() extensionsv1beta1.ExtensionsV1beta1Interface
+	Rbac() rbacv1.RbacV1Interface
 	APIExtension() apiextensionsv1beta1.ApiextensionsV1beta1Interface
 	Build() buildv1alpha1.BuildV1alpha1Interface
 	CoreRuntime() corev1alpha1.CoreV1alpha1Interface
@@ -48,6 +57,12 @@ func (c *client) DefaultNamespace() string {
 	return c.lazyLoadDefaultNamespaceOrDie()
 }
 
+// LoadDefaultNamespace resolves the default namespace like DefaultNamespace,
+// returning an error for an invalid kube config rather than panicking.
+func (c *client) LoadDefaultNamespace() (string, error) {
+	return c.lazyLoadDefaultNamespace()
+}
+
 func (c *client) KubeRestConfig() *rest.Config {
 	return c.lazyLoadRestConfigOrDie()
 }
@@ -56,10 +71,22 @@ func (c *client) Core() corev1.CoreV1Interface {
 	return c.lazyLoadKubernetesClientsetOrDie().CoreV1()
 }
 
+func (c *client) Apps() appsv1.AppsV1Interface {
+	return c.lazyLoadKubernetesClientsetOrDie().AppsV1()
+}
+
 func (c *client) Auth() authv1client.AuthorizationV1Interface {
 	return c.lazyLoadKubernetesClientsetOrDie().AuthorizationV1()
 }
 
+func (c *client) Extensions() extensionsv1beta1.ExtensionsV1beta1Interface {
+	return c.lazyLoadKubernetesClientsetOrDie().ExtensionsV1beta1()
+}
+
+func (c *client) Rbac() rbacv1.RbacV1Interface {
+	return c.lazyLoadKubernetesClientsetOrDie().RbacV1()
+}
+
 func (c *client) APIExtension() apiextensionsv1beta1.ApiextensionsV1beta1Interface {
 	return c.lazyLoadAPIExtensionsClientsetOrDie().ApiextensionsV1beta1()
 }
@@ -80,13 +107,40 @@ func (c *client) KnativeRuntime() knativev1alpha1.KnativeV1alpha1Interface {
 	return c.lazyLoadRiffClientsetOrDie().KnativeV1alpha1()
 }
 
-func NewClient(kubeConfigFile string) Client {
-	return &client{kubeConfigFile: kubeConfigFile}
+// ClientOptions customize how requests are made to the cluster.
+type ClientOptions struct {
+	// QPS is the maximum queries per second to the cluster, the client-go
+	// default is used when zero
+	QPS float32
+	// Burst is the maximum burst of queries above the QPS, the client-go
+	// default is used when zero
+	Burst int
+	// WrapTransport wraps the transport of each request attempt, see
+	// TraceTransport
+	WrapTransport func(http.RoundTripper) http.RoundTripper
+	// RetryBackoff is the backoff for retrying requests that fail with a
+	// transient error, DefaultRetryBackoff is used when nil
+	RetryBackoff *wait.Backoff
+}
+
+// NewClient creates a client for the cluster described by the kubeconfig file.
+// The overrides select the context, cluster and user to use from the file,
+// similar to kubectl's flags of the same name.
+func NewClient(kubeConfigFile string, overrides *clientcmd.ConfigOverrides, options *ClientOptions) Client {
+	if overrides == nil {
+		overrides = &clientcmd.ConfigOverrides{}
+	}
+	if options == nil {
+		options = &ClientOptions{}
+	}
+	return &client{kubeConfigFile: kubeConfigFile, overrides: overrides, options: options}
 }
 
 type client struct {
 	defaultNamespace       string
 	kubeConfigFile         string
+	overrides              *clientcmd.ConfigOverrides
+	options                *ClientOptions
 	kubeConfig             clientcmd.ClientConfig
 	restConfig             *rest.Config
 	kubeClientset          *kubernetes.Clientset
@@ -98,7 +152,7 @@ func (c *client) lazyLoadKubeConfig() clientcmd.ClientConfig {
 	if c.kubeConfig == nil {
 		c.kubeConfig = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
 			&clientcmd.ClientConfigLoadingRules{ExplicitPath: c.kubeConfigFile},
-			&clientcmd.ConfigOverrides{ClusterInfo: clientcmdapi.Cluster{Server: ""}},
+			c.overrides,
 		)
 	}
 	return c.kubeConfig
@@ -111,6 +165,27 @@ func (c *client) lazyLoadRestConfigOrDie() *rest.Config {
 		if err != nil {
 			panic(err)
 		}
+		if c.options.QPS != 0 {
+			restConfig.QPS = c.options.QPS
+		}
+		if c.options.Burst != 0 {
+			restConfig.Burst = c.options.Burst
+		}
+		backoff := DefaultRetryBackoff
+		if c.options.RetryBackoff != nil {
+			backoff = *c.options.RetryBackoff
+		}
+		// each attempt is traced, so retries are the outermost wrapper
+		retryTransport := RetryTransport(backoff)
+		wrappers := []func(http.RoundTripper) http.RoundTripper{restConfig.WrapTransport, c.options.WrapTransport}
+		restConfig.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+			for _, wrapper := range wrappers {
+				if wrapper != nil {
+					rt = wrapper(rt)
+				}
+			}
+			return retryTransport(rt)
+		}
 		c.restConfig = restConfig
 	}
 	return c.restConfig
@@ -141,14 +216,22 @@ func (c *client) lazyLoadRiffClientsetOrDie() *projectriffclientset.Clientset {
 	return c.riffClientset
 }
 
-func (c *client) lazyLoadDefaultNamespaceOrDie() string {
+func (c *client) lazyLoadDefaultNamespace() (string, error) {
 	if c.defaultNamespace == "" {
 		kubeConfig := c.lazyLoadKubeConfig()
 		namespace, _, err := kubeConfig.Namespace()
 		if err != nil {
-			panic(err)
+			return "", err
 		}
 		c.defaultNamespace = namespace
 	}
-	return c.defaultNamespace
+	return c.defaultNamespace, nil
+}
+
+func (c *client) lazyLoadDefaultNamespaceOrDie() string {
+	namespace, err := c.lazyLoadDefaultNamespace()
+	if err != nil {
+		panic(err)
+	}
+	return namespace
 }
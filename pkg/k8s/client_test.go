@@ -17,13 +17,18 @@
 package k8s_test
 
 import (
+	"net/http"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/projectriff/cli/pkg/k8s"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 func TestNewClient(t *testing.T) {
-	client := k8s.NewClient("testdata/.kube/config")
+	client := k8s.NewClient("testdata/.kube/config", nil, nil)
 
 	if expected, actual := "my-namespace", client.DefaultNamespace(); expected != actual {
 		t.Errorf("Expected namespace to be %q, actually %q", expected, actual)
@@ -53,3 +58,97 @@ func TestNewClient(t *testing.T) {
 		t.Errorf("Expected KnativeRuntime client to not be nil")
 	}
 }
+
+func TestNewClient_InvalidKubeConfig(t *testing.T) {
+	client := k8s.NewClient("testdata/.kube/missing", nil, nil)
+
+	if _, err := client.LoadDefaultNamespace(); err == nil {
+		t.Errorf("Expected error loading the default namespace")
+	}
+}
+
+func TestNewClient_Overrides(t *testing.T) {
+	client := k8s.NewClient("testdata/.kube/config", &clientcmd.ConfigOverrides{
+		CurrentContext: "other-context",
+		Timeout:        "30s",
+	}, nil)
+
+	if expected, actual := "other-namespace", client.DefaultNamespace(); expected != actual {
+		t.Errorf("Expected namespace to be %q, actually %q", expected, actual)
+	}
+	restConfig := client.KubeRestConfig()
+	if expected, actual := "https://192.168.1.2:8443", restConfig.Host; expected != actual {
+		t.Errorf("Expected host to be %q, actually %q", expected, actual)
+	}
+	if expected, actual := 30*time.Second, restConfig.Timeout; expected != actual {
+		t.Errorf("Expected timeout to be %s, actually %s", expected, actual)
+	}
+}
+
+func TestNewClient_ClusterAndUserOverrides(t *testing.T) {
+	overrides := &clientcmd.ConfigOverrides{}
+	overrides.Context.Cluster = "other-cluster"
+	overrides.Context.AuthInfo = "other-user"
+	client := k8s.NewClient("testdata/.kube/config", overrides, nil)
+
+	if expected, actual := "my-namespace", client.DefaultNamespace(); expected != actual {
+		t.Errorf("Expected namespace to be %q, actually %q", expected, actual)
+	}
+	restConfig := client.KubeRestConfig()
+	if expected, actual := "https://192.168.1.2:8443", restConfig.Host; expected != actual {
+		t.Errorf("Expected host to be %q, actually %q", expected, actual)
+	}
+	if expected, actual := "other-token", restConfig.BearerToken; expected != actual {
+		t.Errorf("Expected bearer token to be %q, actually %q", expected, actual)
+	}
+}
+
+func TestNewClient_ImpersonationOverrides(t *testing.T) {
+	overrides := &clientcmd.ConfigOverrides{}
+	overrides.AuthInfo.Impersonate = "jane"
+	overrides.AuthInfo.ImpersonateGroups = []string{"developers", "reviewers"}
+	client := k8s.NewClient("testdata/.kube/config", overrides, nil)
+
+	expected := rest.ImpersonationConfig{
+		UserName: "jane",
+		Groups:   []string{"developers", "reviewers"},
+		Extra:    map[string][]string{},
+	}
+	if diff := cmp.Diff(expected, client.KubeRestConfig().Impersonate); diff != "" {
+		t.Errorf("Unexpected impersonation (-expected, +actual): %s", diff)
+	}
+}
+
+func TestNewClient_WrapTransport(t *testing.T) {
+	wrapped := false
+	client := k8s.NewClient("testdata/.kube/config", nil, &k8s.ClientOptions{
+		WrapTransport: func(rt http.RoundTripper) http.RoundTripper {
+			wrapped = true
+			return rt
+		},
+	})
+
+	restConfig := client.KubeRestConfig()
+	if restConfig.WrapTransport == nil {
+		t.Fatalf("Expected rest config to wrap the transport")
+	}
+	restConfig.WrapTransport(http.DefaultTransport)
+	if !wrapped {
+		t.Errorf("Expected transport to be wrapped")
+	}
+}
+
+func TestNewClient_QPS(t *testing.T) {
+	client := k8s.NewClient("testdata/.kube/config", nil, &k8s.ClientOptions{
+		QPS:   50,
+		Burst: 100,
+	})
+
+	restConfig := client.KubeRestConfig()
+	if expected, actual := float32(50), restConfig.QPS; expected != actual {
+		t.Errorf("Expected QPS to be %v, actually %v", expected, actual)
+	}
+	if expected, actual := 100, restConfig.Burst; expected != actual {
+		t.Errorf("Expected burst to be %v, actually %v", expected, actual)
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package k8s
+
+import (
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+)
+
+// DryRunCreate creates the resource in dry run mode and returns the resource
+// as defaulted and admitted by the API server, the resource is not persisted.
+// The typed clients do not accept create options, so the request is made with
+// the REST client for the resource's API group.
+func DryRunCreate(client rest.Interface, resource string, obj runtime.Object) (runtime.Object, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	result := newObject(obj)
+	err = client.Post().
+		NamespaceIfScoped(accessor.GetNamespace(), accessor.GetNamespace() != "").
+		Resource(resource).
+		VersionedParams(&metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}}, metav1.ParameterCodec).
+		Body(obj).
+		Do().
+		Into(result)
+	return result, err
+}
+
+// DryRunUpdate updates the resource in dry run mode and returns the resource
+// as defaulted and admitted by the API server, the change is not persisted.
+func DryRunUpdate(client rest.Interface, resource string, obj runtime.Object) (runtime.Object, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	result := newObject(obj)
+	err = client.Put().
+		NamespaceIfScoped(accessor.GetNamespace(), accessor.GetNamespace() != "").
+		Resource(resource).
+		Name(accessor.GetName()).
+		VersionedParams(&metav1.UpdateOptions{DryRun: []string{metav1.DryRunAll}}, metav1.ParameterCodec).
+		Body(obj).
+		Do().
+		Into(result)
+	return result, err
+}
+
+func newObject(obj runtime.Object) runtime.Object {
+	return reflect.New(reflect.TypeOf(obj).Elem()).Interface().(runtime.Object)
+}
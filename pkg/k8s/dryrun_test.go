@@ -0,0 +1,110 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package k8s_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/projectriff/cli/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestDryRun(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "my-secret",
+		},
+	}
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "my-namespace",
+		},
+	}
+
+	tests := []struct {
+		name           string
+		dryRun         func(client rest.Interface) (runtime.Object, error)
+		expectedMethod string
+		expectedPath   string
+	}{{
+		name: "create",
+		dryRun: func(client rest.Interface) (runtime.Object, error) {
+			return k8s.DryRunCreate(client, "secrets", secret)
+		},
+		expectedMethod: http.MethodPost,
+		expectedPath:   "/api/v1/namespaces/default/secrets",
+	}, {
+		name: "create cluster scoped",
+		dryRun: func(client rest.Interface) (runtime.Object, error) {
+			return k8s.DryRunCreate(client, "namespaces", namespace)
+		},
+		expectedMethod: http.MethodPost,
+		expectedPath:   "/api/v1/namespaces",
+	}, {
+		name: "update",
+		dryRun: func(client rest.Interface) (runtime.Object, error) {
+			return k8s.DryRunUpdate(client, "secrets", secret)
+		},
+		expectedMethod: http.MethodPut,
+		expectedPath:   "/api/v1/namespaces/default/secrets/my-secret",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var method, path, query string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				method, path, query = r.Method, r.URL.Path, r.URL.RawQuery
+				// respond as the server would with the defaulted resource
+				body, _ := ioutil.ReadAll(r.Body)
+				obj := map[string]interface{}{}
+				json.Unmarshal(body, &obj)
+				obj["metadata"].(map[string]interface{})["uid"] = "dry-run-uid"
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(obj)
+			}))
+			defer server.Close()
+
+			clientset := kubernetes.NewForConfigOrDie(&rest.Config{Host: server.URL})
+			result, err := test.dryRun(clientset.CoreV1().RESTClient())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if expected, actual := test.expectedMethod, method; expected != actual {
+				t.Errorf("expected method %q, actually %q", expected, actual)
+			}
+			if expected, actual := test.expectedPath, path; expected != actual {
+				t.Errorf("expected path %q, actually %q", expected, actual)
+			}
+			if expected, actual := "dryRun=All", query; expected != actual {
+				t.Errorf("expected query %q, actually %q", expected, actual)
+			}
+			if expected, actual := "dry-run-uid", string(result.(metav1.Object).GetUID()); expected != actual {
+				t.Errorf("expected uid %q, actually %q", expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package k8s
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// DefaultRetryBackoff is the backoff between attempts of a request that failed
+// with a transient error.
+var DefaultRetryBackoff = wait.Backoff{
+	Duration: 250 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    5,
+}
+
+// maxRetryAfter caps the delay requested by the server before retrying.
+const maxRetryAfter = 10 * time.Second
+
+// RetryTransport returns a function that wraps a transport to retry requests
+// that fail with a transient error, waiting for the backoff between attempts.
+// Requests are retried when the server is throttling them (429), and for
+// idempotent requests when the server fails (5xx) or the connection is reset.
+func RetryTransport(backoff wait.Backoff) func(http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return &retryRoundTripper{
+			delegate: rt,
+			backoff:  backoff,
+		}
+	}
+}
+
+type retryRoundTripper struct {
+	delegate http.RoundTripper
+	backoff  wait.Backoff
+}
+
+func (rt *retryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// each request has its own copy of the backoff
+	backoff := rt.backoff
+	for {
+		res, err := rt.delegate.RoundTrip(req)
+		if backoff.Steps <= 1 || !isRetryable(req, res, err) {
+			return res, err
+		}
+
+		delay := nextDelay(&backoff)
+		if res != nil {
+			if retryAfter := retryAfter(res); retryAfter > delay {
+				delay = retryAfter
+			}
+			// drain the body so the connection may be reused
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		ctx := req.Context()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+
+		req = req.Clone(ctx)
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func isRetryable(req *http.Request, res *http.Response, err error) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		// the body can not be sent again
+		return false
+	}
+	if res != nil && res.StatusCode == http.StatusTooManyRequests {
+		// the server did not process the request
+		return true
+	}
+	if !isIdempotent(req) {
+		return false
+	}
+	if err != nil {
+		return errors.Is(err, syscall.ECONNRESET)
+	}
+	switch res.StatusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// nextDelay steps the backoff, returning the delay before the next attempt.
+func nextDelay(backoff *wait.Backoff) time.Duration {
+	delay := backoff.Duration
+	if backoff.Jitter > 0 {
+		delay = wait.Jitter(delay, backoff.Jitter)
+	}
+	backoff.Duration = time.Duration(float64(backoff.Duration) * backoff.Factor)
+	backoff.Steps--
+	return delay
+}
+
+// retryAfter is the delay requested by the server in seconds.
+func retryAfter(res *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(res.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	delay := time.Duration(seconds) * time.Second
+	if delay > maxRetryAfter {
+		return maxRetryAfter
+	}
+	return delay
+}
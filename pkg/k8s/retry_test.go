@@ -0,0 +1,177 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package k8s_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/projectriff/cli/pkg/k8s"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestRetryTransport(t *testing.T) {
+	backoff := wait.Backoff{Duration: time.Millisecond, Factor: 2, Steps: 3}
+
+	tests := []struct {
+		name             string
+		method           string
+		statuses         []int
+		expectedStatus   int
+		expectedAttempts int32
+	}{{
+		name:             "success",
+		method:           http.MethodGet,
+		statuses:         []int{http.StatusOK},
+		expectedStatus:   http.StatusOK,
+		expectedAttempts: 1,
+	}, {
+		name:             "retry server error",
+		method:           http.MethodGet,
+		statuses:         []int{http.StatusServiceUnavailable, http.StatusInternalServerError, http.StatusOK},
+		expectedStatus:   http.StatusOK,
+		expectedAttempts: 3,
+	}, {
+		name:             "retry too many requests",
+		method:           http.MethodPost,
+		statuses:         []int{http.StatusTooManyRequests, http.StatusCreated},
+		expectedStatus:   http.StatusCreated,
+		expectedAttempts: 2,
+	}, {
+		name:             "no retry for server error on create",
+		method:           http.MethodPost,
+		statuses:         []int{http.StatusInternalServerError, http.StatusCreated},
+		expectedStatus:   http.StatusInternalServerError,
+		expectedAttempts: 1,
+	}, {
+		name:             "no retry for client error",
+		method:           http.MethodGet,
+		statuses:         []int{http.StatusNotFound, http.StatusOK},
+		expectedStatus:   http.StatusNotFound,
+		expectedAttempts: 1,
+	}, {
+		name:             "gives up",
+		method:           http.MethodGet,
+		statuses:         []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusOK},
+		expectedStatus:   http.StatusBadGateway,
+		expectedAttempts: 3,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempt := atomic.AddInt32(&attempts, 1)
+				// the body is sent with each attempt
+				if body, _ := ioutil.ReadAll(r.Body); string(body) != `{"kind":"Pod"}` {
+					t.Errorf("unexpected body %q", body)
+				}
+				w.WriteHeader(test.statuses[attempt-1])
+			}))
+			defer server.Close()
+
+			transport := k8s.RetryTransport(backoff)(http.DefaultTransport)
+			req, err := http.NewRequest(test.method, server.URL, strings.NewReader(`{"kind":"Pod"}`))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			res, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			res.Body.Close()
+
+			if expected, actual := test.expectedStatus, res.StatusCode; expected != actual {
+				t.Errorf("expected status %d, actually %d", expected, actual)
+			}
+			if expected, actual := test.expectedAttempts, atomic.LoadInt32(&attempts); expected != actual {
+				t.Errorf("expected %d attempts, actually %d", expected, actual)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_ConnectionReset(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer listener.Close()
+
+	var attempts int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				// reset the connection rather than closing it cleanly
+				conn.(*net.TCPConn).SetLinger(0)
+				conn.Close()
+				continue
+			}
+			// read the request before responding
+			conn.Read(make([]byte, 1024))
+			conn.Write([]byte("HTTP/1.1 200 OK\r\nContent-Length: 0\r\nConnection: close\r\n\r\n"))
+			conn.Close()
+		}
+	}()
+
+	transport := k8s.RetryTransport(wait.Backoff{Duration: time.Millisecond, Factor: 2, Steps: 3})(&http.Transport{})
+	req, err := http.NewRequest(http.MethodGet, "http://"+listener.Addr().String(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res.Body.Close()
+
+	if expected, actual := http.StatusOK, res.StatusCode; expected != actual {
+		t.Errorf("expected status %d, actually %d", expected, actual)
+	}
+	if expected, actual := int32(2), atomic.LoadInt32(&attempts); expected != actual {
+		t.Errorf("expected %d attempts, actually %d", expected, actual)
+	}
+}
+
+func TestRetryTransport_Canceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	transport := k8s.RetryTransport(wait.Backoff{Duration: time.Hour, Factor: 2, Steps: 3})(http.DefaultTransport)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := transport.RoundTrip(req.WithContext(ctx)); err != context.DeadlineExceeded {
+		t.Errorf("expected error %v, actually %v", context.DeadlineExceeded, err)
+	}
+}
@@ -0,0 +1,213 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package k8s
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Verbosity levels for tracing requests to the cluster, each level includes
+// the levels below it.
+const (
+	// TraceRequests logs the method, URL, status and latency of each request
+	TraceRequests = 1
+	// TraceHeaders logs the request and response headers
+	TraceHeaders = 2
+	// TraceBodies logs the request and response bodies
+	TraceBodies = 3
+)
+
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+}
+
+// lastAppliedAnnotation holds a copy of the resource applied by kubectl,
+// including the data of secrets
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// TraceTransport returns a function that wraps a transport to log requests to
+// out at the verbosity level. Nil is returned when requests are not traced.
+func TraceTransport(out io.Writer, verbosity int) func(http.RoundTripper) http.RoundTripper {
+	if verbosity < TraceRequests {
+		return nil
+	}
+	mutex := &sync.Mutex{}
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return &traceRoundTripper{
+			delegate:  rt,
+			out:       out,
+			mutex:     mutex,
+			verbosity: verbosity,
+		}
+	}
+}
+
+type traceRoundTripper struct {
+	delegate  http.RoundTripper
+	out       io.Writer
+	mutex     *sync.Mutex
+	verbosity int
+}
+
+func (rt *traceRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if rt.verbosity >= TraceBodies && req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if req.GetBody != nil {
+			// read a copy of the body, the request is sent as is
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			requestBody, err = ioutil.ReadAll(body)
+			body.Close()
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			// a RoundTripper must not modify the request, send a copy with the
+			// body that was read
+			requestBody, err = ioutil.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = ioutil.NopCloser(bytes.NewReader(requestBody))
+			req.GetBody = func() (io.ReadCloser, error) {
+				return ioutil.NopCloser(bytes.NewReader(requestBody)), nil
+			}
+		}
+	}
+
+	start := time.Now()
+	res, err := rt.delegate.RoundTrip(req)
+	latency := time.Since(start).Round(time.Millisecond)
+
+	// buffer the trace so concurrent requests are not interleaved
+	trace := &bytes.Buffer{}
+	if err != nil {
+		fmt.Fprintf(trace, "%s %s failed in %s: %v\n", req.Method, req.URL, latency, err)
+	} else {
+		fmt.Fprintf(trace, "%s %s %s in %s\n", req.Method, req.URL, res.Status, latency)
+	}
+	if rt.verbosity >= TraceHeaders {
+		traceHeaders(trace, "Request Headers", req.Header)
+	}
+	if rt.verbosity >= TraceBodies && len(requestBody) != 0 {
+		fmt.Fprintf(trace, "Request Body: %s\n", redactBody(requestBody))
+	}
+	if err == nil {
+		if rt.verbosity >= TraceHeaders {
+			traceHeaders(trace, "Response Headers", res.Header)
+		}
+		if rt.verbosity >= TraceBodies {
+			if isStreaming(req) {
+				// reading the body would block until the stream ends
+				fmt.Fprintf(trace, "Response Body: <stream>\n")
+			} else if res.Body != nil {
+				responseBody, readErr := ioutil.ReadAll(res.Body)
+				res.Body.Close()
+				res.Body = ioutil.NopCloser(bytes.NewReader(responseBody))
+				if readErr != nil {
+					err = readErr
+					res = nil
+				}
+				fmt.Fprintf(trace, "Response Body: %s\n", redactBody(responseBody))
+			}
+		}
+	}
+
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	rt.out.Write(trace.Bytes())
+
+	return res, err
+}
+
+func traceHeaders(out io.Writer, title string, header http.Header) {
+	fmt.Fprintf(out, "%s:\n", title)
+	keys := []string{}
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range header[key] {
+			if redactedHeaders[http.CanonicalHeaderKey(key)] {
+				value = "<redacted>"
+			}
+			fmt.Fprintf(out, "    %s: %s\n", key, value)
+		}
+	}
+}
+
+// redactBody hides the values of secrets in a json body, like the credentials
+// applied by riff. Other bodies are returned unchanged.
+func redactBody(body []byte) []byte {
+	resource := map[string]interface{}{}
+	if err := json.Unmarshal(body, &resource); err != nil {
+		return body
+	}
+	switch resource["kind"] {
+	case "Secret":
+		redactSecret(resource)
+	case "SecretList":
+		items, _ := resource["items"].([]interface{})
+		for _, item := range items {
+			if secret, ok := item.(map[string]interface{}); ok {
+				redactSecret(secret)
+			}
+		}
+	default:
+		return body
+	}
+	redacted, err := json.Marshal(resource)
+	if err != nil {
+		return []byte("<redacted>")
+	}
+	return redacted
+}
+
+func redactSecret(secret map[string]interface{}) {
+	for _, field := range []string{"data", "stringData"} {
+		values, _ := secret[field].(map[string]interface{})
+		for key := range values {
+			values[key] = "<redacted>"
+		}
+	}
+	metadata, _ := secret["metadata"].(map[string]interface{})
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+	if _, ok := annotations[lastAppliedAnnotation]; ok {
+		annotations[lastAppliedAnnotation] = "<redacted>"
+	}
+}
+
+func isStreaming(req *http.Request) bool {
+	query := req.URL.Query()
+	return strings.EqualFold(query.Get("watch"), "true") || strings.EqualFold(query.Get("follow"), "true")
+}
@@ -0,0 +1,230 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package k8s_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/projectriff/cli/pkg/k8s"
+)
+
+func TestTraceTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Date", "Mon, 01 Jan 2019 00:00:00 GMT")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"kind":"Status"}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		verbosity int
+		url       string
+		expected  string
+	}{{
+		name:      "disabled",
+		verbosity: 0,
+		expected:  "",
+	}, {
+		name:      "requests",
+		verbosity: k8s.TraceRequests,
+		expected: `
+POST {{url}}/api 404 Not Found in {{latency}}
+`,
+	}, {
+		name:      "headers",
+		verbosity: k8s.TraceHeaders,
+		expected: `
+POST {{url}}/api 404 Not Found in {{latency}}
+Request Headers:
+    Authorization: <redacted>
+    Content-Type: application/json
+Response Headers:
+    Content-Length: 17
+    Content-Type: application/json
+    Date: Mon, 01 Jan 2019 00:00:00 GMT
+`,
+	}, {
+		name:      "bodies",
+		verbosity: k8s.TraceBodies,
+		expected: `
+POST {{url}}/api 404 Not Found in {{latency}}
+Request Headers:
+    Authorization: <redacted>
+    Content-Type: application/json
+Request Body: {"kind":"Pod"}
+Response Headers:
+    Content-Length: 17
+    Content-Type: application/json
+    Date: Mon, 01 Jan 2019 00:00:00 GMT
+Response Body: {"kind":"Status"}
+`,
+	}, {
+		name:      "streaming",
+		verbosity: k8s.TraceBodies,
+		url:       "/api?watch=true",
+		expected: `
+POST {{url}}/api?watch=true 404 Not Found in {{latency}}
+Request Headers:
+    Authorization: <redacted>
+    Content-Type: application/json
+Request Body: {"kind":"Pod"}
+Response Headers:
+    Content-Length: 17
+    Content-Type: application/json
+    Date: Mon, 01 Jan 2019 00:00:00 GMT
+Response Body: <stream>
+`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			var transport http.RoundTripper = http.DefaultTransport
+			if wrap := k8s.TraceTransport(out, test.verbosity); wrap != nil {
+				transport = wrap(transport)
+			}
+
+			url := test.url
+			if url == "" {
+				url = "/api"
+			}
+			req, err := http.NewRequest(http.MethodPost, server.URL+url, strings.NewReader(`{"kind":"Pod"}`))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			req.Header.Set("Authorization", "Bearer secret")
+			req.Header.Set("Content-Type", "application/json")
+			res, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer res.Body.Close()
+
+			// the body is still readable after tracing
+			body, err := ioutil.ReadAll(res.Body)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if expected, actual := `{"kind":"Status"}`, string(body); expected != actual {
+				t.Errorf("expected body %q, actually %q", expected, actual)
+			}
+
+			expected := regexp.QuoteMeta(strings.TrimPrefix(test.expected, "\n"))
+			expected = strings.ReplaceAll(expected, regexp.QuoteMeta("{{url}}"), regexp.QuoteMeta(server.URL))
+			expected = strings.ReplaceAll(expected, regexp.QuoteMeta("{{latency}}"), `[0-9.]+m?s`)
+			if !regexp.MustCompile("^" + expected + "$").MatchString(out.String()) {
+				t.Errorf("unexpected trace, expected to match %q, actually %q", expected, out.String())
+			}
+		})
+	}
+}
+
+func TestTraceTransport_Secrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the request body is sent unchanged
+		if body, _ := ioutil.ReadAll(r.Body); !strings.Contains(string(body), "1password") {
+			t.Errorf("expected request body to contain the password, actually %q", body)
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"kind":"SecretList","items":[{"kind":"Secret","data":{"password":"MXBhc3N3b3Jk"}}]}`)
+	}))
+	defer server.Close()
+
+	out := &bytes.Buffer{}
+	transport := k8s.TraceTransport(out, k8s.TraceBodies)(http.DefaultTransport)
+
+	body := `{"kind":"Secret","metadata":{"name":"my-creds","annotations":{"kubectl.kubernetes.io/last-applied-configuration":"{\"stringData\":{\"password\":\"1password\"}}"}},"stringData":{"password":"1password","username":"projectriff"}}`
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/api/v1/namespaces/default/secrets", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res.Body.Close()
+
+	trace := out.String()
+	for _, secret := range []string{"1password", "projectriff", "MXBhc3N3b3Jk"} {
+		if strings.Contains(trace, secret) {
+			t.Errorf("expected trace to not contain %q, actually %q", secret, trace)
+		}
+	}
+	for _, expected := range []string{
+		`Request Body: {"kind":"Secret","metadata":{"annotations":{"kubectl.kubernetes.io/last-applied-configuration":"\u003credacted\u003e"},"name":"my-creds"},"stringData":{"password":"\u003credacted\u003e","username":"\u003credacted\u003e"}}`,
+		`Response Body: {"items":[{"data":{"password":"\u003credacted\u003e"},"kind":"Secret"}],"kind":"SecretList"}`,
+	} {
+		if !strings.Contains(trace, expected) {
+			t.Errorf("expected trace to contain %q, actually %q", expected, trace)
+		}
+	}
+}
+
+func TestTraceTransport_RequestNotModified(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body, _ := ioutil.ReadAll(r.Body); string(body) != `{"kind":"Pod"}` {
+			t.Errorf("unexpected body %q", body)
+		}
+	}))
+	defer server.Close()
+
+	transport := k8s.TraceTransport(&bytes.Buffer{}, k8s.TraceBodies)(http.DefaultTransport)
+
+	// without GetBody the body can only be read once
+	body := ioutil.NopCloser(strings.NewReader(`{"kind":"Pod"}`))
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/api", body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res.Body.Close()
+
+	if req.Body != body {
+		t.Errorf("expected request body to not be replaced")
+	}
+	if req.GetBody != nil {
+		t.Errorf("expected request GetBody to not be set")
+	}
+}
+
+func TestTraceTransport_Error(t *testing.T) {
+	out := &bytes.Buffer{}
+	transport := k8s.TraceTransport(out, k8s.TraceRequests)(http.DefaultTransport)
+
+	req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1:0/api", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := transport.RoundTrip(req); err == nil {
+		t.Fatalf("expected error")
+	}
+	if expected, actual := "GET http://127.0.0.1:0/api failed in ", out.String(); !strings.HasPrefix(actual, expected) {
+		t.Errorf("expected trace to start with %q, actually %q", expected, actual)
+	}
+}
@@ -47,6 +47,37 @@ func WaitUntilReady(ctx context.Context, client rest.Interface, resource string,
 	return err
 }
 
+// WaitUntil watches for mutations of the target object until the condition is satisfied. The
+// version of the target object that satisfied the condition is returned.
+func WaitUntil(ctx context.Context, client rest.Interface, resource string, target object, condition func(runtime.Object) (bool, error)) (runtime.Object, error) {
+	lw := GetListerWatcher(ctx, client, resource, target)
+	event, err := watchclient.UntilWithSync(ctx, lw, target, nil, targetCondition(target, condition))
+	if err != nil {
+		return nil, err
+	}
+	return event.Object, nil
+}
+
+func targetCondition(target object, condition func(runtime.Object) (bool, error)) watchclient.ConditionFunc {
+	return func(event watch.Event) (bool, error) {
+		if event.Type == watch.Error {
+			return false, fmt.Errorf("error waiting for %s %q", strings.ToLower(target.GetObjectKind().GroupVersionKind().Kind), target.GetName())
+		}
+		obj, ok := event.Object.(object)
+		if !ok || obj.GetUID() != target.GetUID() {
+			// event is not for the target resource
+			return false, nil
+		}
+		switch event.Type {
+		case watch.Added, watch.Modified:
+			return condition(event.Object)
+		case watch.Deleted:
+			return false, fmt.Errorf("%s %q deleted", strings.ToLower(target.GetObjectKind().GroupVersionKind().Kind), target.GetName())
+		}
+		return false, nil
+	}
+}
+
 func readyCondition(target object) watchclient.ConditionFunc {
 	return func(event watch.Event) (bool, error) {
 		if event.Type == watch.Error {
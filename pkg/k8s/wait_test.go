@@ -19,6 +19,7 @@ package k8s_test
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"testing"
 	"time"
 
@@ -27,9 +28,13 @@ import (
 	"github.com/projectriff/cli/pkg/k8s"
 	rifftesting "github.com/projectriff/cli/pkg/testing"
 	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/projectriff/system/pkg/apis/core/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
 	cachetesting "k8s.io/client-go/tools/cache/testing"
 )
 
@@ -118,6 +123,165 @@ func TestWaitUntilReady(t *testing.T) {
 	}
 }
 
+func TestWaitUntilReady_WatchExpired(t *testing.T) {
+	application := &buildv1alpha1.Application{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Application",
+			APIVersion: "build.projectriff.io/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            "my-application",
+			UID:             "c6acbbab-87dd-11e9-807c-42010a80011d",
+			ResourceVersion: "1",
+		},
+		Status: buildv1alpha1.ApplicationStatus{
+			Status: duckv1beta1.Status{
+				Conditions: duckv1beta1.Conditions{
+					{
+						Type:   knapis.ConditionReady,
+						Status: corev1.ConditionUnknown,
+					},
+				},
+			},
+		},
+	}
+
+	// the first watch expires, the application becomes ready on the next watch
+	watches := make(chan *watch.FakeWatcher, 2)
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return &buildv1alpha1.ApplicationList{
+				ListMeta: metav1.ListMeta{ResourceVersion: "1"},
+				Items:    []buildv1alpha1.Application{*application.DeepCopy()},
+			}, nil
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			w := watch.NewFakeWithChanSize(1, false)
+			watches <- w
+			return w, nil
+		},
+	}
+	ctx := k8s.WithListerWatcher(context.Background(), lw)
+
+	client := rifftesting.NewClient(application)
+	done := make(chan error, 1)
+	go func() {
+		done <- k8s.WaitUntilReady(ctx, client.Build().RESTClient(), "applications", application)
+	}()
+
+	expired := <-watches
+	expired.Error(&metav1.Status{
+		Status: metav1.StatusFailure,
+		Code:   http.StatusGone,
+		Reason: metav1.StatusReasonExpired,
+	})
+	ready := updateReady(application, corev1.ConditionTrue, "")
+	ready.Object.(*buildv1alpha1.Application).ResourceVersion = "2"
+	(<-watches).Action(ready.Type, ready.Object)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected no error, actually %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Errorf("timed out waiting for ready")
+	}
+}
+
+func TestWaitUntil(t *testing.T) {
+	// using Deployer, but any type will work
+	deployer := &corev1alpha1.Deployer{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Deployer",
+			APIVersion: "core.projectriff.io/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "my-deployer",
+			UID:       "c6acbbab-87dd-11e9-807c-42010a80011d",
+		},
+	}
+	hasServiceName := func(obj runtime.Object) (bool, error) {
+		return obj.(*corev1alpha1.Deployer).Status.ServiceName != "", nil
+	}
+
+	tests := []struct {
+		name     string
+		resource *corev1alpha1.Deployer
+		events   []watch.Event
+		expected string
+		err      error
+	}{{
+		name:     "condition met",
+		resource: deployer.DeepCopy(),
+		events: []watch.Event{
+			updateServiceName(deployer, "", ""),
+			updateServiceName(deployer, "", "my-service"),
+		},
+		expected: "my-service",
+	}, {
+		name:     "ignore other resources",
+		resource: deployer.DeepCopy(),
+		events: []watch.Event{
+			updateServiceName(deployer, "not-a-uid", "not-my-service"),
+			updateServiceName(deployer, "", "my-service"),
+		},
+		expected: "my-service",
+	}, {
+		name:     "bail on delete",
+		resource: deployer.DeepCopy(),
+		events: []watch.Event{
+			updateServiceName(deployer, "", ""),
+			watch.Event{Type: watch.Deleted, Object: deployer.DeepCopy()},
+		},
+		err: fmt.Errorf("%s %q deleted", "deployer", "my-deployer"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lw := cachetesting.NewFakeControllerSource()
+			ctx := k8s.WithListerWatcher(context.Background(), lw)
+
+			client := rifftesting.NewClient(deployer)
+			done := make(chan error, 1)
+			defer close(done)
+			var actual runtime.Object
+			go func() {
+				var err error
+				actual, err = k8s.WaitUntil(ctx, client.CoreRuntime().RESTClient(), "deployers", deployer, hasServiceName)
+				done <- err
+			}()
+
+			time.Sleep(5 * time.Millisecond)
+			for _, event := range test.events {
+				lw.Change(event, 1)
+			}
+			lw.Shutdown()
+
+			err := <-done
+			if expected, actual := fmt.Sprintf("%s", test.err), fmt.Sprintf("%s", err); expected != actual {
+				t.Errorf("expected error %v, actually %v", expected, actual)
+			}
+			if test.expected != "" {
+				if expected, actual := test.expected, actual.(*corev1alpha1.Deployer).Status.ServiceName; expected != actual {
+					t.Errorf("expected service name %q, actually %q", expected, actual)
+				}
+			}
+		})
+	}
+}
+
+func updateServiceName(deployer *corev1alpha1.Deployer, uid types.UID, serviceName string) watch.Event {
+	deployer = deployer.DeepCopy()
+	if uid != "" {
+		deployer.UID = uid
+	}
+	deployer.Status.ServiceName = serviceName
+	return watch.Event{Type: watch.Modified, Object: deployer}
+}
+
 func updateReady(application *buildv1alpha1.Application, status corev1.ConditionStatus, message string) watch.Event {
 	application = application.DeepCopy()
 	application.Status.Conditions[0].Status = status
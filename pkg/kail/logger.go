@@ -17,15 +17,21 @@
 package kail
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
+	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	logutil "github.com/boz/go-logutil"
 	"github.com/boz/kail"
+	"github.com/boz/kcache/filter"
+	"github.com/boz/kcache/types/pod"
 	"github.com/projectriff/cli/pkg/k8s"
 	"github.com/projectriff/system/pkg/apis/build"
 	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
@@ -35,93 +41,176 @@ import (
 	knativev1alpha1 "github.com/projectriff/system/pkg/apis/knative/v1alpha1"
 	"github.com/projectriff/system/pkg/apis/streaming"
 	streamv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
 )
 
+// LogOptions customize which logs are streamed and how each log line is written
+type LogOptions struct {
+	// Since is the relative time to start reading logs from, all existing logs are read when
+	// zero and not following
+	Since time.Duration
+	// SinceTime is the absolute time to start reading logs from, overriding Since when set
+	SinceTime time.Time
+	// Previous reads the logs of the previous instance of each container that has restarted,
+	// implies NoFollow
+	Previous bool
+	// NoFollow reads existing logs and returns instead of waiting for new logs
+	NoFollow bool
+	// Containers to stream logs from, overriding the default containers for the resource
+	Containers []string
+	// Grep is a regular expression log lines must match to be written
+	Grep string
+	// Output is the format for each log line, either human readable (the default) or OutputJSON
+	Output string
+	// Timestamps includes the time each log line was written
+	Timestamps bool
+}
+
 type Logger interface {
-	ApplicationLogs(ctx context.Context, application *buildv1alpha1.Application, since time.Duration, out io.Writer) error
-	FunctionLogs(ctx context.Context, function *buildv1alpha1.Function, since time.Duration, out io.Writer) error
-	CoreDeployerLogs(ctx context.Context, deployer *corev1alpha1.Deployer, since time.Duration, out io.Writer) error
-	StreamingProcessorLogs(ctx context.Context, processor *streamv1alpha1.Processor, since time.Duration, out io.Writer) error
-	KnativeDeployerLogs(ctx context.Context, deployer *knativev1alpha1.Deployer, since time.Duration, out io.Writer) error
+	ApplicationLogs(ctx context.Context, application *buildv1alpha1.Application, opts LogOptions, out io.Writer) error
+	FunctionLogs(ctx context.Context, function *buildv1alpha1.Function, opts LogOptions, out io.Writer) error
+	CoreDeployerLogs(ctx context.Context, deployer *corev1alpha1.Deployer, opts LogOptions, out io.Writer) error
+	StreamingProcessorLogs(ctx context.Context, processor *streamv1alpha1.Processor, opts LogOptions, out io.Writer) error
+	KnativeDeployerLogs(ctx context.Context, deployer *knativev1alpha1.Deployer, opts LogOptions, out io.Writer) error
+	// Logs streams logs from every container of pods in the namespace matching any of the
+	// selectors, or from all pods in the namespace when there are no selectors. Each source is
+	// printed in a different color.
+	Logs(ctx context.Context, namespace string, selectors []labels.Selector, opts LogOptions, out io.Writer) error
 }
 
-func NewDefault(k8s k8s.Client) Logger {
+// NewDefault creates a logger for the cluster. Kail's own logs are written to
+// debug, or discarded when debug is nil.
+func NewDefault(k8s k8s.Client, debug io.Writer) Logger {
+	if debug == nil {
+		debug = ioutil.Discard
+	}
 	return &logger{
-		k8s: k8s,
+		k8s:      k8s,
+		debug:    debug,
+		openLogs: openLogs,
 	}
 }
 
 type logger struct {
-	k8s k8s.Client
+	k8s      k8s.Client
+	debug    io.Writer
+	openLogs func(ctx context.Context, client corev1client.CoreV1Interface, namespace, name string, opts *corev1.PodLogOptions) (io.ReadCloser, error)
+}
+
+func openLogs(ctx context.Context, client corev1client.CoreV1Interface, namespace, name string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+	return client.Pods(namespace).GetLogs(name, opts).Context(ctx).Stream()
 }
 
-func (c *logger) ApplicationLogs(ctx context.Context, application *buildv1alpha1.Application, since time.Duration, out io.Writer) error {
+func (c *logger) ApplicationLogs(ctx context.Context, application *buildv1alpha1.Application, opts LogOptions, out io.Writer) error {
 	selector, err := labels.Parse(fmt.Sprintf("%s=%s", build.ApplicationLabelKey, application.Name))
 	if err != nil {
 		panic(err)
 	}
 	containers := []string{}
-	return c.stream(ctx, application.Namespace, selector, containers, since, out)
+	return c.stream(ctx, application.Namespace, []labels.Selector{selector}, containers, opts, out, false)
 }
 
-func (c *logger) FunctionLogs(ctx context.Context, function *buildv1alpha1.Function, since time.Duration, out io.Writer) error {
+func (c *logger) FunctionLogs(ctx context.Context, function *buildv1alpha1.Function, opts LogOptions, out io.Writer) error {
 	selector, err := labels.Parse(fmt.Sprintf("%s=%s", build.FunctionLabelKey, function.Name))
 	if err != nil {
 		panic(err)
 	}
 	containers := []string{}
-	return c.stream(ctx, function.Namespace, selector, containers, since, out)
+	return c.stream(ctx, function.Namespace, []labels.Selector{selector}, containers, opts, out, false)
 }
 
-func (c *logger) CoreDeployerLogs(ctx context.Context, deployer *corev1alpha1.Deployer, since time.Duration, out io.Writer) error {
+func (c *logger) CoreDeployerLogs(ctx context.Context, deployer *corev1alpha1.Deployer, opts LogOptions, out io.Writer) error {
 	selector, err := labels.Parse(fmt.Sprintf("%s=%s", core.DeployerLabelKey, deployer.Name))
 	if err != nil {
 		panic(err)
 	}
 	containers := []string{}
-	return c.stream(ctx, deployer.Namespace, selector, containers, since, out)
+	return c.stream(ctx, deployer.Namespace, []labels.Selector{selector}, containers, opts, out, false)
 }
 
-func (c *logger) StreamingProcessorLogs(ctx context.Context, processor *streamv1alpha1.Processor, since time.Duration, out io.Writer) error {
+func (c *logger) StreamingProcessorLogs(ctx context.Context, processor *streamv1alpha1.Processor, opts LogOptions, out io.Writer) error {
 	selector, err := labels.Parse(fmt.Sprintf("%s=%s", streaming.ProcessorLabelKey, processor.Name))
 	if err != nil {
 		panic(err)
 	}
 	containers := []string{"function", "processor"}
-	return c.stream(ctx, processor.Namespace, selector, containers, since, out)
+	return c.stream(ctx, processor.Namespace, []labels.Selector{selector}, containers, opts, out, false)
 }
 
-func (c *logger) KnativeDeployerLogs(ctx context.Context, deployer *knativev1alpha1.Deployer, since time.Duration, out io.Writer) error {
+func (c *logger) KnativeDeployerLogs(ctx context.Context, deployer *knativev1alpha1.Deployer, opts LogOptions, out io.Writer) error {
 	selector, err := labels.Parse(fmt.Sprintf("%s=%s", knative.DeployerLabelKey, deployer.Name))
 	if err != nil {
 		panic(err)
 	}
 	containers := []string{"user-container"}
-	return c.stream(ctx, deployer.Namespace, selector, containers, since, out)
+	return c.stream(ctx, deployer.Namespace, []labels.Selector{selector}, containers, opts, out, false)
 }
 
-func (c *logger) stream(ctx context.Context, namespace string, selector labels.Selector, containers []string, since time.Duration, out io.Writer) error {
-	// avoid kail logs appearing
-	l := logutil.New(log.New(ioutil.Discard, "", log.LstdFlags), ioutil.Discard)
+func (c *logger) Logs(ctx context.Context, namespace string, selectors []labels.Selector, opts LogOptions, out io.Writer) error {
+	containers := []string{}
+	return c.stream(ctx, namespace, selectors, containers, opts, out, true)
+}
+
+func (c *logger) stream(ctx context.Context, namespace string, selectors []labels.Selector, containers []string, opts LogOptions, out io.Writer, colorSources bool) error {
+	if len(opts.Containers) != 0 {
+		containers = opts.Containers
+	}
+	writer, err := newWriter(out, opts)
+	if err != nil {
+		return err
+	}
+	if colorSources {
+		writer.colorSources()
+	}
+
+	if opts.NoFollow || opts.Previous {
+		return c.dump(ctx, c.k8s.Core(), namespace, selectors, containers, opts, writer)
+	}
+
+	// kail logs are only shown when debugging
+	l := logutil.New(log.New(c.debug, "", log.LstdFlags), c.debug)
 	ctx = logutil.NewContext(ctx, l)
 
-	rc := c.k8s.KubeRestConfig()
+	rc := timestampLogs(c.k8s.KubeRestConfig())
 	cs, err := kubernetes.NewForConfig(rc)
 	if err != nil {
 		return err
 	}
-	ds, err := kail.NewDSBuilder().WithNamespace(namespace).WithSelectors(selector).Create(ctx, cs)
+
+	since := opts.Since
+	if !opts.SinceTime.IsZero() {
+		since = time.Since(opts.SinceTime)
+	}
+	if since < time.Second {
+		// kail reads logs with a resolution of seconds
+		since = time.Second
+	}
+
+	ds, err := kail.NewDSBuilder().WithNamespace(namespace).Create(ctx, cs)
 	if err != nil {
 		return err
 	}
-	filter := kail.NewContainerFilter(containers)
-	controller, err := kail.NewController(ctx, cs, rc, ds.Pods(), filter, since)
+	var pods pod.Controller = ds.Pods()
+	if len(selectors) != 0 {
+		// kail requires pods to match every selector, we want pods that match any selector
+		filters := make([]filter.Filter, len(selectors))
+		for i, selector := range selectors {
+			filters[i] = filter.Selector(selector)
+		}
+		pods, err = pods.CloneWithFilter(filter.Or(filters...))
+		if err != nil {
+			return err
+		}
+	}
+	controller, err := kail.NewController(ctx, cs, rc, pods, kail.NewContainerFilter(containers), since)
 	if err != nil {
 		return err
 	}
-	writer := kail.NewWriter(out)
 	for {
 		select {
 		case ev := <-controller.Events():
@@ -131,3 +220,178 @@ func (c *logger) stream(ctx context.Context, namespace string, selector labels.S
 		}
 	}
 }
+
+// dump writes the existing logs for each matching container and returns. kail always follows
+// logs, and is unable to read logs from previous container instances.
+func (c *logger) dump(ctx context.Context, client corev1client.CoreV1Interface, namespace string, selectors []labels.Selector, containers []string, opts LogOptions, writer *writer) error {
+	pods, err := listPods(client, namespace, selectors)
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods {
+		for _, container := range pod.Spec.Containers {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if len(containers) != 0 && !contains(containers, container.Name) {
+				continue
+			}
+			if opts.Previous && !restarted(pod, container.Name) {
+				// there are no previous logs to read
+				continue
+			}
+			logOpts := &corev1.PodLogOptions{
+				Container:  container.Name,
+				Previous:   opts.Previous,
+				Timestamps: true,
+			}
+			if !opts.SinceTime.IsZero() {
+				sinceTime := metav1.NewTime(opts.SinceTime)
+				logOpts.SinceTime = &sinceTime
+			} else if opts.Since > 0 {
+				sinceSeconds := int64(opts.Since / time.Second)
+				if sinceSeconds < 1 {
+					sinceSeconds = 1
+				}
+				logOpts.SinceSeconds = &sinceSeconds
+			}
+			logs, err := c.openLogs(ctx, client, namespace, pod.Name, logOpts)
+			if err != nil {
+				return err
+			}
+			err = printLogs(logs, &eventSource{pod: pod, container: container.Name}, writer)
+			logs.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// timestampLogs copies the config, requesting the server to prefix each pod log line with the
+// time it was written. kail opens its own log streams without exposing the option.
+func timestampLogs(rc *rest.Config) *rest.Config {
+	rc = rest.CopyConfig(rc)
+	wrap := rc.WrapTransport
+	rc.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		if wrap != nil {
+			rt = wrap(rt)
+		}
+		return &timestampsRoundTripper{delegate: rt}
+	}
+	return rc
+}
+
+type timestampsRoundTripper struct {
+	delegate http.RoundTripper
+}
+
+func (t *timestampsRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodGet && strings.HasSuffix(req.URL.Path, "/log") {
+		req = req.Clone(req.Context())
+		query := req.URL.Query()
+		query.Set("timestamps", "true")
+		req.URL.RawQuery = query.Encode()
+	}
+	return t.delegate.RoundTrip(req)
+}
+
+func printLogs(logs io.Reader, source kail.EventSource, writer *writer) error {
+	reader := bufio.NewReader(logs)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) != 0 {
+			if err := writer.Print(&event{source: source, log: line}); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// listPods lists the pods in the namespace matching any of the selectors, or every pod when
+// there are no selectors, sorted by name
+func listPods(client corev1client.CoreV1Interface, namespace string, selectors []labels.Selector) ([]corev1.Pod, error) {
+	if len(selectors) == 0 {
+		selectors = []labels.Selector{labels.Everything()}
+	}
+	pods := []corev1.Pod{}
+	listed := map[string]bool{}
+	for _, selector := range selectors {
+		list, err := client.Pods(namespace).List(metav1.ListOptions{
+			LabelSelector: selector.String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, pod := range list.Items {
+			if !listed[pod.Name] {
+				listed[pod.Name] = true
+				pods = append(pods, pod)
+			}
+		}
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].Name < pods[j].Name
+	})
+	return pods, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func restarted(pod corev1.Pod, container string) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == container {
+			return status.RestartCount != 0
+		}
+	}
+	return false
+}
+
+// event is a log line read outside of kail
+type event struct {
+	source kail.EventSource
+	log    []byte
+}
+
+func (e *event) Source() kail.EventSource {
+	return e.source
+}
+
+func (e *event) Log() []byte {
+	return e.log
+}
+
+type eventSource struct {
+	pod       corev1.Pod
+	container string
+}
+
+func (s *eventSource) Namespace() string {
+	return s.pod.Namespace
+}
+
+func (s *eventSource) Name() string {
+	return s.pod.Name
+}
+
+func (s *eventSource) Container() string {
+	return s.container
+}
+
+func (s *eventSource) Node() string {
+	return s.pod.Spec.NodeName
+}
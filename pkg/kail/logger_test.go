@@ -0,0 +1,334 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	clientgotesting "k8s.io/client-go/testing"
+)
+
+func TestLogger_dump(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	sinceTime := time.Date(2019, time.August, 20, 12, 30, 15, 0, time.UTC)
+	pods := []runtime.Object{
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "my-pod-b",
+				Labels:    map[string]string{"app": "my-app"},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "function"}, {Name: "processor"}},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "function", RestartCount: 2},
+					{Name: "processor"},
+				},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "my-pod-a",
+				Labels:    map[string]string{"app": "my-app"},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "function"}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "my-other-pod",
+				Labels:    map[string]string{"app": "my-other-app"},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "function"}},
+			},
+		},
+	}
+	selector := labels.SelectorFromSet(labels.Set{"app": "my-app"})
+
+	tests := []struct {
+		name              string
+		selectors         []labels.Selector
+		containers        []string
+		opts              LogOptions
+		expectedSelectors []string
+		expectedOpts      []corev1.PodLogOptions
+		expected          string
+	}{{
+		name:              "all logs",
+		selectors:         []labels.Selector{selector},
+		opts:              LogOptions{NoFollow: true},
+		expectedSelectors: []string{"app=my-app"},
+		expectedOpts: []corev1.PodLogOptions{
+			{Container: "function", Timestamps: true},
+			{Container: "function", Timestamps: true},
+			{Container: "processor", Timestamps: true},
+		},
+		expected: `default/my-pod-a[function]: log 1
+default/my-pod-a[function]: log 2
+default/my-pod-b[function]: log 1
+default/my-pod-b[function]: log 2
+default/my-pod-b[processor]: log 1
+default/my-pod-b[processor]: log 2
+`,
+	}, {
+		name: "any selector",
+		selectors: []labels.Selector{
+			labels.SelectorFromSet(labels.Set{"app": "my-other-app"}),
+			selector,
+			labels.SelectorFromSet(labels.Set{"app": "my-app"}),
+		},
+		containers:        []string{"function"},
+		opts:              LogOptions{NoFollow: true},
+		expectedSelectors: []string{"app=my-other-app", "app=my-app", "app=my-app"},
+		expectedOpts: []corev1.PodLogOptions{
+			{Container: "function", Timestamps: true},
+			{Container: "function", Timestamps: true},
+			{Container: "function", Timestamps: true},
+		},
+		expected: `default/my-other-pod[function]: log 1
+default/my-other-pod[function]: log 2
+default/my-pod-a[function]: log 1
+default/my-pod-a[function]: log 2
+default/my-pod-b[function]: log 1
+default/my-pod-b[function]: log 2
+`,
+	}, {
+		name:              "no selectors",
+		containers:        []string{"processor"},
+		opts:              LogOptions{NoFollow: true},
+		expectedSelectors: []string{""},
+		expectedOpts: []corev1.PodLogOptions{
+			{Container: "processor", Timestamps: true},
+		},
+		expected: `default/my-pod-b[processor]: log 1
+default/my-pod-b[processor]: log 2
+`,
+	}, {
+		name:              "containers",
+		selectors:         []labels.Selector{selector},
+		containers:        []string{"processor"},
+		opts:              LogOptions{NoFollow: true, Since: time.Hour},
+		expectedSelectors: []string{"app=my-app"},
+		expectedOpts: []corev1.PodLogOptions{
+			{Container: "processor", Timestamps: true, SinceSeconds: int64Ptr(3600)},
+		},
+		expected: `default/my-pod-b[processor]: log 1
+default/my-pod-b[processor]: log 2
+`,
+	}, {
+		name:              "since time",
+		selectors:         []labels.Selector{selector},
+		opts:              LogOptions{NoFollow: true, Since: time.Hour, SinceTime: sinceTime},
+		expectedSelectors: []string{"app=my-app"},
+		expectedOpts: []corev1.PodLogOptions{
+			{Container: "function", Timestamps: true, SinceTime: &metav1.Time{Time: sinceTime}},
+			{Container: "function", Timestamps: true, SinceTime: &metav1.Time{Time: sinceTime}},
+			{Container: "processor", Timestamps: true, SinceTime: &metav1.Time{Time: sinceTime}},
+		},
+		expected: `default/my-pod-a[function]: log 1
+default/my-pod-a[function]: log 2
+default/my-pod-b[function]: log 1
+default/my-pod-b[function]: log 2
+default/my-pod-b[processor]: log 1
+default/my-pod-b[processor]: log 2
+`,
+	}, {
+		name:              "previous",
+		selectors:         []labels.Selector{selector},
+		opts:              LogOptions{Previous: true},
+		expectedSelectors: []string{"app=my-app"},
+		expectedOpts: []corev1.PodLogOptions{
+			{Container: "function", Timestamps: true, Previous: true},
+		},
+		expected: `default/my-pod-b[function]: log 1
+default/my-pod-b[function]: log 2
+`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			w, err := newWriter(out, test.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			actualOpts := []corev1.PodLogOptions{}
+			c := &logger{
+				openLogs: func(ctx context.Context, client corev1client.CoreV1Interface, namespace, name string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+					actualOpts = append(actualOpts, *opts)
+					return ioutil.NopCloser(strings.NewReader("log 1\nlog 2")), nil
+				},
+			}
+			clientset := fake.NewSimpleClientset(pods...)
+			actualSelectors := []string{}
+			clientset.PrependReactor("list", "pods", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+				actualSelectors = append(actualSelectors, action.(clientgotesting.ListAction).GetListRestrictions().Labels.String())
+				return false, nil, nil
+			})
+			if err := c.dump(context.TODO(), clientset.CoreV1(), "default", test.selectors, test.containers, test.opts, w); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.expectedSelectors, actualSelectors); diff != "" {
+				t.Errorf("unexpected selectors (-expected, +actual): %s", diff)
+			}
+			if diff := cmp.Diff(test.expectedOpts, actualOpts); diff != "" {
+				t.Errorf("unexpected log options (-expected, +actual): %s", diff)
+			}
+			if diff := cmp.Diff(test.expected, out.String()); diff != "" {
+				t.Errorf("unexpected output (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
+
+func TestLogger_dump_Error(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "my-pod",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "function"}},
+		},
+	}
+	w, err := newWriter(&bytes.Buffer{}, LogOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := &logger{
+		openLogs: func(ctx context.Context, client corev1client.CoreV1Interface, namespace, name string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+			return nil, fmt.Errorf("logs error")
+		},
+	}
+	client := fake.NewSimpleClientset(pod).CoreV1()
+	err = c.dump(context.TODO(), client, "default", nil, nil, LogOptions{NoFollow: true}, w)
+	if expected, actual := "logs error", fmt.Sprintf("%v", err); expected != actual {
+		t.Errorf("expected error %q, actually %q", expected, actual)
+	}
+}
+
+func TestLogger_dump_Canceled(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "my-pod",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "function"}, {Name: "processor"}},
+		},
+	}
+	out := &bytes.Buffer{}
+	w, err := newWriter(out, LogOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	c := &logger{
+		openLogs: func(ctx context.Context, client corev1client.CoreV1Interface, namespace, name string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+			// stop after the first container
+			cancel()
+			return ioutil.NopCloser(strings.NewReader("log 1\n")), nil
+		},
+	}
+	client := fake.NewSimpleClientset(pod).CoreV1()
+	err = c.dump(ctx, client, "default", nil, nil, LogOptions{NoFollow: true}, w)
+	if expected, actual := context.Canceled, err; expected != actual {
+		t.Errorf("expected error %v, actually %v", expected, actual)
+	}
+	if expected, actual := "default/my-pod[function]: log 1\n", out.String(); expected != actual {
+		t.Errorf("expected output %q, actually %q", expected, actual)
+	}
+}
+
+func TestTimestampLogs(t *testing.T) {
+	queries := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries[r.URL.Path] = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	wrapped := false
+	rc := &rest.Config{
+		Host: server.URL,
+		WrapTransport: func(rt http.RoundTripper) http.RoundTripper {
+			wrapped = true
+			return rt
+		},
+	}
+	client := kubernetes.NewForConfigOrDie(timestampLogs(rc)).CoreV1()
+	logs, err := client.Pods("default").GetLogs("my-pod", &corev1.PodLogOptions{Container: "function"}).Stream()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	logs.Close()
+	if _, err := client.Pods("default").Get("my-pod", metav1.GetOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"/api/v1/namespaces/default/pods/my-pod/log": "container=function&timestamps=true",
+		"/api/v1/namespaces/default/pods/my-pod":     "",
+	}
+	if diff := cmp.Diff(expected, queries); diff != "" {
+		t.Errorf("unexpected queries (-expected, +actual): %s", diff)
+	}
+	if !wrapped {
+		t.Errorf("expected the existing transport wrapper to be called")
+	}
+	if _, ok := rc.WrapTransport(http.DefaultTransport).(*timestampsRoundTripper); ok {
+		t.Errorf("expected the config to be copied, not modified")
+	}
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kail
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/boz/kail"
+	"github.com/fatih/color"
+)
+
+const (
+	OutputJSON = "json"
+)
+
+var (
+	prefixColor = color.New(color.FgHiWhite, color.Bold)
+	// sourceColors are assigned in turn to each source when sources are colored
+	sourceColors = []*color.Color{
+		color.New(color.FgCyan, color.Bold),
+		color.New(color.FgGreen, color.Bold),
+		color.New(color.FgYellow, color.Bold),
+		color.New(color.FgMagenta, color.Bold),
+		color.New(color.FgBlue, color.Bold),
+		color.New(color.FgRed, color.Bold),
+	}
+)
+
+// writer prints log events from kail. The human readable format matches kail's writer, with
+// optional timestamps.
+type writer struct {
+	out        io.Writer
+	grep       *regexp.Regexp
+	output     string
+	timestamps bool
+	now        func() time.Time
+	// sources maps each source prefix to its color, nil when sources are not colored
+	sources map[string]*color.Color
+}
+
+// logLine is the structure of each log event when written as json
+type logLine struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	Timestamp string `json:"timestamp"`
+	Message   string `json:"message"`
+}
+
+func newWriter(out io.Writer, opts LogOptions) (*writer, error) {
+	w := &writer{
+		out:        out,
+		output:     opts.Output,
+		timestamps: opts.Timestamps,
+		now:        time.Now,
+	}
+	switch opts.Output {
+	case "", OutputJSON:
+	default:
+		return nil, fmt.Errorf("unknown log output format %q", opts.Output)
+	}
+	if opts.Grep != "" {
+		grep, err := regexp.Compile(opts.Grep)
+		if err != nil {
+			return nil, err
+		}
+		w.grep = grep
+	}
+	return w, nil
+}
+
+func (w *writer) Print(ev kail.Event) error {
+	log := bytes.TrimSuffix(ev.Log(), []byte("\n"))
+	written, log := splitTimestamp(log)
+	if w.grep != nil && !w.grep.Match(log) {
+		return nil
+	}
+	if written.IsZero() {
+		// the server did not prefix the line with a timestamp, use the time it was received
+		written = w.now()
+	}
+	timestamp := written.UTC().Format(time.RFC3339Nano)
+
+	if w.output == OutputJSON {
+		b, err := json.Marshal(logLine{
+			Namespace: ev.Source().Namespace(),
+			Pod:       ev.Source().Name(),
+			Container: ev.Source().Container(),
+			Timestamp: timestamp,
+			Message:   string(log),
+		})
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w.out, "%s\n", b)
+		return err
+	}
+
+	if w.timestamps {
+		if _, err := fmt.Fprintf(w.out, "%s ", timestamp); err != nil {
+			return err
+		}
+	}
+	prefix := fmt.Sprintf("%s/%s[%s]: ", ev.Source().Namespace(), ev.Source().Name(), ev.Source().Container())
+	if _, err := w.prefixColor(prefix).Fprint(w.out, prefix); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w.out, "%s\n", log)
+	return err
+}
+
+// splitTimestamp separates the RFC3339 timestamp the server prefixes to each log line from the
+// message. The time is zero when the line has no timestamp.
+func splitTimestamp(log []byte) (time.Time, []byte) {
+	end := bytes.IndexByte(log, ' ')
+	if end < 0 {
+		end = len(log)
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, string(log[:end]))
+	if err != nil {
+		return time.Time{}, log
+	}
+	if end == len(log) {
+		return timestamp, []byte{}
+	}
+	return timestamp, log[end+1:]
+}
+
+// colorSources prints the prefix for each source in a different color, making interleaved logs
+// from many pods easier to follow
+func (w *writer) colorSources() {
+	w.sources = map[string]*color.Color{}
+}
+
+func (w *writer) prefixColor(prefix string) *color.Color {
+	if w.sources == nil {
+		return prefixColor
+	}
+	c, ok := w.sources[prefix]
+	if !ok {
+		c = sourceColors[len(w.sources)%len(sourceColors)]
+		w.sources[prefix] = c
+	}
+	return c
+}
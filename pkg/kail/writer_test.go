@@ -0,0 +1,236 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kail
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/boz/kail"
+	"github.com/fatih/color"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriter(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	now := time.Date(2019, time.August, 20, 12, 30, 15, 123000000, time.UTC)
+	events := []kail.Event{
+		&testEvent{namespace: "default", pod: "my-pod", container: "function", log: "hello\n"},
+		&testEvent{namespace: "default", pod: "my-pod", container: "processor", log: "world"},
+		&testEvent{namespace: "default", pod: "my-pod", container: "function", log: `say "hi"` + "\n"},
+	}
+
+	tests := []struct {
+		name     string
+		opts     LogOptions
+		expected string
+		err      error
+	}{{
+		name: "human readable",
+		opts: LogOptions{},
+		expected: `default/my-pod[function]: hello
+default/my-pod[processor]: world
+default/my-pod[function]: say "hi"
+`,
+	}, {
+		name: "timestamps",
+		opts: LogOptions{Timestamps: true},
+		expected: `2019-08-20T12:30:15.123Z default/my-pod[function]: hello
+2019-08-20T12:30:15.123Z default/my-pod[processor]: world
+2019-08-20T12:30:15.123Z default/my-pod[function]: say "hi"
+`,
+	}, {
+		name: "grep",
+		opts: LogOptions{Grep: "^h"},
+		expected: `default/my-pod[function]: hello
+`,
+	}, {
+		name: "json",
+		opts: LogOptions{Output: OutputJSON},
+		expected: `{"namespace":"default","pod":"my-pod","container":"function","timestamp":"2019-08-20T12:30:15.123Z","message":"hello"}
+{"namespace":"default","pod":"my-pod","container":"processor","timestamp":"2019-08-20T12:30:15.123Z","message":"world"}
+{"namespace":"default","pod":"my-pod","container":"function","timestamp":"2019-08-20T12:30:15.123Z","message":"say \"hi\""}
+`,
+	}, {
+		name: "json, grep",
+		opts: LogOptions{Output: OutputJSON, Grep: "o"},
+		expected: `{"namespace":"default","pod":"my-pod","container":"function","timestamp":"2019-08-20T12:30:15.123Z","message":"hello"}
+{"namespace":"default","pod":"my-pod","container":"processor","timestamp":"2019-08-20T12:30:15.123Z","message":"world"}
+`,
+	}, {
+		name: "unknown output",
+		opts: LogOptions{Output: "xml"},
+		err:  fmt.Errorf("unknown log output format %q", "xml"),
+	}, {
+		name: "invalid grep",
+		opts: LogOptions{Grep: "("},
+		err:  fmt.Errorf("error parsing regexp: missing closing ): `(`"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			w, err := newWriter(out, test.opts)
+			if expected, actual := fmt.Sprintf("%v", test.err), fmt.Sprintf("%v", err); expected != actual {
+				t.Fatalf("expected error %q, actually %q", expected, actual)
+			}
+			if err != nil {
+				return
+			}
+			w.now = func() time.Time { return now }
+			for _, ev := range events {
+				if err := w.Print(ev); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if diff := cmp.Diff(test.expected, out.String()); diff != "" {
+				t.Errorf("unexpected output (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
+
+func TestWriter_ServerTimestamps(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	now := time.Date(2019, time.August, 20, 12, 30, 15, 123000000, time.UTC)
+	events := []kail.Event{
+		&testEvent{namespace: "default", pod: "my-pod", container: "function", log: "2019-08-20T11:00:00.000000001Z hello\n"},
+		&testEvent{namespace: "default", pod: "my-pod", container: "function", log: "2019-08-20T11:00:01Z\n"},
+		&testEvent{namespace: "default", pod: "my-pod", container: "function", log: "not a timestamp\n"},
+	}
+
+	tests := []struct {
+		name     string
+		opts     LogOptions
+		expected string
+	}{{
+		name: "human readable",
+		opts: LogOptions{},
+		expected: `default/my-pod[function]: hello
+default/my-pod[function]: 
+default/my-pod[function]: not a timestamp
+`,
+	}, {
+		name: "timestamps",
+		opts: LogOptions{Timestamps: true},
+		expected: `2019-08-20T11:00:00.000000001Z default/my-pod[function]: hello
+2019-08-20T11:00:01Z default/my-pod[function]: 
+2019-08-20T12:30:15.123Z default/my-pod[function]: not a timestamp
+`,
+	}, {
+		name: "grep",
+		opts: LogOptions{Grep: "^h"},
+		expected: `default/my-pod[function]: hello
+`,
+	}, {
+		name: "json",
+		opts: LogOptions{Output: OutputJSON},
+		expected: `{"namespace":"default","pod":"my-pod","container":"function","timestamp":"2019-08-20T11:00:00.000000001Z","message":"hello"}
+{"namespace":"default","pod":"my-pod","container":"function","timestamp":"2019-08-20T11:00:01Z","message":""}
+{"namespace":"default","pod":"my-pod","container":"function","timestamp":"2019-08-20T12:30:15.123Z","message":"not a timestamp"}
+`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			w, err := newWriter(out, test.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			w.now = func() time.Time { return now }
+			for _, ev := range events {
+				if err := w.Print(ev); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if diff := cmp.Diff(test.expected, out.String()); diff != "" {
+				t.Errorf("unexpected output (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
+
+func TestWriter_ColorSources(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	events := []kail.Event{
+		&testEvent{namespace: "default", pod: "my-pod", container: "function", log: "hello"},
+		&testEvent{namespace: "default", pod: "my-other-pod", container: "function", log: "world"},
+		&testEvent{namespace: "default", pod: "my-pod", container: "function", log: "again"},
+	}
+
+	out := &bytes.Buffer{}
+	w, err := newWriter(out, LogOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w.colorSources()
+	for _, ev := range events {
+		if err := w.Print(ev); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	expected := sourceColors[0].Sprint("default/my-pod[function]: ") + "hello\n" +
+		sourceColors[1].Sprint("default/my-other-pod[function]: ") + "world\n" +
+		sourceColors[0].Sprint("default/my-pod[function]: ") + "again\n"
+	if diff := cmp.Diff(expected, out.String()); diff != "" {
+		t.Errorf("unexpected output (-expected, +actual): %s", diff)
+	}
+}
+
+type testEvent struct {
+	namespace string
+	pod       string
+	container string
+	log       string
+}
+
+func (e *testEvent) Source() kail.EventSource {
+	return e
+}
+
+func (e *testEvent) Log() []byte {
+	return []byte(e.log)
+}
+
+func (e *testEvent) Namespace() string {
+	return e.namespace
+}
+
+func (e *testEvent) Name() string {
+	return e.pod
+}
+
+func (e *testEvent) Container() string {
+	return e.container
+}
+
+func (e *testEvent) Node() string {
+	return ""
+}
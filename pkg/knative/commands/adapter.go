@@ -39,6 +39,8 @@ the image for an existing resource.
 		Aliases: []string{"adapters"},
 	}
 
+	cli.CompleteNameArgs(cmd, cli.KnativeAdaptersCompletion)
+
 	cmd.AddCommand(NewAdapterListCommand(ctx, c))
 	cmd.AddCommand(NewAdapterCreateCommand(ctx, c))
 	cmd.AddCommand(NewAdapterDeleteCommand(ctx, c))
@@ -28,6 +28,7 @@ import (
 	knativev1alpha1 "github.com/projectriff/system/pkg/apis/knative/v1alpha1"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 type AdapterCreateOptions struct {
@@ -50,6 +51,7 @@ var (
 	_ cli.Validatable = (*AdapterCreateOptions)(nil)
 	_ cli.Executable  = (*AdapterCreateOptions)(nil)
 	_ cli.DryRunable  = (*AdapterCreateOptions)(nil)
+	_ cli.Promptable  = (*AdapterCreateOptions)(nil)
 )
 
 func (opts *AdapterCreateOptions) Validate(ctx context.Context) *cli.FieldError {
@@ -159,7 +161,15 @@ func (opts *AdapterCreateOptions) Exec(ctx context.Context, c *cli.Config) error
 	}
 
 	if opts.DryRun {
-		cli.DryRunResource(ctx, adapter, adapter.GetGroupVersionKind())
+		get := func() (runtime.Object, error) {
+			return c.KnativeRuntime().Adapters(opts.Namespace).Get(adapter.Name, metav1.GetOptions{})
+		}
+		create := func() (runtime.Object, error) {
+			return k8s.DryRunCreate(c.KnativeRuntime().RESTClient(), "adapters", adapter)
+		}
+		if err := cli.DryRunCreate(ctx, c, adapter, adapter.GetGroupVersionKind(), get, create); err != nil {
+			return err
+		}
 	} else {
 		var err error
 		adapter, err = c.KnativeRuntime().Adapters(opts.Namespace).Create(adapter)
@@ -188,6 +198,13 @@ func (opts *AdapterCreateOptions) Exec(ctx context.Context, c *cli.Config) error
 	return nil
 }
 
+func (opts *AdapterCreateOptions) Prompt(ctx context.Context, c *cli.Config, p *cli.Prompter) error {
+	if err := p.OneOf(cli.ApplicationRefFlagName, cli.ContainerRefFlagName, cli.FunctionRefFlagName); err != nil {
+		return err
+	}
+	return p.OneOf(cli.ConfigurationRefFlagName, cli.ServiceRefFlagName)
+}
+
 func (opts *AdapterCreateOptions) IsDryRun() bool {
 	return opts.DryRun
 }
@@ -216,6 +233,7 @@ the image for an existing resource.
 		cli.NameArg(&opts.Name),
 	)
 
+	cli.InteractiveFlag(ctx, cmd, c, opts)
 	cli.NamespaceFlag(cmd, c, &opts.Namespace)
 	cmd.Flags().StringVar(&opts.ApplicationRef, cli.StripDash(cli.ApplicationRefFlagName), "", "`name` of application to deploy")
 	cmd.Flags().StringVar(&opts.ContainerRef, cli.StripDash(cli.ContainerRefFlagName), "", "`name` of container to deploy")
@@ -224,7 +242,11 @@ the image for an existing resource.
 	cmd.Flags().StringVar(&opts.ServiceRef, cli.StripDash(cli.ServiceRefFlagName), "", "`name` of Knative service to update")
 	cmd.Flags().BoolVar(&opts.Tail, cli.StripDash(cli.TailFlagName), false, "watch adapter logs")
 	cmd.Flags().StringVar(&opts.WaitTimeout, cli.StripDash(cli.WaitTimeoutFlagName), "10m", "`duration` to wait for the adapter to become ready when watching logs")
-	cmd.Flags().BoolVar(&opts.DryRun, cli.StripDash(cli.DryRunFlagName), false, "print kubernetes resources to stdout rather than apply them to the cluster, messages normally on stdout will be sent to stderr")
+	cli.DryRunFlags(cmd, c, &opts.DryRun)
+
+	cli.CompleteFlagResource(cmd, cli.ApplicationRefFlagName, cli.ApplicationsCompletion)
+	cli.CompleteFlagResource(cmd, cli.ContainerRefFlagName, cli.ContainersCompletion)
+	cli.CompleteFlagResource(cmd, cli.FunctionRefFlagName, cli.FunctionsCompletion)
 
 	return cmd
 }
@@ -44,6 +44,8 @@ The hostname to access the deployer is available in the deployer listing.
 		Aliases: []string{"deployers"},
 	}
 
+	cli.CompleteNameArgs(cmd, cli.KnativeDeployersCompletion)
+
 	cmd.AddCommand(NewDeployerListCommand(ctx, c))
 	cmd.AddCommand(NewDeployerCreateCommand(ctx, c))
 	cmd.AddCommand(NewDeployerDeleteCommand(ctx, c))
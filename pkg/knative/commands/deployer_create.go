@@ -24,6 +24,7 @@ import (
 
 	"github.com/projectriff/cli/pkg/cli"
 	"github.com/projectriff/cli/pkg/k8s"
+	"github.com/projectriff/cli/pkg/kail"
 	"github.com/projectriff/cli/pkg/parsers"
 	"github.com/projectriff/cli/pkg/race"
 	"github.com/projectriff/cli/pkg/validation"
@@ -31,6 +32,7 @@ import (
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 type DeployerCreateOptions struct {
@@ -54,6 +56,7 @@ var (
 	_ cli.Validatable = (*DeployerCreateOptions)(nil)
 	_ cli.Executable  = (*DeployerCreateOptions)(nil)
 	_ cli.DryRunable  = (*DeployerCreateOptions)(nil)
+	_ cli.Promptable  = (*DeployerCreateOptions)(nil)
 )
 
 func (opts *DeployerCreateOptions) Validate(ctx context.Context) *cli.FieldError {
@@ -85,6 +88,7 @@ func (opts *DeployerCreateOptions) Validate(ctx context.Context) *cli.FieldError
 
 	if opts.Image != "" {
 		used = append(used, cli.ImageFlagName)
+		errs = errs.Also(validation.ImageRef(opts.Image, cli.ImageFlagName))
 	} else {
 		unused = append(unused, cli.ImageFlagName)
 	}
@@ -159,7 +163,15 @@ func (opts *DeployerCreateOptions) Exec(ctx context.Context, c *cli.Config) erro
 	}
 
 	if opts.DryRun {
-		cli.DryRunResource(ctx, deployer, deployer.GetGroupVersionKind())
+		get := func() (runtime.Object, error) {
+			return c.KnativeRuntime().Deployers(opts.Namespace).Get(deployer.Name, metav1.GetOptions{})
+		}
+		create := func() (runtime.Object, error) {
+			return k8s.DryRunCreate(c.KnativeRuntime().RESTClient(), "deployers", deployer)
+		}
+		if err := cli.DryRunCreate(ctx, c, deployer, deployer.GetGroupVersionKind(), get, create); err != nil {
+			return err
+		}
 	} else {
 		var err error
 		deployer, err = c.KnativeRuntime().Deployers(opts.Namespace).Create(deployer)
@@ -176,7 +188,7 @@ func (opts *DeployerCreateOptions) Exec(ctx context.Context, c *cli.Config) erro
 				return k8s.WaitUntilReady(ctx, c.KnativeRuntime().RESTClient(), "deployers", deployer)
 			},
 			func(ctx context.Context) error {
-				return c.Kail.KnativeDeployerLogs(ctx, deployer, cli.TailSinceCreateDefault, c.Stdout)
+				return c.Kail.KnativeDeployerLogs(ctx, deployer, kail.LogOptions{Since: cli.TailSinceCreateDefault}, c.Stdout)
 			},
 		)
 		if err == context.DeadlineExceeded {
@@ -192,6 +204,10 @@ func (opts *DeployerCreateOptions) Exec(ctx context.Context, c *cli.Config) erro
 	return nil
 }
 
+func (opts *DeployerCreateOptions) Prompt(ctx context.Context, c *cli.Config, p *cli.Prompter) error {
+	return p.OneOf(cli.ApplicationRefFlagName, cli.ContainerRefFlagName, cli.FunctionRefFlagName, cli.ImageFlagName)
+}
+
 func (opts *DeployerCreateOptions) IsDryRun() bool {
 	return opts.DryRun
 }
@@ -226,6 +242,7 @@ and ` + cli.EnvFromFlagName + ` to map values from a ConfigMap or Secret.
 		cli.NameArg(&opts.Name),
 	)
 
+	cli.InteractiveFlag(ctx, cmd, c, opts)
 	cli.NamespaceFlag(cmd, c, &opts.Namespace)
 	cmd.Flags().StringVar(&opts.Image, cli.StripDash(cli.ImageFlagName), "", "container `image` to deploy")
 	cmd.Flags().StringVar(&opts.ApplicationRef, cli.StripDash(cli.ApplicationRefFlagName), "", "`name` of application to deploy")
@@ -235,7 +252,11 @@ and ` + cli.EnvFromFlagName + ` to map values from a ConfigMap or Secret.
 	cmd.Flags().StringArrayVar(&opts.EnvFrom, cli.StripDash(cli.EnvFromFlagName), []string{}, fmt.Sprintf("environment `variable` from a config map or secret, example %q, %q (may be set multiple times)", fmt.Sprintf("%s MY_SECRET_VALUE=secretKeyRef:my-secret-name:key-in-secret", cli.EnvFromFlagName), fmt.Sprintf("%s MY_CONFIG_MAP_VALUE=configMapKeyRef:my-config-map-name:key-in-config-map", cli.EnvFromFlagName)))
 	cmd.Flags().BoolVar(&opts.Tail, cli.StripDash(cli.TailFlagName), false, "watch deployer logs")
 	cmd.Flags().StringVar(&opts.WaitTimeout, cli.StripDash(cli.WaitTimeoutFlagName), "10m", "`duration` to wait for the deployer to become ready when watching logs")
-	cmd.Flags().BoolVar(&opts.DryRun, cli.StripDash(cli.DryRunFlagName), false, "print kubernetes resources to stdout rather than apply them to the cluster, messages normally on stdout will be sent to stderr")
+	cli.DryRunFlags(cmd, c, &opts.DryRun)
+
+	cli.CompleteFlagResource(cmd, cli.ApplicationRefFlagName, cli.ApplicationsCompletion)
+	cli.CompleteFlagResource(cmd, cli.ContainerRefFlagName, cli.ContainersCompletion)
+	cli.CompleteFlagResource(cmd, cli.FunctionRefFlagName, cli.FunctionsCompletion)
 
 	return cmd
 }
@@ -20,7 +20,6 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/projectriff/cli/pkg/cli"
 	"github.com/spf13/cobra"
@@ -30,7 +29,7 @@ import (
 type DeployerTailOptions struct {
 	cli.ResourceOptions
 
-	Since string
+	cli.TailOptions
 }
 
 var (
@@ -43,11 +42,7 @@ func (opts *DeployerTailOptions) Validate(ctx context.Context) *cli.FieldError {
 
 	errs = errs.Also(opts.ResourceOptions.Validate(ctx))
 
-	if opts.Since != "" {
-		if _, err := time.ParseDuration(opts.Since); err != nil {
-			errs = errs.Also(cli.ErrInvalidValue(opts.Since, cli.SinceFlagName))
-		}
-	}
+	errs = errs.Also(opts.TailOptions.Validate(ctx))
 
 	return errs
 }
@@ -57,12 +52,7 @@ func (opts *DeployerTailOptions) Exec(ctx context.Context, c *cli.Config) error
 	if err != nil {
 		return err
 	}
-	since := cli.TailSinceDefault
-	if opts.Since != "" {
-		// error is protected by Validate()
-		since, _ = time.ParseDuration(opts.Since)
-	}
-	return c.Kail.KnativeDeployerLogs(ctx, deployer, since, c.Stdout)
+	return c.Kail.KnativeDeployerLogs(ctx, deployer, opts.TailOptions.LogOptions(), c.Stdout)
 }
 
 func NewDeployerTailCommand(ctx context.Context, c *cli.Config) *cobra.Command {
@@ -77,10 +67,17 @@ shell or kill the process.
 
 As new deployer pods are started, the logs are displayed. To show historical logs
 use ` + cli.SinceFlagName + `.
+
+Log lines are written for people to read by default. Use ` + cli.OutputFlagName + ` json to
+write one JSON object per line instead, for processing by other tools.
+
+Use ` + cli.NoFollowFlagName + ` to read existing logs and exit, or ` + cli.PreviousFlagName + ` to read
+the logs of containers from before they last restarted.
 `),
 		Example: strings.Join([]string{
 			fmt.Sprintf("%s knative deployer tail my-deployer", c.Name),
 			fmt.Sprintf("%s knative deployer tail my-deployer %s 1h", c.Name, cli.SinceFlagName),
+			fmt.Sprintf("%s knative deployer tail my-deployer %s json %s error", c.Name, cli.OutputFlagName, cli.GrepFlagName),
 		}, "\n"),
 		PreRunE: cli.ValidateOptions(ctx, opts),
 		RunE:    cli.ExecOptions(ctx, c, opts),
@@ -91,7 +88,7 @@ use ` + cli.SinceFlagName + `.
 	)
 
 	cli.NamespaceFlag(cmd, c, &opts.Namespace)
-	cmd.Flags().StringVar(&opts.Since, cli.StripDash(cli.SinceFlagName), "", "time `duration` to start reading logs from")
+	cli.TailFlags(cmd, &opts.TailOptions)
 
 	return cmd
 }
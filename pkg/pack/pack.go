@@ -19,6 +19,7 @@ package pack
 import (
 	"context"
 	"io"
+	"io/ioutil"
 
 	"github.com/buildpack/pack"
 	"github.com/buildpack/pack/logging"
@@ -30,7 +31,28 @@ type Client interface {
 	Build(ctx context.Context, opts pack.BuildOptions) error
 }
 
-func NewClient(stdout io.Writer) (Client, error) {
-	logger := logging.New(stdout)
+// NewClient creates a pack client logging to stdout. Pack's debug logs are
+// written to debug, or discarded when debug is nil.
+func NewClient(stdout io.Writer, debug io.Writer) (Client, error) {
+	if debug == nil {
+		debug = ioutil.Discard
+	}
+	logger := &logger{
+		Logger: logging.New(stdout),
+		debug:  logging.New(debug),
+	}
 	return pack.NewClient(pack.WithLogger(logger))
 }
+
+type logger struct {
+	logging.Logger
+	debug logging.Logger
+}
+
+func (l *logger) Debug(msg string) {
+	l.debug.Debug(msg)
+}
+
+func (l *logger) Debugf(format string, v ...interface{}) {
+	l.debug.Debugf(format, v...)
+}
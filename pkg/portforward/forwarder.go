@@ -0,0 +1,96 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package portforward
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/projectriff/cli/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+type Forwarder interface {
+	PortForward(ctx context.Context, pod *corev1.Pod, localPort, remotePort int32, out, errOut io.Writer) error
+}
+
+func NewDefault(k8s k8s.Client) Forwarder {
+	f := &forwarder{
+		k8s: k8s,
+	}
+	f.dialer = f.spdyDialer
+	return f
+}
+
+type forwarder struct {
+	k8s k8s.Client
+	// dialer opens connections to the port forward subresource of the pod
+	dialer func(pod *corev1.Pod) (httpstream.Dialer, error)
+}
+
+// PortForward opens a SPDY connection to the pod, forwarding connections on the local port to
+// the remote port until the context is done. Progress is written to out and errors forwarding
+// connections to errOut.
+func (f *forwarder) PortForward(ctx context.Context, pod *corev1.Pod, localPort, remotePort int32, out, errOut io.Writer) error {
+	dialer, err := f.dialer(pod)
+	if err != nil {
+		return err
+	}
+
+	stopCh := make(chan struct{})
+	readyCh := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			close(stopCh)
+		case <-done:
+		}
+	}()
+
+	ports := []string{fmt.Sprintf("%d:%d", localPort, remotePort)}
+	pf, err := portforward.New(dialer, ports, stopCh, readyCh, out, errOut)
+	if err != nil {
+		return err
+	}
+	return pf.ForwardPorts()
+}
+
+func (f *forwarder) spdyDialer(pod *corev1.Pod) (httpstream.Dialer, error) {
+	transport, upgrader, err := spdy.RoundTripperFor(f.k8s.KubeRestConfig())
+	if err != nil {
+		return nil, err
+	}
+	return spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, f.url(pod)), nil
+}
+
+// url is the port forward subresource of the pod
+func (f *forwarder) url(pod *corev1.Pod) *url.URL {
+	return f.k8s.Core().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("portforward").
+		URL()
+}
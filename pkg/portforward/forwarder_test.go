@@ -0,0 +1,283 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package portforward
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/projectriff/cli/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	corev1clientset "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+func TestForwarder_URL(t *testing.T) {
+	config := &rest.Config{Host: "https://localhost:8443"}
+	f := NewDefault(&client{config: config}).(*forwarder)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "my-namespace",
+			Name:      "my-pod",
+		},
+	}
+
+	if expected, actual := "https://localhost:8443/api/v1/namespaces/my-namespace/pods/my-pod/portforward", f.url(pod).String(); expected != actual {
+		t.Errorf("expected url %q, actually %q", expected, actual)
+	}
+	if _, err := f.dialer(pod); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestForwarder_PortForward(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "my-namespace",
+			Name:      "my-pod",
+		},
+	}
+
+	tests := []struct {
+		name          string
+		localPort     int32
+		remotePort    int32
+		dialerErr     error
+		dialErr       error
+		expectedError string
+	}{{
+		name:          "invalid local port",
+		localPort:     -1,
+		remotePort:    8080,
+		expectedError: "Error parsing local port '-1'",
+	}, {
+		name:          "invalid remote port",
+		localPort:     8080,
+		remotePort:    0,
+		expectedError: "Remote port must be > 0",
+	}, {
+		name:          "dialer error",
+		localPort:     8080,
+		remotePort:    8080,
+		dialerErr:     fmt.Errorf("no config"),
+		expectedError: "no config",
+	}, {
+		name:          "dial error",
+		localPort:     8080,
+		remotePort:    8080,
+		dialErr:       fmt.Errorf("forbidden"),
+		expectedError: "error upgrading connection: forbidden",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dialer := &fakeDialer{err: test.dialErr}
+			f := &forwarder{
+				dialer: func(actual *corev1.Pod) (httpstream.Dialer, error) {
+					if actual != pod {
+						t.Errorf("expected dialer for pod %q, actually %q", pod.Name, actual.Name)
+					}
+					return dialer, test.dialerErr
+				},
+			}
+
+			err := f.PortForward(context.TODO(), pod, test.localPort, test.remotePort, ioutil.Discard, ioutil.Discard)
+			if err == nil {
+				t.Fatalf("expected error %q", test.expectedError)
+			}
+			if !strings.Contains(err.Error(), test.expectedError) {
+				t.Errorf("expected error %q, actually %q", test.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestForwarder_PortForward_Connection(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "my-namespace",
+			Name:      "my-pod",
+		},
+	}
+	localPort := freePort(t)
+	conn := &fakeConnection{closeCh: make(chan bool)}
+	f := &forwarder{
+		dialer: func(pod *corev1.Pod) (httpstream.Dialer, error) {
+			return &fakeDialer{conn: conn}, nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- f.PortForward(ctx, pod, localPort, 8080, ioutil.Discard, ioutil.Discard)
+	}()
+
+	// the data stream echoes what is sent to the remote port
+	local := dialLocal(t, localPort)
+	if _, err := local.Write([]byte("ping")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b := make([]byte, 4)
+	if _, err := io.ReadFull(local, b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	local.Close()
+	if expected, actual := "ping", string(b); expected != actual {
+		t.Errorf("expected %q, actually %q", expected, actual)
+	}
+	if expected, actual := "8080", conn.port(); expected != actual {
+		t.Errorf("expected remote port %q, actually %q", expected, actual)
+	}
+
+	cancel()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected port forward to stop when the context is done")
+	}
+}
+
+func freePort(t *testing.T) int32 {
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer listener.Close()
+	return int32(listener.Addr().(*net.TCPAddr).Port)
+}
+
+// dialLocal connects to the local port, retrying until the forwarder is listening
+func dialLocal(t *testing.T, port int32) net.Conn {
+	var err error
+	for i := 0; i < 50; i++ {
+		var conn net.Conn
+		conn, err = net.Dial("tcp4", fmt.Sprintf("127.0.0.1:%d", port))
+		if err == nil {
+			return conn
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf("unable to connect to local port %d: %v", port, err)
+	return nil
+}
+
+type client struct {
+	k8s.Client
+	config *rest.Config
+}
+
+func (c *client) KubeRestConfig() *rest.Config {
+	return c.config
+}
+
+func (c *client) Core() corev1clientset.CoreV1Interface {
+	return corev1clientset.NewForConfigOrDie(c.config)
+}
+
+type fakeDialer struct {
+	conn *fakeConnection
+	err  error
+}
+
+func (d *fakeDialer) Dial(protocols ...string) (httpstream.Connection, string, error) {
+	if d.err != nil {
+		return nil, "", d.err
+	}
+	return d.conn, protocols[0], nil
+}
+
+type fakeConnection struct {
+	m       sync.Mutex
+	headers []http.Header
+	closeCh chan bool
+}
+
+func (c *fakeConnection) CreateStream(headers http.Header) (httpstream.Stream, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.headers = append(c.headers, headers)
+	if headers.Get(corev1.StreamType) == corev1.StreamTypeError {
+		// no errors are reported
+		return &fakeStream{Reader: &bytes.Buffer{}, headers: headers}, nil
+	}
+	r, w := io.Pipe()
+	return &fakeStream{Reader: r, Writer: w, headers: headers}, nil
+}
+
+func (c *fakeConnection) port() string {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if len(c.headers) == 0 {
+		return ""
+	}
+	return c.headers[0].Get(corev1.PortHeader)
+}
+
+func (c *fakeConnection) Close() error {
+	return nil
+}
+
+func (c *fakeConnection) CloseChan() <-chan bool {
+	return c.closeCh
+}
+
+func (c *fakeConnection) SetIdleTimeout(timeout time.Duration) {}
+
+// fakeStream reads back what is written to it
+type fakeStream struct {
+	io.Reader
+	Writer  *io.PipeWriter
+	headers http.Header
+}
+
+func (s *fakeStream) Write(p []byte) (int, error) {
+	return s.Writer.Write(p)
+}
+
+func (s *fakeStream) Close() error {
+	if s.Writer == nil {
+		return nil
+	}
+	return s.Writer.Close()
+}
+
+func (s *fakeStream) Reset() error {
+	return s.Close()
+}
+
+func (s *fakeStream) Headers() http.Header {
+	return s.headers
+}
+
+func (s *fakeStream) Identifier() uint32 {
+	return 0
+}
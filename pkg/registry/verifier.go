@@ -0,0 +1,299 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package registry
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	ReasonUnauthorized = "unauthorized"
+	ReasonUnreachable  = "unreachable"
+	ReasonTLS          = "tls failure"
+	ReasonUnsupported  = "unsupported"
+	ReasonUnverifiable = "not verifiable"
+)
+
+// Verifier checks credentials against a container registry.
+type Verifier interface {
+	Verify(ctx context.Context, registry, username, password string) error
+}
+
+// VerifyError describes why credentials could not be verified for a registry.
+type VerifyError struct {
+	Registry string
+	Reason   string
+	Err      error
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("unable to verify credentials for registry %q, %s: %v", e.Registry, e.Reason, e.Err)
+}
+
+func (e *VerifyError) Unwrap() error {
+	return e.Err
+}
+
+// IsUnauthorized returns true if the registry rejected the credentials.
+func IsUnauthorized(err error) bool {
+	return hasReason(err, ReasonUnauthorized)
+}
+
+// IsUnreachable returns true if the registry could not be contacted.
+func IsUnreachable(err error) bool {
+	return hasReason(err, ReasonUnreachable)
+}
+
+// IsUnverifiable returns true if the registry accepted the request without
+// checking the credentials.
+func IsUnverifiable(err error) bool {
+	return hasReason(err, ReasonUnverifiable)
+}
+
+// IsTLSFailure returns true if a secure connection to the registry could not
+// be established.
+func IsTLSFailure(err error) bool {
+	return hasReason(err, ReasonTLS)
+}
+
+func hasReason(err error, reason string) bool {
+	verr := &VerifyError{}
+	return errors.As(err, &verr) && verr.Reason == reason
+}
+
+func NewDefault() Verifier {
+	return NewVerifier(&http.Client{
+		Timeout: 30 * time.Second,
+	})
+}
+
+func NewVerifier(client *http.Client) Verifier {
+	return &verifier{
+		client: client,
+	}
+}
+
+type verifier struct {
+	client *http.Client
+}
+
+// Verify performs the Docker Registry v2 auth handshake. The registry's base
+// endpoint is probed anonymously and the credentials are presented as basic
+// auth, or exchanged for a bearer token, as requested by the challenge. A
+// registry that allows anonymous access is asked again with the credentials,
+// as it only challenges credentials it rejects.
+func (v *verifier) Verify(ctx context.Context, registry, username, password string) error {
+	endpoint, err := registryEndpoint(registry)
+	if err != nil {
+		return &VerifyError{Registry: registry, Reason: ReasonUnsupported, Err: err}
+	}
+
+	res, err := v.get(ctx, endpoint, nil)
+	if err != nil {
+		return connectionError(registry, err)
+	}
+	res.Body.Close()
+	if res.StatusCode == http.StatusOK {
+		// the registry allows anonymous access
+		res, err = v.get(ctx, endpoint, basicAuth(username, password))
+		if err != nil {
+			return connectionError(registry, err)
+		}
+		res.Body.Close()
+		if res.StatusCode == http.StatusOK {
+			return &VerifyError{Registry: registry, Reason: ReasonUnverifiable, Err: fmt.Errorf("the registry allows anonymous access and did not check the username and password")}
+		}
+		if scheme, _ := parseChallenge(res.Header.Get("WWW-Authenticate")); res.StatusCode != http.StatusUnauthorized || scheme != "bearer" {
+			// basic auth was rejected
+			return statusError(registry, endpoint, res.StatusCode)
+		}
+		// continue with the bearer challenge
+	} else if res.StatusCode != http.StatusUnauthorized {
+		return &VerifyError{Registry: registry, Reason: ReasonUnsupported, Err: fmt.Errorf("unexpected status %d from %s", res.StatusCode, endpoint)}
+	}
+
+	scheme, params := parseChallenge(res.Header.Get("WWW-Authenticate"))
+	var header http.Header
+	switch scheme {
+	case "basic":
+		header = basicAuth(username, password)
+	case "bearer":
+		token, err := v.token(ctx, registry, params, username, password)
+		if err != nil {
+			return err
+		}
+		header = http.Header{"Authorization": []string{"Bearer " + token}}
+	default:
+		return &VerifyError{Registry: registry, Reason: ReasonUnsupported, Err: fmt.Errorf("unsupported auth challenge %q", res.Header.Get("WWW-Authenticate"))}
+	}
+
+	res, err = v.get(ctx, endpoint, header)
+	if err != nil {
+		return connectionError(registry, err)
+	}
+	res.Body.Close()
+	return statusError(registry, endpoint, res.StatusCode)
+}
+
+// token exchanges the credentials for a bearer token with the realm from the
+// challenge.
+func (v *verifier) token(ctx context.Context, registry string, params map[string]string, username, password string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", &VerifyError{Registry: registry, Reason: ReasonUnsupported, Err: fmt.Errorf("invalid token realm %q", params["realm"])}
+	}
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	if scope := params["scope"]; scope != "" {
+		query.Set("scope", scope)
+	}
+	query.Set("account", username)
+	realm.RawQuery = query.Encode()
+
+	res, err := v.get(ctx, realm.String(), basicAuth(username, password))
+	if err != nil {
+		return "", connectionError(registry, err)
+	}
+	defer res.Body.Close()
+	if err := statusError(registry, realm.String(), res.StatusCode); err != nil {
+		return "", err
+	}
+
+	body := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", &VerifyError{Registry: registry, Reason: ReasonUnsupported, Err: fmt.Errorf("invalid token response from %s: %v", realm.Host, err)}
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", &VerifyError{Registry: registry, Reason: ReasonUnsupported, Err: fmt.Errorf("no token in response from %s", realm.Host)}
+}
+
+func (v *verifier) get(ctx context.Context, location string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	return v.client.Do(req.WithContext(ctx))
+}
+
+// basicAuth is the header presenting the username and password as basic auth.
+func basicAuth(username, password string) http.Header {
+	req := &http.Request{Header: http.Header{}}
+	req.SetBasicAuth(username, password)
+	return req.Header
+}
+
+// registryEndpoint returns the v2 api base endpoint for a registry as stored in
+// a credential. Registries without a scheme are assumed to be secure.
+func registryEndpoint(registry string) (string, error) {
+	if !strings.Contains(registry, "://") {
+		registry = "https://" + registry
+	}
+	u, err := url.Parse(registry)
+	if err != nil {
+		return "", err
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("missing registry host")
+	}
+	switch u.Host {
+	case "docker.io", "index.docker.io":
+		// docker hub credentials are keyed by the index, but served by the registry
+		u.Host = "registry-1.docker.io"
+	}
+	return fmt.Sprintf("%s://%s/v2/", u.Scheme, u.Host), nil
+}
+
+// parseChallenge splits a WWW-Authenticate header into the lower cased scheme
+// and its parameters.
+func parseChallenge(header string) (string, map[string]string) {
+	params := map[string]string{}
+	parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	scheme := strings.ToLower(parts[0])
+	if len(parts) == 1 {
+		return scheme, params
+	}
+	rest := parts[1]
+	for rest != "" {
+		eq := strings.Index(rest, "=")
+		if eq < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(rest[:eq]))
+		rest = strings.TrimSpace(rest[eq+1:])
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else if comma := strings.Index(rest, ","); comma >= 0 {
+			value, rest = rest[:comma], rest[comma:]
+		} else {
+			value, rest = rest, ""
+		}
+		params[key] = strings.TrimSpace(value)
+		rest = strings.TrimPrefix(strings.TrimSpace(rest), ",")
+		rest = strings.TrimSpace(rest)
+	}
+	return scheme, params
+}
+
+func statusError(registry, location string, status int) error {
+	switch status {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return &VerifyError{Registry: registry, Reason: ReasonUnauthorized, Err: fmt.Errorf("the registry rejected the username and password")}
+	default:
+		return &VerifyError{Registry: registry, Reason: ReasonUnsupported, Err: fmt.Errorf("unexpected status %d from %s", status, location)}
+	}
+}
+
+func connectionError(registry string, err error) error {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	var recordHeader tls.RecordHeaderError
+	if errors.As(err, &unknownAuthority) || errors.As(err, &hostname) || errors.As(err, &invalid) || errors.As(err, &recordHeader) {
+		return &VerifyError{Registry: registry, Reason: ReasonTLS, Err: err}
+	}
+	return &VerifyError{Registry: registry, Reason: ReasonUnreachable, Err: err}
+}
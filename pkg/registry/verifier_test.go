@@ -0,0 +1,236 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package registry_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/projectriff/cli/pkg/registry"
+)
+
+const (
+	username = "my-user"
+	password = "my-password"
+	token    = "my-token"
+)
+
+// basicRegistry stands in for a registry that accepts basic auth on the v2
+// endpoint.
+func basicRegistry() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if u, p, ok := r.BasicAuth(); ok && u == username && p == password {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+}
+
+// tokenRegistry stands in for a registry that delegates to a token server,
+// which is served from the same host.
+func tokenRegistry() *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			if r.Header.Get("Authorization") == "Bearer "+token {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry.example.com"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/token":
+			if r.URL.Query().Get("service") != "registry.example.com" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if u, p, ok := r.BasicAuth(); !ok || u != username || p != password {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, `{"token":%q}`, token)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+// anonymousRegistry stands in for a registry that allows anonymous reads. The
+// credentials are only checked when presented, as basic auth or as a bearer
+// token from the token server at realm when one is given.
+func anonymousRegistry(realm func() string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		auth := r.Header.Get("Authorization")
+		if auth == "" || auth == "Bearer "+token {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if realm != nil {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry.example.com"`, realm()))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if u, p, ok := r.BasicAuth(); ok && u == username && p == password {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+}
+
+func TestVerifier(t *testing.T) {
+	basic := httptest.NewServer(basicRegistry())
+	defer basic.Close()
+	bearer := tokenRegistry()
+	defer bearer.Close()
+	anonymous := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer anonymous.Close()
+	anonymousBasic := httptest.NewServer(anonymousRegistry(nil))
+	defer anonymousBasic.Close()
+	anonymousBearer := httptest.NewServer(anonymousRegistry(func() string { return bearer.URL }))
+	defer anonymousBearer.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+	secure := httptest.NewTLSServer(basicRegistry())
+	defer secure.Close()
+	closed := httptest.NewServer(basicRegistry())
+	closed.Close()
+
+	tests := []struct {
+		name     string
+		registry string
+		username string
+		password string
+		check    func(error) bool
+	}{{
+		name:     "basic auth",
+		registry: basic.URL,
+		username: username,
+		password: password,
+	}, {
+		name:     "basic auth unauthorized",
+		registry: basic.URL,
+		username: username,
+		password: "wrong-password",
+		check:    registry.IsUnauthorized,
+	}, {
+		name:     "bearer token",
+		registry: bearer.URL,
+		username: username,
+		password: password,
+	}, {
+		name:     "bearer token unauthorized",
+		registry: bearer.URL,
+		username: username,
+		password: "wrong-password",
+		check:    registry.IsUnauthorized,
+	}, {
+		name:     "anonymous",
+		registry: anonymous.URL,
+		username: username,
+		password: password,
+		check:    registry.IsUnverifiable,
+	}, {
+		name:     "anonymous basic auth",
+		registry: anonymousBasic.URL,
+		username: username,
+		password: password,
+		check:    registry.IsUnverifiable,
+	}, {
+		name:     "anonymous basic auth unauthorized",
+		registry: anonymousBasic.URL,
+		username: username,
+		password: "wrong-password",
+		check:    registry.IsUnauthorized,
+	}, {
+		name:     "anonymous bearer token",
+		registry: anonymousBearer.URL,
+		username: username,
+		password: password,
+	}, {
+		name:     "anonymous bearer token unauthorized",
+		registry: anonymousBearer.URL,
+		username: username,
+		password: "wrong-password",
+		check:    registry.IsUnauthorized,
+	}, {
+		name:     "unexpected status",
+		registry: broken.URL,
+		username: username,
+		password: password,
+		check: func(err error) bool {
+			return err != nil && !registry.IsUnauthorized(err) && !registry.IsUnreachable(err) && !registry.IsTLSFailure(err)
+		},
+	}, {
+		name:     "untrusted certificate",
+		registry: secure.URL,
+		username: username,
+		password: password,
+		check:    registry.IsTLSFailure,
+	}, {
+		name:     "unreachable",
+		registry: closed.URL,
+		username: username,
+		password: password,
+		check:    registry.IsUnreachable,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			verifier := registry.NewVerifier(&http.Client{})
+			err := verifier.Verify(context.Background(), test.registry, test.username, test.password)
+			if test.check == nil {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if !test.check(err) {
+				t.Errorf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifierTrustedCertificate(t *testing.T) {
+	secure := httptest.NewTLSServer(basicRegistry())
+	defer secure.Close()
+
+	verifier := registry.NewVerifier(secure.Client())
+	if err := verifier.Verify(context.Background(), secure.URL, username, password); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
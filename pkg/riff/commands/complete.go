@@ -0,0 +1,75 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"context"
+	"strings"
+
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+type CompleteOptions struct {
+	Args []string
+}
+
+var (
+	_ cli.Validatable = (*CompleteOptions)(nil)
+	_ cli.Executable  = (*CompleteOptions)(nil)
+)
+
+func (opts *CompleteOptions) Validate(ctx context.Context) *cli.FieldError {
+	return cli.EmptyFieldError
+}
+
+func (opts *CompleteOptions) Exec(ctx context.Context, c *cli.Config) error {
+	cmd := cli.CommandFromContext(ctx)
+	for _, candidate := range cli.Complete(c, cmd.Root(), opts.Args) {
+		c.Printf("%s\n", candidate)
+	}
+	return nil
+}
+
+func NewCompleteCommand(ctx context.Context, c *cli.Config) *cobra.Command {
+	opts := &CompleteOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "__complete",
+		Short: "complete a command line",
+		Long: strings.TrimSpace(`
+Print the candidates for the last argument of a partial command line, one per
+line. Used by the shell completion scripts.
+`),
+		Hidden:             true,
+		DisableFlagParsing: true,
+		PreRunE:            cli.ValidateOptions(ctx, opts),
+		RunE:               cli.ExecOptions(ctx, c, opts),
+	}
+
+	cli.Args(cmd,
+		cli.Arg{
+			Arity: -1,
+			Set: func(cmd *cobra.Command, args []string, offset int) error {
+				opts.Args = args[offset:]
+				return nil
+			},
+		},
+	)
+
+	return cmd
+}
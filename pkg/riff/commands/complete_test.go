@@ -0,0 +1,283 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/projectriff/cli/pkg/riff/commands"
+	rifftesting "github.com/projectriff/cli/pkg/testing"
+	"github.com/projectriff/system/pkg/apis/build"
+	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+	knativev1alpha1 "github.com/projectriff/system/pkg/apis/knative/v1alpha1"
+	streamv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestCompleteCommand(t *testing.T) {
+	defaultNamespace := "default"
+	otherNamespace := "other-namespace"
+
+	functions := []runtime.Object{
+		&buildv1alpha1.Function{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: defaultNamespace,
+				Name:      "my-function",
+			},
+		},
+		&buildv1alpha1.Function{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: defaultNamespace,
+				Name:      "another-function",
+			},
+		},
+		&buildv1alpha1.Function{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: otherNamespace,
+				Name:      "other-function",
+			},
+		},
+	}
+	noOutput := func(t *testing.T, output string, err error) {
+		if output != "" {
+			t.Errorf("expected no candidates, actually %q", output)
+		}
+	}
+
+	table := rifftesting.CommandTable{
+		{
+			Name: "subcommands",
+			Args: []string{"__complete", "function", ""},
+			ExpectOutput: `
+create
+delete
+list
+status
+tail
+`,
+		},
+		{
+			Name: "subcommand prefix",
+			Args: []string{"__complete", "function", "st"},
+			ExpectOutput: `
+status
+`,
+		},
+		{
+			Name: "flag names",
+			Args: []string{"__complete", "function", "status", "--n"},
+			ExpectOutput: `
+--namespace
+--no-color
+`,
+		},
+		{
+			Name:         "resource names",
+			Args:         []string{"__complete", "function", "status", ""},
+			GivenObjects: functions,
+			ExpectOutput: `
+another-function
+my-function
+`,
+		},
+		{
+			Name:         "resource names with prefix",
+			Args:         []string{"__complete", "function", "status", "my-"},
+			GivenObjects: functions,
+			ExpectOutput: `
+my-function
+`,
+		},
+		{
+			Name:         "resource names in namespace",
+			Args:         []string{"__complete", "function", "status", "--namespace", otherNamespace, ""},
+			GivenObjects: functions,
+			ExpectOutput: `
+other-function
+`,
+		},
+		{
+			Name:         "resource names excludes given names",
+			Args:         []string{"__complete", "function", "delete", "my-function", ""},
+			GivenObjects: functions,
+			ExpectOutput: `
+another-function
+`,
+		},
+		{
+			Name:         "single resource name",
+			Args:         []string{"__complete", "function", "status", "my-function", ""},
+			GivenObjects: functions,
+			Verify:       noOutput,
+		},
+		{
+			Name:         "create names are not completed",
+			Args:         []string{"__complete", "function", "create", ""},
+			GivenObjects: functions,
+			Verify:       noOutput,
+		},
+		{
+			Name: "credential names",
+			Args: []string{"__complete", "credential", "show", ""},
+			GivenObjects: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      "my-credential",
+						Labels: map[string]string{
+							build.CredentialLabelKey: "basic-auth",
+						},
+					},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      "not-a-credential",
+					},
+				},
+			},
+			ExpectOutput: `
+my-credential
+`,
+		},
+		{
+			Name: "namespace flag",
+			Args: []string{"__complete", "function", "list", "-n", ""},
+			GivenObjects: []runtime.Object{
+				&corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{Name: defaultNamespace},
+				},
+				&corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{Name: otherNamespace},
+				},
+			},
+			ExpectOutput: `
+default
+other-namespace
+`,
+		},
+		{
+			Name: "namespace flag with equals",
+			Args: []string{"__complete", "function", "list", "--namespace=o"},
+			GivenObjects: []runtime.Object{
+				&corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{Name: defaultNamespace},
+				},
+				&corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{Name: otherNamespace},
+				},
+			},
+			ExpectOutput: `
+--namespace=other-namespace
+`,
+		},
+		{
+			Name:         "reference flag",
+			Args:         []string{"__complete", "knative", "deployer", "create", "my-deployer", "--function-ref", ""},
+			GivenObjects: functions,
+			ExpectOutput: `
+another-function
+my-function
+`,
+		},
+		{
+			Name: "stream flags",
+			Args: []string{"__complete", "streaming", "processor", "create", "my-processor", "--input", "in", "--output", ""},
+			GivenObjects: []runtime.Object{
+				&streamv1alpha1.Stream{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      "in",
+					},
+				},
+				&streamv1alpha1.Stream{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      "out",
+					},
+				},
+			},
+			ExpectOutput: `
+in
+out
+`,
+		},
+		{
+			Name: "runtime resource names",
+			Args: []string{"__complete", "knative", "adapter", "status", ""},
+			GivenObjects: []runtime.Object{
+				&knativev1alpha1.Adapter{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: defaultNamespace,
+						Name:      "my-adapter",
+					},
+				},
+			},
+			ExpectOutput: `
+my-adapter
+`,
+		},
+		{
+			Name: "enumerated flag",
+			Args: []string{"__complete", "completion", "--shell", ""},
+			ExpectOutput: `
+bash
+zsh
+fish
+powershell
+`,
+		},
+		{
+			Name: "enumerated root flag",
+			Args: []string{"__complete", "function", "list", "--error-format", ""},
+			ExpectOutput: `
+text
+json
+`,
+		},
+		{
+			Name: "enumerated argument",
+			Args: []string{"__complete", "build", "config", "get", ""},
+			ExpectOutput: `
+default-image-prefix
+`,
+		},
+		{
+			Name:   "flag without completion",
+			Args:   []string{"__complete", "function", "create", "my-function", "--git-repo", ""},
+			Verify: noOutput,
+		},
+		{
+			Name:   "unknown command",
+			Args:   []string{"__complete", "zorglub", ""},
+			Verify: noOutput,
+		},
+		{
+			Name:         "list failure",
+			Args:         []string{"__complete", "function", "status", ""},
+			GivenObjects: functions,
+			WithReactors: []rifftesting.ReactionFunc{
+				rifftesting.InduceFailure("list", "functions"),
+			},
+			Verify: noOutput,
+		},
+	}
+
+	table.Run(t, commands.NewRootCommand)
+}
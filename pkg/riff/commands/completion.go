@@ -19,12 +19,20 @@ package commands
 import (
 	"context"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/projectriff/cli/pkg/cli"
 	"github.com/spf13/cobra"
 )
 
+const (
+	bashShell       = "bash"
+	fishShell       = "fish"
+	powerShellShell = "powershell"
+	zshShell        = "zsh"
+)
+
 type CompletionOptions struct {
 	Shell string
 }
@@ -37,9 +45,12 @@ var (
 func (opts *CompletionOptions) Validate(ctx context.Context) *cli.FieldError {
 	errs := cli.EmptyFieldError
 
-	if opts.Shell == "" {
+	switch opts.Shell {
+	case "":
 		errs = errs.Also(cli.ErrMissingField(cli.ShellFlagName))
-	} else if opts.Shell != "bash" && opts.Shell != "zsh" {
+	case bashShell, fishShell, powerShellShell, zshShell:
+		// valid
+	default:
 		errs = errs.Also(cli.ErrInvalidValue(opts.Shell, cli.ShellFlagName))
 	}
 
@@ -47,12 +58,19 @@ func (opts *CompletionOptions) Validate(ctx context.Context) *cli.FieldError {
 }
 
 func (opts *CompletionOptions) Exec(ctx context.Context, c *cli.Config) error {
-	cmd := cli.CommandFromContext(ctx)
+	root := cli.CommandFromContext(ctx).Root()
 	switch opts.Shell {
-	case "bash":
-		return cmd.Root().GenBashCompletion(c.Stdout)
-	case "zsh":
-		return cmd.Root().GenZshCompletion(c.Stdout)
+	case bashShell:
+		function := fmt.Sprintf("__%s_dynamic_complete", root.Name())
+		cli.BashCompleteFlags(root, function)
+		root.BashCompletionFunction = fmt.Sprintf(bashCompletionFunctions, root.Name())
+		return root.GenBashCompletion(c.Stdout)
+	case fishShell:
+		return writeCompletion(c.Stdout, fishCompletion, root.Name())
+	case powerShellShell:
+		return writeCompletion(c.Stdout, powerShellCompletion, root.Name())
+	case zshShell:
+		return writeCompletion(c.Stdout, zshCompletion, root.Name())
 	}
 	// protected by opts.Validate()
 	panic("invalid shell: " + opts.Shell)
@@ -67,16 +85,105 @@ func NewCompletionCommand(ctx context.Context, c *cli.Config) *cobra.Command {
 		Long: strings.TrimSpace(`
 Generate the completion script for your shell. The script is printed to stdout
 and needs to be placed in the appropriate directory on your system.
+
+Beyond commands and flags, the names of resources, namespaces and the values
+of enumerated flags are completed by querying the cluster as you type.
 `),
 		Example: strings.Join([]string{
 			fmt.Sprintf("%s completion", c.Name),
 			fmt.Sprintf("%s completion %s zsh", c.Name, cli.ShellFlagName),
+			fmt.Sprintf("%s completion %s fish > ~/.config/fish/completions/%s.fish", c.Name, cli.ShellFlagName, c.Name),
+			fmt.Sprintf("%s completion %s powershell | Out-String | Invoke-Expression", c.Name, cli.ShellFlagName),
 		}, "\n"),
 		PreRunE: cli.ValidateOptions(ctx, opts),
 		RunE:    cli.ExecOptions(ctx, c, opts),
 	}
 
-	cmd.Flags().StringVar(&opts.Shell, cli.StripDash(cli.ShellFlagName), "bash", "`shell` to generate completion for: bash or zsh")
+	cmd.Flags().StringVar(&opts.Shell, cli.StripDash(cli.ShellFlagName), bashShell, "`shell` to generate completion for: bash, zsh, fish or powershell")
+	cli.CompleteFlagValues(cmd, cli.ShellFlagName, bashShell, zshShell, fishShell, powerShellShell)
 
 	return cmd
 }
+
+func writeCompletion(w io.Writer, script, name string) error {
+	_, err := fmt.Fprintf(w, script, name)
+	return err
+}
+
+// bashCompletionFunctions are called by cobra's bash completion for flags with
+// dynamic values and for arguments.
+const bashCompletionFunctions = `
+__%[1]s_dynamic_complete()
+{
+    local word="${words[cword]}" prefix="" candidate
+    local -a candidates=()
+    if [[ ${word} == -*=* ]]; then
+        prefix="${word%%%%=*}="
+    fi
+    while IFS='' read -r candidate; do
+        candidates+=("${candidate#"${prefix}"}")
+    done < <("${words[0]}" __complete "${words[@]:1:cword}" 2>/dev/null)
+    COMPREPLY=( $(compgen -W "${candidates[*]}" -- "${cur}") )
+}
+
+__%[1]s_custom_func()
+{
+    __%[1]s_dynamic_complete
+}
+`
+
+const zshCompletion = `#compdef %[1]s
+compdef _%[1]s %[1]s
+
+_%[1]s()
+{
+    local -a candidates
+    candidates=("${(@f)$("${words[1]}" __complete "${(@)words[2,$CURRENT]}" 2>/dev/null)}")
+    if [[ ${#candidates[@]} -eq 1 && -z ${candidates[1]} ]]; then
+        _files
+        return
+    fi
+    compadd -a candidates
+}
+
+# don't run the completion function when being sourced
+if [ "$funcstack[1]" = "_%[1]s" ]; then
+    _%[1]s "$@"
+fi
+`
+
+const fishCompletion = `# fish completion for %[1]s
+
+function __%[1]s_complete
+    set -l args (commandline -opc)
+    set -e args[1]
+    %[1]s __complete $args (commandline -ct) 2>/dev/null
+end
+
+complete -c %[1]s -e
+complete -c %[1]s -f -a '(__%[1]s_complete)'
+`
+
+const powerShellCompletion = `# powershell completion for %[1]s
+
+Register-ArgumentCompleter -Native -CommandName '%[1]s' -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $arguments = @($commandAst.CommandElements |
+        Where-Object { $_.Extent.EndOffset -le $cursorPosition } |
+        Select-Object -Skip 1 |
+        ForEach-Object { $_.ToString() })
+    if ($wordToComplete -eq '') {
+        # empty arguments are dropped when calling native commands prior to 7.3
+        if ($PSVersionTable.PSVersion -lt [version]'7.3.0' -or $PSNativeCommandArgumentPassing -eq 'Legacy') {
+            $arguments += '""'
+        } else {
+            $arguments += ''
+        }
+    }
+    & '%[1]s' __complete @arguments 2>$null |
+        Where-Object { $_ -like "$wordToComplete*" } |
+        ForEach-Object {
+            [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+        }
+}
+`
@@ -53,6 +53,20 @@ func TestCompletionOptions(t *testing.T) {
 			},
 			ShouldValidate: true,
 		},
+		{
+			Name: "valid shell fish",
+			Options: &commands.CompletionOptions{
+				Shell: "fish",
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "valid shell powershell",
+			Options: &commands.CompletionOptions{
+				Shell: "powershell",
+			},
+			ShouldValidate: true,
+		},
 	}
 
 	table.Run(t)
@@ -79,6 +93,7 @@ func TestCompletionCommand(t *testing.T) {
 			Verify: func(t *testing.T, output string, err error) {
 				for _, str := range []string{
 					"# bash completion",
+					"__completion_dynamic_complete()",
 				} {
 					if !strings.Contains(output, str) {
 						t.Errorf("expected completion output to contain %q\n", str)
@@ -91,7 +106,36 @@ func TestCompletionCommand(t *testing.T) {
 			Args: []string{cli.ShellFlagName, "zsh"},
 			Verify: func(t *testing.T, output string, err error) {
 				for _, str := range []string{
-					"#compdef _completion completion",
+					"#compdef completion",
+					"__complete",
+				} {
+					if !strings.Contains(output, str) {
+						t.Errorf("expected completion output to contain %q\n", str)
+					}
+				}
+			},
+		},
+		{
+			Name: "fish",
+			Args: []string{cli.ShellFlagName, "fish"},
+			Verify: func(t *testing.T, output string, err error) {
+				for _, str := range []string{
+					"# fish completion for completion",
+					"completion __complete",
+				} {
+					if !strings.Contains(output, str) {
+						t.Errorf("expected completion output to contain %q\n", str)
+					}
+				}
+			},
+		},
+		{
+			Name: "powershell",
+			Args: []string{cli.ShellFlagName, "powershell"},
+			Verify: func(t *testing.T, output string, err error) {
+				for _, str := range []string{
+					"Register-ArgumentCompleter -Native -CommandName 'completion'",
+					"& 'completion' __complete",
 				} {
 					if !strings.Contains(output, str) {
 						t.Errorf("expected completion output to contain %q\n", str)
@@ -0,0 +1,140 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	profileKubeConfigKey = "kube-config"
+	profileContextKey    = "context"
+	profileNamespaceKey  = "namespace"
+	profileRuntimesKey   = "runtimes"
+	profileFlagsKey      = "flags"
+)
+
+func NewConfigCommand(ctx context.Context, c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "profiles in the " + c.Name + " config file",
+		Long: strings.TrimSpace(fmt.Sprintf(`
+Profiles in the %s config file.
+
+A profile is a named set of settings, switching between clusters is a matter of
+changing the active profile. Each profile may set:
+- %s: the kubectl config file
+- %s: the kubectl config context
+- %s: the default namespace
+- %s: the runtimes that are enabled, overriding the runtimes built in
+- %s: default values for flags, flags on the command line take precedence
+
+For example:
+
+    profile: kind
+    profiles:
+      kind:
+        context: kind-kind
+        runtimes: [core, streaming]
+      dev:
+        kube-config: ~/.kube/dev
+        namespace: my-team
+        flags:
+          wait-timeout: 5m
+
+The active profile may be overridden by the %s_PROFILE environment variable.
+`, c.Name, profileKubeConfigKey, profileContextKey, profileNamespaceKey, profileRuntimesKey, profileFlagsKey, strings.ToUpper(c.Name))),
+	}
+
+	cmd.AddCommand(NewConfigSetCommand(ctx, c))
+	cmd.AddCommand(NewConfigUseProfileCommand(ctx, c))
+	cmd.AddCommand(NewConfigViewCommand(ctx, c))
+
+	return cmd
+}
+
+// configFile is the config file read by the CLI, it may not exist yet.
+func configFile(c *cli.Config) (string, error) {
+	if c.ViperConfigFile != "" {
+		return c.ViperConfigFile, nil
+	}
+	if file := viper.ConfigFileUsed(); file != "" {
+		return file, nil
+	}
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, fmt.Sprintf(".%s.yaml", c.Name)), nil
+}
+
+// readConfigFile reads the content of the config file. A missing file has no
+// content.
+func readConfigFile(file string) (map[string]interface{}, error) {
+	config := map[string]interface{}{}
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config, nil
+		}
+		return nil, err
+	}
+	if err := yaml.Unmarshal(b, &config); err != nil {
+		return nil, fmt.Errorf("unable to parse config file %s: %v", file, err)
+	}
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+	return config, nil
+}
+
+func writeConfigFile(file string, config map[string]interface{}) error {
+	b, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, b, 0600)
+}
+
+// configProfile returns the named profile from the config, or nil if the
+// profile is not defined.
+func configProfile(config map[string]interface{}, name string) map[string]interface{} {
+	profiles, ok := config[cli.ProfilesKey].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	profile, ok := profiles[name].(map[string]interface{})
+	if !ok {
+		if _, defined := profiles[name]; defined {
+			// a profile without settings
+			return map[string]interface{}{}
+		}
+		return nil
+	}
+	return profile
+}
@@ -0,0 +1,187 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/projectriff/cli/pkg/validation"
+	"github.com/spf13/cobra"
+)
+
+type ConfigSetOptions struct {
+	Profile string
+	Key     string
+	Value   string
+}
+
+var (
+	_ cli.Validatable = (*ConfigSetOptions)(nil)
+	_ cli.Executable  = (*ConfigSetOptions)(nil)
+)
+
+func (opts *ConfigSetOptions) Validate(ctx context.Context) *cli.FieldError {
+	errs := cli.EmptyFieldError
+
+	if opts.Profile != "" {
+		errs = errs.Also(validation.K8sName(opts.Profile, cli.ProfileFlagName))
+	}
+
+	switch {
+	case opts.Key == "":
+		errs = errs.Also(cli.ErrMissingField(cli.KeyArgumentName))
+	case opts.Key == profileRuntimesKey:
+		if opts.Value != "" {
+			for _, runtime := range strings.Split(opts.Value, ",") {
+				switch runtime {
+				case cli.CoreRuntime, cli.StreamingRuntime, cli.KnativeRuntime:
+				default:
+					errs = errs.Also(cli.ErrInvalidValue(runtime, cli.ValueArgumentName))
+				}
+			}
+		}
+	case opts.Key == profileKubeConfigKey, opts.Key == profileContextKey, opts.Key == profileNamespaceKey:
+	case strings.HasPrefix(opts.Key, profileFlagsKey+"."):
+		if strings.TrimPrefix(opts.Key, profileFlagsKey+".") == "" {
+			errs = errs.Also(cli.ErrInvalidValue(opts.Key, cli.KeyArgumentName))
+		}
+	default:
+		errs = errs.Also(cli.ErrInvalidValue(opts.Key, cli.KeyArgumentName))
+	}
+
+	return errs
+}
+
+func (opts *ConfigSetOptions) Exec(ctx context.Context, c *cli.Config) error {
+	file, err := configFile(c)
+	if err != nil {
+		return err
+	}
+	config, err := readConfigFile(file)
+	if err != nil {
+		return err
+	}
+
+	name := opts.Profile
+	if name == "" {
+		name, _ = config[cli.ProfileKey].(string)
+	}
+	if name == "" {
+		return fmt.Errorf("no active profile, set %s to choose the profile", cli.ProfileFlagName)
+	}
+
+	profiles, ok := config[cli.ProfilesKey].(map[string]interface{})
+	if !ok {
+		profiles = map[string]interface{}{}
+		config[cli.ProfilesKey] = profiles
+	}
+	profile := configProfile(config, name)
+	if profile == nil {
+		profile = map[string]interface{}{}
+	}
+	profiles[name] = profile
+
+	if flag := strings.TrimPrefix(opts.Key, profileFlagsKey+"."); flag != opts.Key {
+		flags, ok := profile[profileFlagsKey].(map[string]interface{})
+		if !ok {
+			flags = map[string]interface{}{}
+		}
+		if opts.Value == "" {
+			delete(flags, flag)
+		} else {
+			flags[flag] = opts.Value
+		}
+		if len(flags) == 0 {
+			delete(profile, profileFlagsKey)
+		} else {
+			profile[profileFlagsKey] = flags
+		}
+	} else if opts.Value == "" {
+		delete(profile, opts.Key)
+	} else if opts.Key == profileRuntimesKey {
+		profile[opts.Key] = strings.Split(opts.Value, ",")
+	} else {
+		profile[opts.Key] = opts.Value
+	}
+
+	if err := writeConfigFile(file, config); err != nil {
+		return err
+	}
+	if opts.Value == "" {
+		c.Successf("Unset %s in profile %q\n", opts.Key, name)
+	} else {
+		c.Successf("Set %s to %q in profile %q\n", opts.Key, opts.Value, name)
+	}
+
+	return nil
+}
+
+func NewConfigSetCommand(ctx context.Context, c *cli.Config) *cobra.Command {
+	opts := &ConfigSetOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "set a profile setting",
+		Long: strings.TrimSpace(fmt.Sprintf(`
+Set the value of a setting in a profile, the profile is created if it does not
+exist. An empty value removes the setting.
+
+The supported keys are:
+- %s: the kubectl config file
+- %s: the kubectl config context
+- %s: the default namespace
+- %s: a comma separated list of enabled runtimes, e.g. '%s,%s'
+- %s.<flag>: the default value of the flag, e.g. '%s.wait-timeout'
+
+The active profile is updated unless %s is set.
+`, profileKubeConfigKey, profileContextKey, profileNamespaceKey, profileRuntimesKey, cli.CoreRuntime, cli.StreamingRuntime, profileFlagsKey, profileFlagsKey, cli.ProfileFlagName)),
+		Example: strings.Join([]string{
+			fmt.Sprintf("%s config set %s kind-kind %s kind", c.Name, profileContextKey, cli.ProfileFlagName),
+			fmt.Sprintf("%s config set %s my-team", c.Name, profileNamespaceKey),
+			fmt.Sprintf("%s config set %s.wait-timeout 5m", c.Name, profileFlagsKey),
+		}, "\n"),
+		PreRunE: cli.ValidateOptions(ctx, opts),
+		RunE:    cli.ExecOptions(ctx, c, opts),
+	}
+
+	cli.Args(cmd,
+		cli.Arg{
+			Name:   cli.KeyArgumentName,
+			Arity:  1,
+			Values: []string{profileKubeConfigKey, profileContextKey, profileNamespaceKey, profileRuntimesKey, profileFlagsKey + "."},
+			Set: func(cmd *cobra.Command, args []string, offset int) error {
+				opts.Key = args[offset]
+				return nil
+			},
+		},
+		cli.Arg{
+			Name:  cli.ValueArgumentName,
+			Arity: 1,
+			Set: func(cmd *cobra.Command, args []string, offset int) error {
+				opts.Value = args[offset]
+				return nil
+			},
+		},
+	)
+
+	cmd.Flags().StringVar(&opts.Profile, cli.StripDash(cli.ProfileFlagName), "", "`name` of the profile to change (default is the active profile)")
+
+	return cmd
+}
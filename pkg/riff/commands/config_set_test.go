@@ -0,0 +1,187 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/projectriff/cli/pkg/riff/commands"
+	rifftesting "github.com/projectriff/cli/pkg/testing"
+)
+
+func TestConfigSetOptions(t *testing.T) {
+	table := rifftesting.OptionsTable{
+		{
+			Name:             "missing key",
+			Options:          &commands.ConfigSetOptions{},
+			ExpectFieldError: cli.ErrMissingField(cli.KeyArgumentName),
+		},
+		{
+			Name: "invalid key",
+			Options: &commands.ConfigSetOptions{
+				Key:   "bogus",
+				Value: "value",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("bogus", cli.KeyArgumentName),
+		},
+		{
+			Name: "missing flag name",
+			Options: &commands.ConfigSetOptions{
+				Key:   "flags.",
+				Value: "value",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("flags.", cli.KeyArgumentName),
+		},
+		{
+			Name: "flag",
+			Options: &commands.ConfigSetOptions{
+				Key:   "flags.wait-timeout",
+				Value: "5m",
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "runtimes",
+			Options: &commands.ConfigSetOptions{
+				Key:   "runtimes",
+				Value: "core,knative",
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "invalid runtimes",
+			Options: &commands.ConfigSetOptions{
+				Key:   "runtimes",
+				Value: "core,bogus",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("bogus", cli.ValueArgumentName),
+		},
+		{
+			Name: "unset",
+			Options: &commands.ConfigSetOptions{
+				Key: "namespace",
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "invalid profile",
+			Options: &commands.ConfigSetOptions{
+				Profile: "my.profile",
+				Key:     "namespace",
+				Value:   "my-team",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("my.profile", cli.ProfileFlagName),
+		},
+	}
+
+	table.Run(t)
+}
+
+func TestConfigSetCommand(t *testing.T) {
+	file, cleanUpFile := tempConfigFile(t)
+	defer cleanUpFile()
+
+	prepareSet, cleanUpSet := withConfigFile(file, profilesConfig, strings.Replace(profilesConfig, "context: kind-kind", "context: kind-other", 1))
+	prepareProfile, cleanUpProfile := withConfigFile(file, profilesConfig, strings.Replace(profilesConfig, "    namespace: my-team\n", "    namespace: other-team\n", 1))
+	prepareRuntimes, cleanUpRuntimes := withConfigFile(file, profilesConfig, strings.Replace(profilesConfig, "    - core\n    - streaming\n", "    - knative\n", 1))
+	prepareFlag, cleanUpFlag := withConfigFile(file, profilesConfig, strings.Replace(profilesConfig, "      wait-timeout: 5m\n", "      image: registry.example.com/image\n      wait-timeout: 5m\n", 1))
+	prepareUnset, cleanUpUnset := withConfigFile(file, profilesConfig, strings.Replace(profilesConfig, "    flags:\n      wait-timeout: 5m\n", "", 1))
+	prepareNew, cleanUpNew := withConfigFile(file, "", `
+profiles:
+  prod:
+    context: prod
+`)
+	prepareNoProfile, cleanUpNoProfile := withConfigFile(file, "", "")
+
+	table := rifftesting.CommandTable{
+		{
+			Name:        "invalid args",
+			Args:        []string{"config", "set", cli.ConfigFlagName, file},
+			ShouldError: true,
+		},
+		{
+			Name:    "set active profile",
+			Args:    []string{"config", "set", "context", "kind-other", cli.ConfigFlagName, file},
+			Prepare: prepareSet,
+			CleanUp: cleanUpSet,
+			ExpectOutput: `
+Set context to "kind-other" in profile "kind"
+`,
+		},
+		{
+			Name:    "set profile",
+			Args:    []string{"config", "set", "namespace", "other-team", cli.ProfileFlagName, "dev", cli.ConfigFlagName, file},
+			Prepare: prepareProfile,
+			CleanUp: cleanUpProfile,
+			ExpectOutput: `
+Set namespace to "other-team" in profile "dev"
+`,
+		},
+		{
+			Name:    "set runtimes",
+			Args:    []string{"config", "set", "runtimes", "knative", cli.ConfigFlagName, file},
+			Prepare: prepareRuntimes,
+			CleanUp: cleanUpRuntimes,
+			ExpectOutput: `
+Set runtimes to "knative" in profile "kind"
+`,
+		},
+		{
+			Name:    "set flag",
+			Args:    []string{"config", "set", "flags.image", "registry.example.com/image", cli.ProfileFlagName, "dev", cli.ConfigFlagName, file},
+			Prepare: prepareFlag,
+			CleanUp: cleanUpFlag,
+			ExpectOutput: `
+Set flags.image to "registry.example.com/image" in profile "dev"
+`,
+		},
+		{
+			Name:    "unset flag",
+			Args:    []string{"config", "set", "flags.wait-timeout", "", cli.ProfileFlagName, "dev", cli.ConfigFlagName, file},
+			Prepare: prepareUnset,
+			CleanUp: cleanUpUnset,
+			ExpectOutput: `
+Unset flags.wait-timeout in profile "dev"
+`,
+		},
+		{
+			Name:    "new profile",
+			Args:    []string{"config", "set", "context", "prod", cli.ProfileFlagName, "prod", cli.ConfigFlagName, file},
+			Prepare: prepareNew,
+			CleanUp: cleanUpNew,
+			ExpectOutput: `
+Set context to "prod" in profile "prod"
+`,
+		},
+		{
+			Name:        "no active profile",
+			Args:        []string{"config", "set", "context", "prod", cli.ConfigFlagName, file},
+			Prepare:     prepareNoProfile,
+			CleanUp:     cleanUpNoProfile,
+			ShouldError: true,
+			Verify: func(t *testing.T, output string, err error) {
+				if expected, actual := "no active profile, set --profile to choose the profile", err.Error(); expected != actual {
+					t.Errorf("Expected error %q, actually %q", expected, actual)
+				}
+			},
+		},
+	}
+
+	table.Run(t, commands.NewRootCommand)
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/projectriff/cli/pkg/riff/commands"
+	rifftesting "github.com/projectriff/cli/pkg/testing"
+)
+
+// withConfigFile writes the content to the config file before the test, when
+// the content is empty the file is removed. On clean up the file's content is
+// compared with the expected content.
+func withConfigFile(file, content, expected string) (func(*testing.T, context.Context, *cli.Config) (context.Context, error), func(*testing.T, context.Context, *cli.Config) error) {
+	prepare := func(t *testing.T, ctx context.Context, c *cli.Config) (context.Context, error) {
+		if content == "" {
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+			return ctx, nil
+		}
+		return ctx, ioutil.WriteFile(file, []byte(strings.TrimPrefix(content, "\n")), 0600)
+	}
+	cleanUp := func(t *testing.T, ctx context.Context, c *cli.Config) error {
+		b, err := ioutil.ReadFile(file)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if diff := cmp.Diff(strings.TrimPrefix(expected, "\n"), string(b)); diff != "" {
+			return fmt.Errorf("unexpected config file (-expected, +actual): %s", diff)
+		}
+		return nil
+	}
+	return prepare, cleanUp
+}
+
+// tempConfigFile is the path of a config file in a new temp directory.
+func tempConfigFile(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "riff-config")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	return filepath.Join(dir, ".riff.yaml"), func() { os.RemoveAll(dir) }
+}
+
+const profilesConfig = `
+profile: kind
+profiles:
+  dev:
+    flags:
+      wait-timeout: 5m
+    kube-config: ~/.kube/dev
+    namespace: my-team
+  kind:
+    context: kind-kind
+    runtimes:
+    - core
+    - streaming
+`
+
+func TestConfigCommand(t *testing.T) {
+	table := rifftesting.CommandTable{
+		{
+			Name: "empty",
+			Args: []string{},
+		},
+	}
+
+	table.Run(t, commands.NewConfigCommand)
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/projectriff/cli/pkg/validation"
+	"github.com/spf13/cobra"
+)
+
+type ConfigUseProfileOptions struct {
+	Name string
+}
+
+var (
+	_ cli.Validatable = (*ConfigUseProfileOptions)(nil)
+	_ cli.Executable  = (*ConfigUseProfileOptions)(nil)
+)
+
+func (opts *ConfigUseProfileOptions) Validate(ctx context.Context) *cli.FieldError {
+	errs := cli.EmptyFieldError
+
+	if opts.Name == "" {
+		errs = errs.Also(cli.ErrMissingField(cli.NameArgumentName))
+	} else {
+		errs = errs.Also(validation.K8sName(opts.Name, cli.NameArgumentName))
+	}
+
+	return errs
+}
+
+func (opts *ConfigUseProfileOptions) Exec(ctx context.Context, c *cli.Config) error {
+	file, err := configFile(c)
+	if err != nil {
+		return err
+	}
+	config, err := readConfigFile(file)
+	if err != nil {
+		return err
+	}
+	if configProfile(config, opts.Name) == nil {
+		return fmt.Errorf("profile %q not found in config file %s", opts.Name, file)
+	}
+
+	config[cli.ProfileKey] = opts.Name
+	if err := writeConfigFile(file, config); err != nil {
+		return err
+	}
+	c.Successf("Using profile %q\n", opts.Name)
+
+	return nil
+}
+
+func NewConfigUseProfileCommand(ctx context.Context, c *cli.Config) *cobra.Command {
+	opts := &ConfigUseProfileOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "use-profile",
+		Short: "set the active profile",
+		Long: strings.TrimSpace(`
+Set the active profile in the config file.
+
+The profile must already be defined, profiles are created with the 'config set'
+command.
+`),
+		Example: strings.Join([]string{
+			fmt.Sprintf("%s config use-profile dev", c.Name),
+		}, "\n"),
+		PreRunE: cli.ValidateOptions(ctx, opts),
+		RunE:    cli.ExecOptions(ctx, c, opts),
+	}
+
+	cli.Args(cmd,
+		cli.NameArg(&opts.Name),
+	)
+
+	return cmd
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/projectriff/cli/pkg/riff/commands"
+	rifftesting "github.com/projectriff/cli/pkg/testing"
+)
+
+func TestConfigUseProfileOptions(t *testing.T) {
+	table := rifftesting.OptionsTable{
+		{
+			Name:             "missing name",
+			Options:          &commands.ConfigUseProfileOptions{},
+			ExpectFieldError: cli.ErrMissingField(cli.NameArgumentName),
+		},
+		{
+			Name: "invalid name",
+			Options: &commands.ConfigUseProfileOptions{
+				Name: "my.profile",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("my.profile", cli.NameArgumentName),
+		},
+		{
+			Name: "valid",
+			Options: &commands.ConfigUseProfileOptions{
+				Name: "dev",
+			},
+			ShouldValidate: true,
+		},
+	}
+
+	table.Run(t)
+}
+
+func TestConfigUseProfileCommand(t *testing.T) {
+	file, cleanUpFile := tempConfigFile(t)
+	defer cleanUpFile()
+
+	prepareProfiles, cleanUpProfiles := withConfigFile(file, profilesConfig, strings.Replace(profilesConfig, "profile: kind", "profile: dev", 1))
+	prepareMissing, cleanUpMissing := withConfigFile(file, profilesConfig, profilesConfig)
+	prepareEmpty, cleanUpEmpty := withConfigFile(file, "", "")
+
+	table := rifftesting.CommandTable{
+		{
+			Name:        "invalid args",
+			Args:        []string{"config", "use-profile", cli.ConfigFlagName, file},
+			ShouldError: true,
+		},
+		{
+			Name:    "use profile",
+			Args:    []string{"config", "use-profile", "dev", cli.ConfigFlagName, file},
+			Prepare: prepareProfiles,
+			CleanUp: cleanUpProfiles,
+			ExpectOutput: `
+Using profile "dev"
+`,
+		},
+		{
+			Name:        "missing profile",
+			Args:        []string{"config", "use-profile", "prod", cli.ConfigFlagName, file},
+			Prepare:     prepareMissing,
+			CleanUp:     cleanUpMissing,
+			ShouldError: true,
+		},
+		{
+			Name:        "missing config file",
+			Args:        []string{"config", "use-profile", "dev", cli.ConfigFlagName, file},
+			Prepare:     prepareEmpty,
+			CleanUp:     cleanUpEmpty,
+			ShouldError: true,
+		},
+	}
+
+	table.Run(t, commands.NewRootCommand)
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/projectriff/cli/pkg/validation"
+	"github.com/spf13/cobra"
+)
+
+type ConfigViewOptions struct {
+	Profile string
+}
+
+var (
+	_ cli.Validatable = (*ConfigViewOptions)(nil)
+	_ cli.Executable  = (*ConfigViewOptions)(nil)
+)
+
+func (opts *ConfigViewOptions) Validate(ctx context.Context) *cli.FieldError {
+	errs := cli.EmptyFieldError
+
+	if opts.Profile != "" {
+		errs = errs.Also(validation.K8sName(opts.Profile, cli.ProfileFlagName))
+	}
+
+	return errs
+}
+
+func (opts *ConfigViewOptions) Exec(ctx context.Context, c *cli.Config) error {
+	file, err := configFile(c)
+	if err != nil {
+		return err
+	}
+	config, err := readConfigFile(file)
+	if err != nil {
+		return err
+	}
+
+	var content interface{} = config
+	if opts.Profile != "" {
+		profile := configProfile(config, opts.Profile)
+		if profile == nil {
+			return fmt.Errorf("profile %q not found in config file %s", opts.Profile, file)
+		}
+		content = profile
+	} else if len(config) == 0 {
+		c.Infof("No configuration found in %s.\n", file)
+		return nil
+	}
+
+	b, err := yaml.Marshal(content)
+	if err != nil {
+		return err
+	}
+	c.Printf("%s", b)
+
+	return nil
+}
+
+func NewConfigViewCommand(ctx context.Context, c *cli.Config) *cobra.Command {
+	opts := &ConfigViewOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "view",
+		Short: "show the config file",
+		Long: strings.TrimSpace(`
+Show the content of the config file, or the settings of a single profile.
+`),
+		Example: strings.Join([]string{
+			fmt.Sprintf("%s config view", c.Name),
+			fmt.Sprintf("%s config view %s dev", c.Name, cli.ProfileFlagName),
+		}, "\n"),
+		PreRunE: cli.ValidateOptions(ctx, opts),
+		RunE:    cli.ExecOptions(ctx, c, opts),
+	}
+
+	cmd.Flags().StringVar(&opts.Profile, cli.StripDash(cli.ProfileFlagName), "", "`name` of the profile to show")
+
+	return cmd
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/projectriff/cli/pkg/riff/commands"
+	rifftesting "github.com/projectriff/cli/pkg/testing"
+)
+
+func TestConfigViewOptions(t *testing.T) {
+	table := rifftesting.OptionsTable{
+		{
+			Name:           "valid",
+			Options:        &commands.ConfigViewOptions{},
+			ShouldValidate: true,
+		},
+		{
+			Name: "valid profile",
+			Options: &commands.ConfigViewOptions{
+				Profile: "dev",
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "invalid profile",
+			Options: &commands.ConfigViewOptions{
+				Profile: "my.profile",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("my.profile", cli.ProfileFlagName),
+		},
+	}
+
+	table.Run(t)
+}
+
+func TestConfigViewCommand(t *testing.T) {
+	file, cleanUpFile := tempConfigFile(t)
+	defer cleanUpFile()
+
+	prepareProfiles, cleanUpProfiles := withConfigFile(file, profilesConfig, profilesConfig)
+	prepareEmpty, cleanUpEmpty := withConfigFile(file, "", "")
+
+	table := rifftesting.CommandTable{
+		{
+			Name:         "view config",
+			Args:         []string{"config", "view", cli.ConfigFlagName, file},
+			Prepare:      prepareProfiles,
+			CleanUp:      cleanUpProfiles,
+			ExpectOutput: profilesConfig,
+		},
+		{
+			Name:    "view profile",
+			Args:    []string{"config", "view", cli.ProfileFlagName, "kind", cli.ConfigFlagName, file},
+			Prepare: prepareProfiles,
+			CleanUp: cleanUpProfiles,
+			ExpectOutput: `
+context: kind-kind
+runtimes:
+- core
+- streaming
+`,
+		},
+		{
+			Name:        "missing profile",
+			Args:        []string{"config", "view", cli.ProfileFlagName, "prod", cli.ConfigFlagName, file},
+			Prepare:     prepareProfiles,
+			CleanUp:     cleanUpProfiles,
+			ShouldError: true,
+		},
+		{
+			Name:    "missing config file",
+			Args:    []string{"config", "view", cli.ConfigFlagName, file},
+			Prepare: prepareEmpty,
+			CleanUp: cleanUpEmpty,
+			ExpectOutput: `
+No configuration found in ` + file + `.
+`,
+		},
+	}
+
+	table.Run(t, commands.NewRootCommand)
+}
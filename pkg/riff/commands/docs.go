@@ -17,20 +17,32 @@
 package commands
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/projectriff/cli/pkg/cli"
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
+	"github.com/spf13/pflag"
+)
+
+const (
+	MarkdownDocsFormat = "markdown"
+	ManDocsFormat      = "man"
+	JSONDocsFormat     = "json"
 )
 
 type DocsOptions struct {
 	Directory string
+	Format    string
 }
 
 var (
@@ -44,6 +56,14 @@ func (opts *DocsOptions) Validate(ctx context.Context) *cli.FieldError {
 		errs = errs.Also(cli.ErrMissingField(cli.DirectoryFlagName))
 	}
 
+	switch opts.Format {
+	case MarkdownDocsFormat, ManDocsFormat, JSONDocsFormat:
+	case "":
+		errs = errs.Also(cli.ErrMissingField(cli.FormatFlagName))
+	default:
+		errs = errs.Also(cli.ErrInvalidValue(opts.Format, cli.FormatFlagName))
+	}
+
 	return errs
 }
 
@@ -51,9 +71,19 @@ func NewDocsCommand(ctx context.Context, c *cli.Config) *cobra.Command {
 	opts := &DocsOptions{}
 
 	cmd := &cobra.Command{
-		Use:     "docs",
-		Short:   "generate docs in Markdown for this CLI",
-		Example: fmt.Sprintf("%s docs", c.Name),
+		Use:   "docs",
+		Short: "generate docs for this CLI",
+		Long: strings.TrimSpace(fmt.Sprintf(`
+Generate docs for this CLI in one of the formats:
+- %s: a page per command for the docs site
+- %s: a man page per command for packaging
+- %s: a single file describing the tree of commands, with the args, flags and
+  examples for each command
+`, MarkdownDocsFormat, ManDocsFormat, JSONDocsFormat)),
+		Example: strings.Join([]string{
+			fmt.Sprintf("%s docs", c.Name),
+			fmt.Sprintf("%s docs %s %s", c.Name, cli.FormatFlagName, ManDocsFormat),
+		}, "\n"),
 		Hidden:  true,
 		PreRunE: cli.ValidateOptions(ctx, opts),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -66,6 +96,10 @@ func NewDocsCommand(ctx context.Context, c *cli.Config) *cobra.Command {
 				noColorFlag.DefValue = "false"
 			}
 
+			if opts.Format == JSONDocsFormat {
+				return genJSONDocs(root, opts.Directory)
+			}
+
 			// hack to rewrite the CommandPath content to add args
 			cli.Visit(root, func(cmd *cobra.Command) error {
 				if !cmd.HasSubCommands() {
@@ -74,6 +108,14 @@ func NewDocsCommand(ctx context.Context, c *cli.Config) *cobra.Command {
 				return nil
 			})
 
+			if opts.Format == ManDocsFormat {
+				return doc.GenManTree(root, &doc.GenManHeader{
+					Section: "1",
+					Source:  fmt.Sprintf("%s %s", c.Name, c.Version),
+					Manual:  fmt.Sprintf("%s Manual", c.Name),
+				}, opts.Directory)
+			}
+
 			return doc.GenMarkdownTreeCustom(root, opts.Directory,
 				func(filename string) string {
 					name := filepath.Base(filename)
@@ -99,6 +141,99 @@ title: "%s"
 	}
 
 	cmd.Flags().StringVarP(&opts.Directory, cli.StripDash(cli.DirectoryFlagName), "d", "docs", "the output `directory` for the docs")
+	cmd.Flags().StringVar(&opts.Format, cli.StripDash(cli.FormatFlagName), MarkdownDocsFormat, fmt.Sprintf("`format` of the docs, one of %q, %q or %q", MarkdownDocsFormat, ManDocsFormat, JSONDocsFormat))
+	cli.CompleteFlagValues(cmd, cli.FormatFlagName, MarkdownDocsFormat, ManDocsFormat, JSONDocsFormat)
 
 	return cmd
 }
+
+type commandDoc struct {
+	Name     string       `json:"name"`
+	Path     string       `json:"path"`
+	Aliases  []string     `json:"aliases,omitempty"`
+	Short    string       `json:"short"`
+	Long     string       `json:"long,omitempty"`
+	Example  string       `json:"example,omitempty"`
+	Args     []argDoc     `json:"args,omitempty"`
+	Flags    []flagDoc    `json:"flags,omitempty"`
+	Commands []commandDoc `json:"commands,omitempty"`
+}
+
+type argDoc struct {
+	Name     string   `json:"name"`
+	Arity    int      `json:"arity"`
+	Optional bool     `json:"optional,omitempty"`
+	Values   []string `json:"values,omitempty"`
+}
+
+type flagDoc struct {
+	Name      string `json:"name"`
+	Shorthand string `json:"shorthand,omitempty"`
+	Type      string `json:"type"`
+	ValueName string `json:"valueName,omitempty"`
+	Default   string `json:"default,omitempty"`
+	Usage     string `json:"usage"`
+}
+
+// genJSONDocs writes the tree of commands to a single JSON file named for the
+// root command.
+func genJSONDocs(root *cobra.Command, dir string) error {
+	b := &bytes.Buffer{}
+	encoder := json.NewEncoder(b)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(newCommandDoc(root)); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, root.Name()+".json"), b.Bytes(), 0644)
+}
+
+func newCommandDoc(cmd *cobra.Command) commandDoc {
+	d := commandDoc{
+		Name:    cmd.Name(),
+		Path:    cmd.CommandPath(),
+		Aliases: cmd.Aliases,
+		Short:   cmd.Short,
+		Long:    cmd.Long,
+		Example: cmd.Example,
+	}
+
+	// args are described by the annotations set by cli.Args
+	length, _ := strconv.Atoi(cmd.Annotations["args.length"])
+	for i := 0; i < length; i++ {
+		arity, _ := strconv.Atoi(cmd.Annotations[fmt.Sprintf("args[%d].arity", i)])
+		arg := argDoc{
+			Name:     cmd.Annotations[fmt.Sprintf("args[%d].name", i)],
+			Arity:    arity,
+			Optional: cmd.Annotations[fmt.Sprintf("args[%d].optional", i)] == "true",
+		}
+		if values := cmd.Annotations[fmt.Sprintf("args[%d].values", i)]; values != "" {
+			arg.Values = strings.Split(values, ",")
+		}
+		d.Args = append(d.Args, arg)
+	}
+
+	cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Hidden {
+			return
+		}
+		valueName, usage := pflag.UnquoteUsage(f)
+		d.Flags = append(d.Flags, flagDoc{
+			Name:      f.Name,
+			Shorthand: f.Shorthand,
+			Type:      f.Value.Type(),
+			ValueName: valueName,
+			Default:   f.DefValue,
+			Usage:     usage,
+		})
+	})
+
+	for _, child := range cmd.Commands() {
+		if !child.IsAvailableCommand() || child.IsAdditionalHelpTopicCommand() {
+			continue
+		}
+		d.Commands = append(d.Commands, newCommandDoc(child))
+	}
+
+	return d
+}
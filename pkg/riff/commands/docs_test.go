@@ -18,8 +18,10 @@ package commands_test
 
 import (
 	"context"
+	"encoding/json"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/projectriff/cli/pkg/cli"
@@ -33,6 +35,7 @@ func TestDocsOptions(t *testing.T) {
 			Name: "valid",
 			Options: &commands.DocsOptions{
 				Directory: "docs",
+				Format:    commands.MarkdownDocsFormat,
 			},
 			ShouldValidate: true,
 		},
@@ -40,9 +43,41 @@ func TestDocsOptions(t *testing.T) {
 			Name: "invalid",
 			Options: &commands.DocsOptions{
 				Directory: "",
+				Format:    commands.MarkdownDocsFormat,
 			},
 			ExpectFieldError: cli.ErrMissingField(cli.DirectoryFlagName),
 		},
+		{
+			Name: "man format",
+			Options: &commands.DocsOptions{
+				Directory: "docs",
+				Format:    commands.ManDocsFormat,
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "json format",
+			Options: &commands.DocsOptions{
+				Directory: "docs",
+				Format:    commands.JSONDocsFormat,
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "missing format",
+			Options: &commands.DocsOptions{
+				Directory: "docs",
+			},
+			ExpectFieldError: cli.ErrMissingField(cli.FormatFlagName),
+		},
+		{
+			Name: "invalid format",
+			Options: &commands.DocsOptions{
+				Directory: "docs",
+				Format:    "html",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("html", cli.FormatFlagName),
+		},
 	}
 
 	table.Run(t)
@@ -78,6 +113,54 @@ func TestDocsCommand(t *testing.T) {
 				return os.RemoveAll(dir)
 			},
 		},
+		{
+			Name: "generate man pages",
+			Args: []string{cli.DirectoryFlagName, dir, cli.FormatFlagName, commands.ManDocsFormat},
+			Prepare: func(t *testing.T, ctx context.Context, c *cli.Config) (context.Context, error) {
+				// ensure the directory is empty
+				os.RemoveAll(dir)
+				return ctx, nil
+			},
+			CleanUp: func(t *testing.T, ctx context.Context, c *cli.Config) error {
+				files, err := ioutil.ReadDir(dir)
+				if err != nil {
+					t.Error(err)
+				}
+				if expected, actual := 1, len(files); expected != actual {
+					t.Errorf("expected %d file, found %d files", expected, actual)
+				} else if expected, actual := "docs.1", files[0].Name(); expected != actual {
+					t.Errorf("expected file name %q, found %q", expected, actual)
+				}
+				return os.RemoveAll(dir)
+			},
+		},
+		{
+			Name: "generate json",
+			Args: []string{cli.DirectoryFlagName, dir, cli.FormatFlagName, commands.JSONDocsFormat},
+			Prepare: func(t *testing.T, ctx context.Context, c *cli.Config) (context.Context, error) {
+				// ensure the directory is empty
+				os.RemoveAll(dir)
+				return ctx, nil
+			},
+			CleanUp: func(t *testing.T, ctx context.Context, c *cli.Config) error {
+				b, err := ioutil.ReadFile(filepath.Join(dir, "docs.json"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				docs := map[string]interface{}{}
+				if err := json.Unmarshal(b, &docs); err != nil {
+					t.Fatal(err)
+				}
+				if expected, actual := "docs", docs["name"]; expected != actual {
+					t.Errorf("expected name %q, found %q", expected, actual)
+				}
+				flags, _ := docs["flags"].([]interface{})
+				if expected, actual := 3, len(flags); expected != actual {
+					t.Errorf("expected %d flags, found %d flags", expected, actual)
+				}
+				return os.RemoveAll(dir)
+			},
+		},
 	}
 
 	table.Run(t, commands.NewDocsCommand)
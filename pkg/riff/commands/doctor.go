@@ -18,19 +18,36 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
+	"github.com/ghodss/yaml"
 	"github.com/projectriff/cli/pkg/cli"
 	"github.com/projectriff/cli/pkg/cli/printers"
+	"github.com/projectriff/system/pkg/apis/build"
+	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/projectriff/system/pkg/apis/core/v1alpha1"
+	knativev1alpha1 "github.com/projectriff/system/pkg/apis/knative/v1alpha1"
+	streamv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
 	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
 	authv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	DoctorOutputJSON = "json"
+	DoctorOutputYAML = "yaml"
+)
+
 type DoctorOptions struct {
 	Namespace string
+	Runtimes  []string
+	Output    string
 }
 
 var (
@@ -45,39 +62,95 @@ func (opts *DoctorOptions) Validate(ctx context.Context) *cli.FieldError {
 		errs = errs.Also(cli.ErrMissingField(cli.NamespaceFlagName))
 	}
 
+	for i, runtime := range opts.Runtimes {
+		if _, ok := findDoctorRuntime(runtime); !ok {
+			errs = errs.Also(cli.ErrInvalidArrayValue(runtime, cli.RuntimeFlagName, i))
+		}
+	}
+
+	if opts.Output != "" && opts.Output != DoctorOutputJSON && opts.Output != DoctorOutputYAML {
+		errs = errs.Also(cli.ErrInvalidValue(opts.Output, cli.OutputFlagName))
+	}
+
 	return errs
 }
 
 func (opts *DoctorOptions) Exec(ctx context.Context, c *cli.Config) error {
+	runtimes, err := opts.selectRuntimes(c)
+	if err != nil {
+		return err
+	}
+
 	riffNamespaces := []string{
 		"riff-system",
 	}
-	err := opts.checkNamespaces(c, riffNamespaces)
+	namespaces, err := opts.checkNamespaces(c, riffNamespaces)
+	if err != nil {
+		return err
+	}
+
+	components, err := opts.buildChecks().Resolve(c)
+	if err != nil {
+		return err
+	}
+	runtimeResults := []doctorRuntimeResult{}
+	checkedRuntimes := []doctorRuntime{}
+	for _, runtime := range runtimes {
+		result, runtimeComponents, err := runtime.Resolve(c, len(opts.Runtimes) != 0)
+		if err != nil {
+			return err
+		}
+		runtimeResults = append(runtimeResults, result)
+		if result.Status == doctorRuntimeMissing && !result.Required {
+			// the runtime is not in use, skip checking its components and access
+			continue
+		}
+		components = append(components, runtimeComponents...)
+		checkedRuntimes = append(checkedRuntimes, runtime)
+	}
+	systemComponents, err := opts.systemChecks().Resolve(c)
 	if err != nil {
 		return err
 	}
+	components = append(components, systemComponents...)
 
-	verbs := []string{"get", "list", "create", "update", "delete", "patch", "watch"}
-	readVerbs := []string{"get", "list", "watch"}
-	accessChecks := doctorAccessChecks{
-		{Attributes: &authv1.ResourceAttributes{Namespace: opts.Namespace, Group: "core", Resource: "configmaps"}, Verbs: verbs},
-		{Attributes: &authv1.ResourceAttributes{Namespace: opts.Namespace, Group: "core", Resource: "secrets"}, Verbs: verbs},
-		{Attributes: &authv1.ResourceAttributes{Namespace: opts.Namespace, Group: "core", Resource: "pods"}, Verbs: readVerbs},
-		{Attributes: &authv1.ResourceAttributes{Namespace: opts.Namespace, Group: "core", Resource: "pods", Subresource: "log"}, Verbs: readVerbs},
-		{Attributes: &authv1.ResourceAttributes{Namespace: opts.Namespace, Group: "build.projectriff.io", Resource: "applications"}, Verbs: verbs},
-		{Attributes: &authv1.ResourceAttributes{Namespace: opts.Namespace, Group: "build.projectriff.io", Resource: "containers"}, Verbs: verbs},
-		{Attributes: &authv1.ResourceAttributes{Namespace: opts.Namespace, Group: "build.projectriff.io", Resource: "functions"}, Verbs: verbs},
-		{Attributes: &authv1.ResourceAttributes{Namespace: opts.Namespace, Group: "core.projectriff.io", Resource: "deployers"}, Verbs: verbs},
-		{Attributes: &authv1.ResourceAttributes{Namespace: opts.Namespace, Group: "streaming.projectriff.io", Resource: "processors"}, Verbs: verbs},
-		{Attributes: &authv1.ResourceAttributes{Namespace: opts.Namespace, Group: "streaming.projectriff.io", Resource: "streams"}, Verbs: verbs},
-		{Attributes: &authv1.ResourceAttributes{Namespace: opts.Namespace, Group: "knative.projectriff.io", Resource: "adapters"}, Verbs: verbs},
-		{Attributes: &authv1.ResourceAttributes{Namespace: opts.Namespace, Group: "knative.projectriff.io", Resource: "deployers"}, Verbs: verbs},
-	}
-	err = opts.checkAccess(c, accessChecks)
+	builds, err := (&doctorBuildCheck{Namespace: opts.Namespace}).Resolve(c)
 	if err != nil {
 		return err
 	}
 
+	accessChecks := doctorResources(opts.Namespace, checkedRuntimes)
+	err = accessChecks.ResolveStatus(c)
+	if err != nil {
+		return err
+	}
+
+	report := newDoctorReport(namespaces, runtimeResults, components, builds, accessChecks)
+	switch opts.Output {
+	case DoctorOutputJSON:
+		b, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		c.Printf("%s\n", b)
+	case DoctorOutputYAML:
+		b, err := yaml.Marshal(report)
+		if err != nil {
+			return err
+		}
+		c.Printf("%s", b)
+	default:
+		opts.printNamespaces(c, namespaces)
+		opts.printRuntimes(c, runtimeResults)
+		opts.printComponents(c, components)
+		opts.printBuilds(c, builds)
+		opts.printAccess(c, accessChecks)
+		opts.printHints(c, report)
+	}
+
+	if !report.Healthy {
+		return cli.SilenceError(fmt.Errorf("%d doctor checks failed", len(report.Failures())))
+	}
 	return nil
 }
 
@@ -92,59 +165,228 @@ func NewDoctorCommand(ctx context.Context, c *cli.Config) *cobra.Command {
 Check that ` + c.Name + ` is installed.
 
 The doctor checks that necessary system components are installed and the user
-has access to resources in the namespace.
+has access to resources in the namespace. Installed custom resources must serve
+the API versions this CLI expects, builders must be registered, and system
+controllers must be available.
+
+Each runtime enabled in this CLI is reported as installed, incomplete or
+missing. Runtimes that are not installed are skipped, unless they are requested
+with ` + cli.RuntimeFlagName + `, which limits the checks to the named runtimes.
+
+The namespace is checked for what builds need: the 'riff-build' ConfigMap with a
+default image prefix, at least one credential, and the 'riff-build' service
+account with the credentials attached.
+
+Each failed check is listed with a hint for how to fix it, and the command exits
+with a non-zero status. Use ` + cli.OutputFlagName + ` json or yaml to print the results in a
+machine readable format, for example when gating a cluster provisioning pipeline.
 
 The doctor is not a tool for monitoring the health of the cluster.
 `),
-		Example: "riff doctor",
+		Example: strings.Join([]string{
+			fmt.Sprintf("%s doctor", c.Name),
+			fmt.Sprintf("%s doctor %s %s", c.Name, cli.RuntimeFlagName, cli.CoreRuntime),
+			fmt.Sprintf("%s doctor %s json", c.Name, cli.OutputFlagName),
+		}, "\n"),
 		PreRunE: cli.ValidateOptions(ctx, opts),
 		RunE:    cli.ExecOptions(ctx, c, opts),
 	}
 
 	cli.NamespaceFlag(cmd, c, &opts.Namespace)
+	cmd.Flags().StringArrayVar(&opts.Runtimes, cli.StripDash(cli.RuntimeFlagName), []string{}, "`name` of runtime to check, one of core, streaming or knative (may be set multiple times, default all enabled runtimes that are installed)")
+	cmd.Flags().StringVarP(&opts.Output, cli.StripDash(cli.OutputFlagName), "o", "", fmt.Sprintf("output `format`, one of %q or %q (default human readable)", DoctorOutputJSON, DoctorOutputYAML))
+
+	cli.CompleteFlagValues(cmd, cli.RuntimeFlagName, cli.CoreRuntime, cli.StreamingRuntime, cli.KnativeRuntime)
+	cli.CompleteFlagValues(cmd, cli.OutputFlagName, DoctorOutputJSON, DoctorOutputYAML)
 
 	return cmd
 }
 
-func (*DoctorOptions) checkNamespaces(c *cli.Config, requiredNamespaces []string) error {
+// selectRuntimes resolves the runtimes to check, defaulting to every runtime enabled in the CLI
+func (opts *DoctorOptions) selectRuntimes(c *cli.Config) ([]doctorRuntime, error) {
+	runtimes := []doctorRuntime{}
+	if len(opts.Runtimes) == 0 {
+		for _, runtime := range doctorRuntimes {
+			if c.Runtimes[runtime.Name] {
+				runtimes = append(runtimes, runtime)
+			}
+		}
+		return runtimes, nil
+	}
+	for _, runtime := range doctorRuntimes {
+		if !containsString(opts.Runtimes, runtime.Name) {
+			continue
+		}
+		if !c.Runtimes[runtime.Name] {
+			return nil, fmt.Errorf("runtime %q is not enabled in this %s CLI", runtime.Name, c.Name)
+		}
+		runtimes = append(runtimes, runtime)
+	}
+	return runtimes, nil
+}
 
-	printer := printers.GetNewTabWriter(c.Stdout)
-	defer printer.Flush()
-	fmt.Fprintf(printer, "NAMESPACE\tSTATUS\n")
-	for _, namespace := range requiredNamespaces {
-		status := cli.Ssuccessf("ok")
+func (*DoctorOptions) checkNamespaces(c *cli.Config, requiredNamespaces []string) ([]doctorComponentResult, error) {
+	results := make([]doctorComponentResult, len(requiredNamespaces))
+	for i, namespace := range requiredNamespaces {
+		result := doctorComponentResult{Name: namespace, Status: doctorComponentOK}
 		_, err := c.Core().Namespaces().Get(namespace, metav1.GetOptions{})
-		if err != nil {
+		if errors.IsForbidden(err) {
+			result = result.forbidden(fmt.Sprintf("namespace %s", namespace))
+		} else if err != nil {
 			if !errors.IsNotFound(err) {
-				return err
+				return nil, err
 			}
-			status = cli.Serrorf("missing")
+			result.Status = doctorComponentMissing
+			result.Hint = fmt.Sprintf("install %s", c.Name)
 		}
-		fmt.Fprintf(printer, "%s\t%s\n", namespace, status)
+		results[i] = result
 	}
-	return nil
+	return results, nil
 }
 
-func (*DoctorOptions) checkAccess(c *cli.Config, accessChecks doctorAccessChecks) error {
-	err := accessChecks.ResolveStatus(c)
-	if err != nil {
-		return err
+func (*DoctorOptions) buildChecks() doctorComponentChecks {
+	return doctorComponentChecks{
+		&doctorCRDCheck{GroupResource: "applications.build.projectriff.io", Version: buildv1alpha1.SchemeGroupVersion.Version, Component: "riff build"},
+		&doctorCRDCheck{GroupResource: "containers.build.projectriff.io", Version: buildv1alpha1.SchemeGroupVersion.Version, Component: "riff build"},
+		&doctorCRDCheck{GroupResource: "functions.build.projectriff.io", Version: buildv1alpha1.SchemeGroupVersion.Version, Component: "riff build"},
+	}
+}
+
+func (*DoctorOptions) systemChecks() doctorComponentChecks {
+	return doctorComponentChecks{
+		&doctorBuilderCheck{Builder: "riff-application"},
+		&doctorBuilderCheck{Builder: "riff-function"},
+		&doctorControllersCheck{Namespace: "riff-system"},
+	}
+}
+
+func (*DoctorOptions) printNamespaces(c *cli.Config, namespaces []doctorComponentResult) {
+	printer := printers.GetNewTabWriter(c.Stdout)
+	defer printer.Flush()
+	fmt.Fprintf(printer, "NAMESPACE\tSTATUS\n")
+	for _, namespace := range namespaces {
+		fmt.Fprintf(printer, "%s\t%s\n", namespace.Name, namespace.Status.String())
+	}
+}
+
+func (*DoctorOptions) printRuntimes(c *cli.Config, runtimes []doctorRuntimeResult) {
+	c.Printf("\n")
+	printer := printers.GetNewTabWriter(c.Stdout)
+	defer printer.Flush()
+	fmt.Fprintf(printer, "RUNTIME\tSTATUS\tDETAILS\n")
+	for _, result := range runtimes {
+		fmt.Fprintf(printer, "%s\t%s\t%s\n", result.Name, result.StatusString(), result.Details)
+	}
+}
+
+func (*DoctorOptions) printComponents(c *cli.Config, components []doctorComponentResult) {
+	c.Printf("\n")
+	printer := printers.GetNewTabWriter(c.Stdout)
+	defer printer.Flush()
+	fmt.Fprintf(printer, "COMPONENT\tSTATUS\tDETAILS\n")
+	for _, result := range components {
+		fmt.Fprintf(printer, "%s\t%s\t%s\n", result.Name, result.Status.String(), result.Details)
 	}
+}
+
+func (*DoctorOptions) printBuilds(c *cli.Config, builds []doctorComponentResult) {
+	c.Printf("\n")
+	printer := printers.GetNewTabWriter(c.Stdout)
+	defer printer.Flush()
+	fmt.Fprintf(printer, "BUILD\tSTATUS\tDETAILS\n")
+	for _, result := range builds {
+		fmt.Fprintf(printer, "%s\t%s\t%s\n", result.Name, result.Status.String(), result.Details)
+	}
+}
+
+func (*DoctorOptions) printAccess(c *cli.Config, accessChecks doctorAccessChecks) {
 	c.Printf("\n")
 	printer := printers.GetNewTabWriter(c.Stdout)
 	defer printer.Flush()
 	fmt.Fprintf(printer, "RESOURCE\tREAD\tWRITE\n")
 	for _, check := range accessChecks {
-		resource := check.Attributes.Resource
-		if check.Attributes.Group != "core" {
-			resource = fmt.Sprintf("%s.%s", resource, check.Attributes.Group)
-		}
-		if check.Attributes.Subresource != "" {
-			resource = fmt.Sprintf("%s/%s", resource, check.Attributes.Subresource)
+		fmt.Fprintf(printer, "%s\t%s\t%s\n", check.Name(), check.ReadStatus.String(), check.WriteStatus.String())
+	}
+}
+
+func (*DoctorOptions) printHints(c *cli.Config, report *doctorReport) {
+	failures := report.Failures()
+	if len(failures) == 0 {
+		return
+	}
+	c.Printf("\n")
+	printer := printers.GetNewTabWriter(c.Stdout)
+	defer printer.Flush()
+	fmt.Fprintf(printer, "CHECK\tNAME\tHINT\n")
+	for _, check := range failures {
+		fmt.Fprintf(printer, "%s\t%s\t%s\n", check.Type, check.Name, check.Hint)
+	}
+}
+
+// doctorReport is the machine readable form of the doctor's results
+type doctorReport struct {
+	Healthy bool                `json:"healthy"`
+	Checks  []doctorReportCheck `json:"checks"`
+}
+
+type doctorReportCheck struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Healthy bool   `json:"healthy"`
+	Read    string `json:"read,omitempty"`
+	Write   string `json:"write,omitempty"`
+	Details string `json:"details,omitempty"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+func newDoctorReport(namespaces []doctorComponentResult, runtimes []doctorRuntimeResult, components, builds []doctorComponentResult, accessChecks doctorAccessChecks) *doctorReport {
+	report := &doctorReport{Healthy: true, Checks: []doctorReportCheck{}}
+	add := func(check doctorReportCheck) {
+		report.Checks = append(report.Checks, check)
+		report.Healthy = report.Healthy && check.Healthy
+	}
+	for _, namespace := range namespaces {
+		add(namespace.reportCheck("namespace"))
+	}
+	for _, runtime := range runtimes {
+		add(doctorReportCheck{
+			Type:    "runtime",
+			Name:    runtime.Name,
+			Status:  runtime.Status.Name(),
+			Healthy: runtime.IsHealthy(),
+			Details: runtime.Details,
+			Hint:    runtime.Hint,
+		})
+	}
+	for _, component := range components {
+		add(component.reportCheck("component"))
+	}
+	for _, build := range builds {
+		add(build.reportCheck("build"))
+	}
+	for _, check := range accessChecks {
+		add(doctorReportCheck{
+			Type:    "access",
+			Name:    check.Name(),
+			Status:  check.Status().Name(),
+			Healthy: check.Status().IsHealthy(),
+			Read:    check.ReadStatus.Name(),
+			Write:   check.WriteStatus.Name(),
+			Hint:    check.Hint(),
+		})
+	}
+	return report
+}
+
+func (report *doctorReport) Failures() []doctorReportCheck {
+	failures := []doctorReportCheck{}
+	for _, check := range report.Checks {
+		if !check.Healthy {
+			failures = append(failures, check)
 		}
-		fmt.Fprintf(printer, "%s\t%s\t%s\n", resource, check.ReadStatus.String(), check.WriteStatus.String())
 	}
-	return nil
+	return failures
 }
 
 type doctorAccessCheck struct {
@@ -154,6 +396,50 @@ type doctorAccessCheck struct {
 	WriteStatus doctorAccessStatus
 }
 
+// Name is the resource the check reviews access to, qualified by its group and subresource
+func (check *doctorAccessCheck) Name() string {
+	resource := check.Attributes.Resource
+	if check.Attributes.Group != "core" {
+		resource = fmt.Sprintf("%s.%s", resource, check.Attributes.Group)
+	}
+	if check.Attributes.Subresource != "" {
+		resource = fmt.Sprintf("%s/%s", resource, check.Attributes.Subresource)
+	}
+	return resource
+}
+
+// Status is the first unhealthy status of the read and write checks, or allowed
+func (check *doctorAccessCheck) Status() doctorAccessStatus {
+	if !check.ReadStatus.IsHealthy() {
+		return check.ReadStatus
+	}
+	if !check.WriteStatus.IsHealthy() {
+		return check.WriteStatus
+	}
+	return doctorAccessAllowed
+}
+
+// Hint suggests how to fix an unhealthy check
+func (check *doctorAccessCheck) Hint() string {
+	if check.ReadStatus == doctorAccessMissing {
+		return fmt.Sprintf("install the custom resource definition for %s", check.Name())
+	}
+	if check.ReadStatus == doctorAccessUnknown || check.WriteStatus == doctorAccessUnknown {
+		return fmt.Sprintf("access could not be determined, ask a cluster admin to review the rules for %s in namespace %s", check.Name(), check.Attributes.Namespace)
+	}
+	access := []string{}
+	if !check.ReadStatus.IsHealthy() {
+		access = append(access, "read")
+	}
+	if !check.WriteStatus.IsHealthy() {
+		access = append(access, "write")
+	}
+	if len(access) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("ask a cluster admin to grant %s access to %s in namespace %s", strings.Join(access, " and "), check.Name(), check.Attributes.Namespace)
+}
+
 func (check *doctorAccessCheck) ResolveStatus(c *cli.Config) error {
 	if strings.Contains(check.Attributes.Group, ".") {
 		missing, err := check.isCustomResourceMissing(c, fmt.Sprintf("%s.%s", check.Attributes.Resource, check.Attributes.Group))
@@ -202,6 +488,10 @@ func (check *doctorAccessCheck) isCustomResourceMissing(c *cli.Config, name stri
 	if errors.IsNotFound(err) {
 		return true, nil
 	}
+	if errors.IsForbidden(err) {
+		// the access review reports on the resource without reading its definition
+		return false, nil
+	}
 	return false, err
 }
 
@@ -218,10 +508,7 @@ func (checks doctorAccessChecks) ResolveStatus(c *cli.Config) error {
 
 func (checks doctorAccessChecks) IsHealthy() bool {
 	for _, check := range checks {
-		if check.ReadStatus != doctorAccessAllowed && check.ReadStatus != doctorAccessUndefined {
-			return false
-		}
-		if check.WriteStatus != doctorAccessAllowed && check.WriteStatus != doctorAccessUndefined {
+		if !check.Status().IsHealthy() {
 			return false
 		}
 	}
@@ -255,18 +542,503 @@ func (das doctorAccessStatus) Combine(new doctorAccessStatus) doctorAccessStatus
 	return doctorAccessDenied
 }
 
-func (das doctorAccessStatus) String() string {
+func (das doctorAccessStatus) IsHealthy() bool {
+	return das == doctorAccessAllowed || das == doctorAccessUndefined
+}
+
+// Name is the status without color
+func (das doctorAccessStatus) Name() string {
 	switch das {
 	case doctorAccessAllowed:
-		return cli.Ssuccessf("allowed")
+		return "allowed"
 	case doctorAccessMixed:
-		return cli.Swarnf("mixed")
+		return "mixed"
 	case doctorAccessDenied:
-		return cli.Swarnf("denied")
+		return "denied"
 	case doctorAccessMissing:
-		return cli.Serrorf("missing")
+		return "missing"
 	case doctorAccessUnknown:
-		return cli.Serrorf("unknown")
+		return "unknown"
 	}
 	return "n/a"
 }
+
+func (das doctorAccessStatus) String() string {
+	switch das {
+	case doctorAccessAllowed:
+		return cli.Ssuccessf(das.Name())
+	case doctorAccessMixed, doctorAccessDenied:
+		return cli.Swarnf(das.Name())
+	case doctorAccessMissing, doctorAccessUnknown:
+		return cli.Serrorf(das.Name())
+	}
+	return das.Name()
+}
+
+// doctorComponentCheck checks that a system component is installed and healthy. A check may
+// resolve to many results, one for each instance of the component found.
+type doctorComponentCheck interface {
+	Resolve(c *cli.Config) ([]doctorComponentResult, error)
+}
+
+type doctorComponentResult struct {
+	Name    string
+	Status  doctorComponentStatus
+	Details string
+	Hint    string
+}
+
+func (result doctorComponentResult) reportCheck(checkType string) doctorReportCheck {
+	return doctorReportCheck{
+		Type:    checkType,
+		Name:    result.Name,
+		Status:  result.Status.Name(),
+		Healthy: result.Status == doctorComponentOK,
+		Details: result.Details,
+		Hint:    result.Hint,
+	}
+}
+
+// forbidden records that the component could not be read with the user's access
+func (result doctorComponentResult) forbidden(resource string) doctorComponentResult {
+	result.Status = doctorComponentForbidden
+	result.Details = fmt.Sprintf("not allowed to read %s", resource)
+	result.Hint = fmt.Sprintf("ask a cluster admin to check %s, or to grant you read access", resource)
+	return result
+}
+
+type doctorComponentChecks []doctorComponentCheck
+
+func (checks doctorComponentChecks) Resolve(c *cli.Config) ([]doctorComponentResult, error) {
+	results := []doctorComponentResult{}
+	for _, check := range checks {
+		result, err := check.Resolve(c)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result...)
+	}
+	return results, nil
+}
+
+// doctorCRDCheck checks that a custom resource definition is installed and serves the expected
+// version. Any version is accepted when the expected version is empty. The component is what
+// the user installs to provide the definition.
+type doctorCRDCheck struct {
+	GroupResource string
+	Version       string
+	Component     string
+}
+
+func (check *doctorCRDCheck) Resolve(c *cli.Config) ([]doctorComponentResult, error) {
+	result := doctorComponentResult{Name: check.GroupResource}
+	crd, err := c.APIExtension().CustomResourceDefinitions().Get(check.GroupResource, metav1.GetOptions{})
+	if errors.IsForbidden(err) {
+		return []doctorComponentResult{result.forbidden("custom resource definitions")}, nil
+	}
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		result.Status = doctorComponentMissing
+		result.Hint = fmt.Sprintf("install %s", check.Component)
+		return []doctorComponentResult{result}, nil
+	}
+	versions := []string{}
+	for _, version := range crd.Spec.Versions {
+		if version.Served {
+			versions = append(versions, version.Name)
+		}
+	}
+	if len(versions) == 0 && crd.Spec.Version != "" {
+		versions = append(versions, crd.Spec.Version)
+	}
+	result.Status = doctorComponentOK
+	result.Details = strings.Join(versions, ", ")
+	if check.Version != "" && !containsString(versions, check.Version) {
+		result.Status = doctorComponentIncompatible
+		result.Details = fmt.Sprintf("serves %s, expected %s", strings.Join(versions, ", "), check.Version)
+		if len(versions) == 0 {
+			result.Details = fmt.Sprintf("serves no versions, expected %s", check.Version)
+		}
+		result.Hint = fmt.Sprintf("install a version of %s that serves %s, or a %s CLI matching the installed version", check.Component, check.Version, c.Name)
+	}
+	return []doctorComponentResult{result}, nil
+}
+
+// doctorBuilderCheck checks that a builder image is registered in the builders ConfigMap
+type doctorBuilderCheck struct {
+	Builder string
+}
+
+func (check *doctorBuilderCheck) Resolve(c *cli.Config) ([]doctorComponentResult, error) {
+	result := doctorComponentResult{Name: fmt.Sprintf("builder/%s", check.Builder)}
+	builders, err := c.Core().ConfigMaps("riff-system").Get("builders", metav1.GetOptions{})
+	if errors.IsForbidden(err) {
+		return []doctorComponentResult{result.forbidden("configmap riff-system/builders")}, nil
+	}
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		result.Status = doctorComponentMissing
+		result.Details = "configmap riff-system/builders not found"
+		result.Hint = "install riff build to register the builder"
+		return []doctorComponentResult{result}, nil
+	}
+	image := builders.Data[check.Builder]
+	if image == "" {
+		result.Status = doctorComponentMissing
+		result.Hint = "install riff build to register the builder"
+		return []doctorComponentResult{result}, nil
+	}
+	result.Status = doctorComponentOK
+	result.Details = image
+	return []doctorComponentResult{result}, nil
+}
+
+// doctorControllersCheck checks that each deployment in the namespace is available
+type doctorControllersCheck struct {
+	Namespace string
+}
+
+func (check *doctorControllersCheck) Resolve(c *cli.Config) ([]doctorComponentResult, error) {
+	deployments, err := c.Apps().Deployments(check.Namespace).List(metav1.ListOptions{})
+	if errors.IsForbidden(err) {
+		result := doctorComponentResult{Name: "controllers"}
+		return []doctorComponentResult{result.forbidden(fmt.Sprintf("deployments in %s", check.Namespace))}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(deployments.Items) == 0 {
+		return []doctorComponentResult{{
+			Name:    "controllers",
+			Status:  doctorComponentMissing,
+			Details: fmt.Sprintf("no deployments found in %s", check.Namespace),
+			Hint:    fmt.Sprintf("install %s", c.Name),
+		}}, nil
+	}
+	sort.Slice(deployments.Items, func(i, j int) bool {
+		return deployments.Items[i].Name < deployments.Items[j].Name
+	})
+	results := make([]doctorComponentResult, len(deployments.Items))
+	for i, deployment := range deployments.Items {
+		result := doctorComponentResult{
+			Name:    fmt.Sprintf("deployment/%s", deployment.Name),
+			Status:  doctorComponentUnavailable,
+			Details: fmt.Sprintf("%d of %d replicas available", deployment.Status.AvailableReplicas, deployment.Status.Replicas),
+			Hint:    fmt.Sprintf("run %q to find the cause", fmt.Sprintf("kubectl -n %s describe deployment %s", check.Namespace, deployment.Name)),
+		}
+		for _, cond := range deployment.Status.Conditions {
+			if cond.Type != appsv1.DeploymentAvailable {
+				continue
+			}
+			if cond.Status == corev1.ConditionTrue {
+				result.Status = doctorComponentOK
+				result.Hint = ""
+			} else if cond.Message != "" {
+				result.Details = cond.Message
+			}
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// doctorResources are the access checks for the resources a user needs in the namespace,
+// including the resources of each runtime
+func doctorResources(namespace string, runtimes []doctorRuntime) doctorAccessChecks {
+	names := map[string]bool{}
+	for _, runtime := range runtimes {
+		names[runtime.Name] = true
+	}
+	checks := doctorAccessChecks{}
+	for _, resource := range namespaceUserResources(namespace, names) {
+		attributes := resource.Attributes
+		checks = append(checks, &doctorAccessCheck{Attributes: &attributes, Verbs: resource.Verbs})
+	}
+	return checks
+}
+
+// doctorRuntime describes the custom resources a runtime installs
+type doctorRuntime struct {
+	Name      string
+	Component string
+	CRDs      []*doctorCRDCheck
+}
+
+var doctorRuntimes = []doctorRuntime{
+	{
+		Name:      cli.CoreRuntime,
+		Component: "the riff core runtime",
+		CRDs: []*doctorCRDCheck{
+			{GroupResource: "deployers.core.projectriff.io", Version: corev1alpha1.SchemeGroupVersion.Version, Component: "the riff core runtime"},
+		},
+	},
+	{
+		Name:      cli.StreamingRuntime,
+		Component: "the riff streaming runtime",
+		CRDs: []*doctorCRDCheck{
+			{GroupResource: "processors.streaming.projectriff.io", Version: streamv1alpha1.SchemeGroupVersion.Version, Component: "the riff streaming runtime"},
+			{GroupResource: "streams.streaming.projectriff.io", Version: streamv1alpha1.SchemeGroupVersion.Version, Component: "the riff streaming runtime"},
+		},
+	},
+	{
+		Name:      cli.KnativeRuntime,
+		Component: "the riff knative runtime",
+		CRDs: []*doctorCRDCheck{
+			{GroupResource: "adapters.knative.projectriff.io", Version: knativev1alpha1.SchemeGroupVersion.Version, Component: "the riff knative runtime"},
+			{GroupResource: "deployers.knative.projectriff.io", Version: knativev1alpha1.SchemeGroupVersion.Version, Component: "the riff knative runtime"},
+			// knative serving may serve any version, only its presence is checked
+			{GroupResource: "configurations.serving.knative.dev", Component: "Knative Serving"},
+			{GroupResource: "revisions.serving.knative.dev", Component: "Knative Serving"},
+			{GroupResource: "routes.serving.knative.dev", Component: "Knative Serving"},
+			{GroupResource: "services.serving.knative.dev", Component: "Knative Serving"},
+		},
+	},
+}
+
+func findDoctorRuntime(name string) (doctorRuntime, bool) {
+	for _, runtime := range doctorRuntimes {
+		if runtime.Name == name {
+			return runtime, true
+		}
+	}
+	return doctorRuntime{}, false
+}
+
+// Resolve checks the runtime's custom resources. A runtime is installed when all of its custom
+// resources are found. Required runtimes must be installed to be healthy.
+func (runtime doctorRuntime) Resolve(c *cli.Config, required bool) (doctorRuntimeResult, []doctorComponentResult, error) {
+	result := doctorRuntimeResult{Name: runtime.Name, Required: required}
+	checks := doctorComponentChecks{}
+	for _, check := range runtime.CRDs {
+		checks = append(checks, check)
+	}
+	components, err := checks.Resolve(c)
+	if err != nil {
+		return result, nil, err
+	}
+	missing, forbidden := 0, 0
+	for _, component := range components {
+		switch component.Status {
+		case doctorComponentMissing:
+			missing++
+		case doctorComponentForbidden:
+			forbidden++
+		}
+	}
+	switch {
+	case forbidden != 0:
+		result.Status = doctorRuntimeUnknown
+		result.Details = fmt.Sprintf("%d of %d custom resources could not be read", forbidden, len(components))
+		result.Hint = fmt.Sprintf("ask a cluster admin to check the %s runtime is installed", runtime.Name)
+	case missing == 0:
+		result.Status = doctorRuntimeInstalled
+	case missing == len(components):
+		result.Status = doctorRuntimeMissing
+		result.Details = "not installed"
+		if required {
+			result.Hint = fmt.Sprintf("install %s", runtime.Component)
+		} else {
+			result.Details = "not installed, skipped"
+		}
+	default:
+		result.Status = doctorRuntimeIncomplete
+		result.Details = fmt.Sprintf("%d of %d custom resources installed", len(components)-missing, len(components))
+		result.Hint = fmt.Sprintf("install the missing components of the %s runtime", runtime.Name)
+	}
+	return result, components, nil
+}
+
+type doctorRuntimeResult struct {
+	Name     string
+	Status   doctorRuntimeStatus
+	Required bool
+	Details  string
+	Hint     string
+}
+
+// IsHealthy is true when the runtime is installed, or is missing and was not required
+func (result doctorRuntimeResult) IsHealthy() bool {
+	return result.Status == doctorRuntimeInstalled || (result.Status == doctorRuntimeMissing && !result.Required)
+}
+
+func (result doctorRuntimeResult) StatusString() string {
+	if result.IsHealthy() && result.Status != doctorRuntimeInstalled {
+		return cli.Swarnf(result.Status.Name())
+	}
+	return result.Status.String()
+}
+
+type doctorRuntimeStatus int
+
+const (
+	doctorRuntimeInstalled  doctorRuntimeStatus = iota
+	doctorRuntimeMissing                        /* none of the runtime's custom resources are installed */
+	doctorRuntimeIncomplete                     /* some of the runtime's custom resources are installed */
+	doctorRuntimeUnknown                        /* the runtime's custom resources could not be read */
+)
+
+// Name is the status without color
+func (drs doctorRuntimeStatus) Name() string {
+	switch drs {
+	case doctorRuntimeInstalled:
+		return "installed"
+	case doctorRuntimeMissing:
+		return "missing"
+	case doctorRuntimeIncomplete:
+		return "incomplete"
+	case doctorRuntimeUnknown:
+		return "unknown"
+	}
+	return "n/a"
+}
+
+func (drs doctorRuntimeStatus) String() string {
+	switch drs {
+	case doctorRuntimeInstalled:
+		return cli.Ssuccessf(drs.Name())
+	case doctorRuntimeMissing, doctorRuntimeIncomplete:
+		return cli.Serrorf(drs.Name())
+	case doctorRuntimeUnknown:
+		return cli.Swarnf(drs.Name())
+	}
+	return drs.Name()
+}
+
+// doctorBuildCheck checks that a namespace is ready to run builds. Builds resolve their image
+// from the default image prefix and push with the credentials attached to the build service
+// account.
+type doctorBuildCheck struct {
+	Namespace string
+}
+
+func (check *doctorBuildCheck) Resolve(c *cli.Config) ([]doctorComponentResult, error) {
+	applyHint := fmt.Sprintf("run %q", fmt.Sprintf("%s credential apply my-creds %s %s %s my-docker-id %s", c.Name, cli.NamespaceFlagName, check.Namespace, cli.DockerHubFlagName, cli.SetDefaultImagePrefixFlagName))
+
+	config := doctorComponentResult{Name: "configmap/riff-build", Status: doctorComponentOK}
+	riffBuildConfig, err := c.Core().ConfigMaps(check.Namespace).Get("riff-build", metav1.GetOptions{})
+	if errors.IsForbidden(err) {
+		config = config.forbidden(fmt.Sprintf("configmap %s/riff-build", check.Namespace))
+	} else if err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		config.Status = doctorComponentMissing
+		config.Hint = applyHint
+	} else if prefix := riffBuildConfig.Data["default-image-prefix"]; prefix == "" {
+		config.Status = doctorComponentMissing
+		config.Details = "default-image-prefix not set"
+		config.Hint = applyHint
+	} else {
+		config.Details = prefix
+	}
+
+	credentials := doctorComponentResult{Name: "credentials", Status: doctorComponentOK}
+	secrets, err := c.Core().Secrets(check.Namespace).List(metav1.ListOptions{
+		LabelSelector: build.CredentialLabelKey,
+	})
+	if err != nil && !errors.IsForbidden(err) {
+		return nil, err
+	}
+	names := []string{}
+	if secrets != nil {
+		for _, secret := range secrets.Items {
+			names = append(names, secret.Name)
+		}
+	}
+	sort.Strings(names)
+	if errors.IsForbidden(err) {
+		credentials = credentials.forbidden(fmt.Sprintf("secrets in %s", check.Namespace))
+	} else if len(names) == 0 {
+		credentials.Status = doctorComponentMissing
+		credentials.Details = fmt.Sprintf("no credentials found in %s", check.Namespace)
+		credentials.Hint = applyHint
+	} else {
+		credentials.Details = strings.Join(names, ", ")
+	}
+
+	serviceAccount := doctorComponentResult{Name: "serviceaccount/riff-build", Status: doctorComponentOK}
+	sa, err := c.Core().ServiceAccounts(check.Namespace).Get("riff-build", metav1.GetOptions{})
+	if errors.IsForbidden(err) {
+		serviceAccount = serviceAccount.forbidden(fmt.Sprintf("serviceaccount %s/riff-build", check.Namespace))
+	} else if err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		serviceAccount.Status = doctorComponentMissing
+		serviceAccount.Hint = "the build controller creates the service account once a credential is applied, check it is available"
+		if len(names) == 0 {
+			serviceAccount.Hint = applyHint
+		}
+	} else {
+		attached := []string{}
+		for _, ref := range sa.Secrets {
+			attached = append(attached, ref.Name)
+		}
+		unattached := []string{}
+		for _, name := range names {
+			if !containsString(attached, name) {
+				unattached = append(unattached, name)
+			}
+		}
+		serviceAccount.Details = strings.Join(attached, ", ")
+		if len(unattached) != 0 {
+			serviceAccount.Status = doctorComponentUnavailable
+			serviceAccount.Details = fmt.Sprintf("credentials not attached: %s", strings.Join(unattached, ", "))
+			serviceAccount.Hint = "the build controller attaches credentials to the service account, check it is available"
+		}
+	}
+
+	return []doctorComponentResult{config, credentials, serviceAccount}, nil
+}
+
+type doctorComponentStatus int
+
+const (
+	doctorComponentOK           doctorComponentStatus = iota
+	doctorComponentMissing                            /* component not installed */
+	doctorComponentIncompatible                       /* component installed with an unexpected version */
+	doctorComponentUnavailable                        /* component installed but not healthy */
+	doctorComponentForbidden                          /* component could not be read with the user's access */
+)
+
+// Name is the status without color
+func (dcs doctorComponentStatus) Name() string {
+	switch dcs {
+	case doctorComponentOK:
+		return "ok"
+	case doctorComponentMissing:
+		return "missing"
+	case doctorComponentIncompatible:
+		return "incompatible"
+	case doctorComponentUnavailable:
+		return "unavailable"
+	case doctorComponentForbidden:
+		return "forbidden"
+	}
+	return "n/a"
+}
+
+func (dcs doctorComponentStatus) String() string {
+	switch dcs {
+	case doctorComponentOK:
+		return cli.Ssuccessf(dcs.Name())
+	case doctorComponentMissing, doctorComponentIncompatible, doctorComponentUnavailable:
+		return cli.Serrorf(dcs.Name())
+	case doctorComponentForbidden:
+		return cli.Swarnf(dcs.Name())
+	}
+	return dcs.Name()
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
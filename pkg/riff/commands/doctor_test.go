@@ -17,15 +17,21 @@
 package commands_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
+	"github.com/ghodss/yaml"
+	"github.com/google/go-cmp/cmp"
 	"github.com/projectriff/cli/pkg/cli"
 	"github.com/projectriff/cli/pkg/riff/commands"
 	rifftesting "github.com/projectriff/cli/pkg/testing"
+	"github.com/projectriff/system/pkg/apis/build"
+	appsv1 "k8s.io/api/apps/v1"
 	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgotesting "k8s.io/client-go/testing"
@@ -45,6 +51,46 @@ func TestDoctorOptions(t *testing.T) {
 			Options:          &commands.DoctorOptions{},
 			ExpectFieldError: cli.ErrMissingField(cli.NamespaceFlagName),
 		},
+		{
+			Name: "json output",
+			Options: &commands.DoctorOptions{
+				Namespace: "default",
+				Output:    commands.DoctorOutputJSON,
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "yaml output",
+			Options: &commands.DoctorOptions{
+				Namespace: "default",
+				Output:    commands.DoctorOutputYAML,
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "runtimes",
+			Options: &commands.DoctorOptions{
+				Namespace: "default",
+				Runtimes:  []string{cli.CoreRuntime, cli.KnativeRuntime},
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "invalid runtime",
+			Options: &commands.DoctorOptions{
+				Namespace: "default",
+				Runtimes:  []string{cli.CoreRuntime, "build"},
+			},
+			ExpectFieldError: cli.ErrInvalidArrayValue("build", cli.RuntimeFlagName, 1),
+		},
+		{
+			Name: "invalid output",
+			Options: &commands.DoctorOptions{
+				Namespace: "default",
+				Output:    "table",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("table", cli.OutputFlagName),
+		},
 	}
 
 	table.Run(t)
@@ -53,6 +99,42 @@ func TestDoctorOptions(t *testing.T) {
 func TestDoctorCommand(t *testing.T) {
 	verbs := []string{"get", "list", "create", "update", "delete", "patch", "watch"}
 	readVerbs := []string{"get", "list", "watch"}
+	builders := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "riff-system", Name: "builders"},
+		Data: map[string]string{
+			"riff-application": "projectriff/builder:application",
+			"riff-function":    "projectriff/builder:function",
+		},
+	}
+	controller := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "riff-system", Name: "riff-build-controller"},
+		Status: appsv1.DeploymentStatus{
+			Replicas:          1,
+			AvailableReplicas: 1,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+	riffBuild := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "riff-build"},
+		Data: map[string]string{
+			"default-image-prefix": "registry.example.com/my-user",
+		},
+	}
+	credential := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "my-creds",
+			Labels:    map[string]string{build.CredentialLabelKey: "basic-auth"},
+		},
+	}
+	serviceAccount := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "riff-build"},
+		Secrets: []corev1.ObjectReference{
+			{Name: "my-creds"},
+		},
+	}
 	table := rifftesting.CommandTable{
 		{
 			Name: "not installed",
@@ -70,34 +152,242 @@ func TestDoctorCommand(t *testing.T) {
 NAMESPACE     STATUS
 riff-system   missing
 
-RESOURCE                              READ      WRITE
-configmaps                            allowed   allowed
-secrets                               allowed   allowed
-pods                                  allowed   n/a
-pods/log                              allowed   n/a
-applications.build.projectriff.io     missing   missing
-containers.build.projectriff.io       missing   missing
-functions.build.projectriff.io        missing   missing
-deployers.core.projectriff.io         missing   missing
-processors.streaming.projectriff.io   missing   missing
-streams.streaming.projectriff.io      missing   missing
-adapters.knative.projectriff.io       missing   missing
-deployers.knative.projectriff.io      missing   missing
+RUNTIME   STATUS    DETAILS
+core      missing   not installed, skipped
+
+COMPONENT                           STATUS    DETAILS
+applications.build.projectriff.io   missing   
+containers.build.projectriff.io     missing   
+functions.build.projectriff.io      missing   
+builder/riff-application            missing   configmap riff-system/builders not found
+builder/riff-function               missing   configmap riff-system/builders not found
+controllers                         missing   no deployments found in riff-system
+
+BUILD                       STATUS    DETAILS
+configmap/riff-build        missing   
+credentials                 missing   no credentials found in default
+serviceaccount/riff-build   missing   
+
+RESOURCE                            READ      WRITE
+configmaps                          allowed   allowed
+secrets                             allowed   allowed
+pods                                allowed   n/a
+pods/log                            allowed   n/a
+applications.build.projectriff.io   missing   missing
+containers.build.projectriff.io     missing   missing
+functions.build.projectriff.io      missing   missing
+
+CHECK       NAME                                HINT
+namespace   riff-system                         install riff
+component   applications.build.projectriff.io   install riff build
+component   containers.build.projectriff.io     install riff build
+component   functions.build.projectriff.io      install riff build
+component   builder/riff-application            install riff build to register the builder
+component   builder/riff-function               install riff build to register the builder
+component   controllers                         install riff
+build       configmap/riff-build                run "riff credential apply my-creds --namespace default --docker-hub my-docker-id --set-default-image-prefix"
+build       credentials                         run "riff credential apply my-creds --namespace default --docker-hub my-docker-id --set-default-image-prefix"
+build       serviceaccount/riff-build           run "riff credential apply my-creds --namespace default --docker-hub my-docker-id --set-default-image-prefix"
+access      applications.build.projectriff.io   install the custom resource definition for applications.build.projectriff.io
+access      containers.build.projectriff.io     install the custom resource definition for containers.build.projectriff.io
+access      functions.build.projectriff.io      install the custom resource definition for functions.build.projectriff.io
 `,
+			ShouldError: true,
+			Verify: func(t *testing.T, output string, err error) {
+				if actual := err; !cli.IsSilent(err) {
+					t.Errorf("expected error to be silent, actual %#v", actual)
+				}
+			},
 		},
 		{
 			Name: "installed",
 			Args: []string{},
 			GivenObjects: []runtime.Object{
 				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "riff-system"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "applications.build.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "containers.build.projectriff.io"}},
+				builders,
+				controller,
+				riffBuild,
+				credential,
+				serviceAccount,
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "applications.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "containers.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "functions.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.core.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "processors.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "streams.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "adapters.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+			},
+			ExpectCreates: merge(
+				selfSubjectAccessReviewRequests("default", "core", "configmaps", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "core", "secrets", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "core", "pods", "", readVerbs...),
+				selfSubjectAccessReviewRequests("default", "core", "pods", "log", readVerbs...),
+				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "applications", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "containers", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "functions", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "core.projectriff.io", "deployers", "", verbs...),
+			),
+			WithReactors: []rifftesting.ReactionFunc{
+				passAccessReview(),
+			},
+			ExpectOutput: `
+NAMESPACE     STATUS
+riff-system   ok
+
+RUNTIME   STATUS      DETAILS
+core      installed   
+
+COMPONENT                           STATUS   DETAILS
+applications.build.projectriff.io   ok       v1alpha1
+containers.build.projectriff.io     ok       v1alpha1
+functions.build.projectriff.io      ok       v1alpha1
+deployers.core.projectriff.io       ok       v1alpha1
+builder/riff-application            ok       projectriff/builder:application
+builder/riff-function               ok       projectriff/builder:function
+deployment/riff-build-controller    ok       1 of 1 replicas available
+
+BUILD                       STATUS   DETAILS
+configmap/riff-build        ok       registry.example.com/my-user
+credentials                 ok       my-creds
+serviceaccount/riff-build   ok       my-creds
+
+RESOURCE                            READ      WRITE
+configmaps                          allowed   allowed
+secrets                             allowed   allowed
+pods                                allowed   n/a
+pods/log                            allowed   n/a
+applications.build.projectriff.io   allowed   allowed
+containers.build.projectriff.io     allowed   allowed
+functions.build.projectriff.io      allowed   allowed
+deployers.core.projectriff.io       allowed   allowed
+`,
+		},
+		{
+			Name: "system forbidden",
+			Args: []string{},
+			GivenObjects: []runtime.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "riff-system"}},
+				builders,
+				controller,
+				riffBuild,
+				credential,
+				serviceAccount,
+			},
+			ExpectCreates: merge(
+				selfSubjectAccessReviewRequests("default", "core", "configmaps", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "core", "secrets", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "core", "pods", "", readVerbs...),
+				selfSubjectAccessReviewRequests("default", "core", "pods", "log", readVerbs...),
+				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "applications", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "containers", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "functions", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "core.projectriff.io", "deployers", "", verbs...),
+			),
+			WithReactors: []rifftesting.ReactionFunc{
+				passAccessReview(),
+				forbidOn("get", "namespaces", ""),
+				forbidOn("get", "customresourcedefinitions", ""),
+				forbidOn("get", "configmaps", "riff-system"),
+				forbidOn("list", "deployments", "riff-system"),
+			},
+			ExpectOutput: `
+NAMESPACE     STATUS
+riff-system   forbidden
+
+RUNTIME   STATUS    DETAILS
+core      unknown   1 of 1 custom resources could not be read
+
+COMPONENT                           STATUS      DETAILS
+applications.build.projectriff.io   forbidden   not allowed to read custom resource definitions
+containers.build.projectriff.io     forbidden   not allowed to read custom resource definitions
+functions.build.projectriff.io      forbidden   not allowed to read custom resource definitions
+deployers.core.projectriff.io       forbidden   not allowed to read custom resource definitions
+builder/riff-application            forbidden   not allowed to read configmap riff-system/builders
+builder/riff-function               forbidden   not allowed to read configmap riff-system/builders
+controllers                         forbidden   not allowed to read deployments in riff-system
+
+BUILD                       STATUS   DETAILS
+configmap/riff-build        ok       registry.example.com/my-user
+credentials                 ok       my-creds
+serviceaccount/riff-build   ok       my-creds
+
+RESOURCE                            READ      WRITE
+configmaps                          allowed   allowed
+secrets                             allowed   allowed
+pods                                allowed   n/a
+pods/log                            allowed   n/a
+applications.build.projectriff.io   allowed   allowed
+containers.build.projectriff.io     allowed   allowed
+functions.build.projectriff.io      allowed   allowed
+deployers.core.projectriff.io       allowed   allowed
+
+CHECK       NAME                                HINT
+namespace   riff-system                         ask a cluster admin to check namespace riff-system, or to grant you read access
+runtime     core                                ask a cluster admin to check the core runtime is installed
+component   applications.build.projectriff.io   ask a cluster admin to check custom resource definitions, or to grant you read access
+component   containers.build.projectriff.io     ask a cluster admin to check custom resource definitions, or to grant you read access
+component   functions.build.projectriff.io      ask a cluster admin to check custom resource definitions, or to grant you read access
+component   deployers.core.projectriff.io       ask a cluster admin to check custom resource definitions, or to grant you read access
+component   builder/riff-application            ask a cluster admin to check configmap riff-system/builders, or to grant you read access
+component   builder/riff-function               ask a cluster admin to check configmap riff-system/builders, or to grant you read access
+component   controllers                         ask a cluster admin to check deployments in riff-system, or to grant you read access
+`,
+			ShouldError: true,
+			Verify: func(t *testing.T, output string, err error) {
+				if actual := err; !cli.IsSilent(err) {
+					t.Errorf("expected error to be silent, actual %#v", actual)
+				}
+			},
+		},
+		{
+			Name: "unhealthy components",
+			Args: []string{},
+			GivenObjects: []runtime.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "riff-system"}},
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "riff-system", Name: "builders"},
+					Data: map[string]string{
+						"riff-function": "projectriff/builder:function",
+					},
+				},
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "riff-system", Name: "riff-build-controller"},
+					Status: appsv1.DeploymentStatus{
+						Replicas:          1,
+						AvailableReplicas: 0,
+						Conditions: []appsv1.DeploymentCondition{
+							{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse, Message: "Deployment does not have minimum availability."},
+						},
+					},
+				},
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "riff-system", Name: "riff-core-controller"},
+					Status: appsv1.DeploymentStatus{
+						Replicas:          1,
+						AvailableReplicas: 0,
+					},
+				},
+				&apiextensionsv1beta1.CustomResourceDefinition{
+					ObjectMeta: metav1.ObjectMeta{Name: "applications.build.projectriff.io"},
+					Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
+						Versions: []apiextensionsv1beta1.CustomResourceDefinitionVersion{
+							{Name: "v1alpha1", Served: false},
+							{Name: "v1beta1", Served: true},
+						},
+					},
+				},
+				&apiextensionsv1beta1.CustomResourceDefinition{
+					ObjectMeta: metav1.ObjectMeta{Name: "containers.build.projectriff.io"},
+					Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
+						Versions: []apiextensionsv1beta1.CustomResourceDefinitionVersion{
+							{Name: "v1alpha1", Served: true},
+							{Name: "v1beta1", Served: true},
+						},
+					},
+				},
 				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "functions.build.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.core.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "processors.streaming.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "streams.streaming.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "adapters.knative.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.knative.projectriff.io"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.core.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha2"}},
 			},
 			ExpectCreates: merge(
 				selfSubjectAccessReviewRequests("default", "core", "configmaps", "", verbs...),
@@ -108,8 +398,89 @@ deployers.knative.projectriff.io      missing   missing
 				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "containers", "", verbs...),
 				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "functions", "", verbs...),
 				selfSubjectAccessReviewRequests("default", "core.projectriff.io", "deployers", "", verbs...),
-				selfSubjectAccessReviewRequests("default", "streaming.projectriff.io", "processors", "", verbs...),
-				selfSubjectAccessReviewRequests("default", "streaming.projectriff.io", "streams", "", verbs...),
+			),
+			WithReactors: []rifftesting.ReactionFunc{
+				passAccessReview(),
+			},
+			ExpectOutput: `
+NAMESPACE     STATUS
+riff-system   ok
+
+RUNTIME   STATUS      DETAILS
+core      installed   
+
+COMPONENT                           STATUS         DETAILS
+applications.build.projectriff.io   incompatible   serves v1beta1, expected v1alpha1
+containers.build.projectriff.io     ok             v1alpha1, v1beta1
+functions.build.projectriff.io      incompatible   serves no versions, expected v1alpha1
+deployers.core.projectriff.io       incompatible   serves v1alpha2, expected v1alpha1
+builder/riff-application            missing        
+builder/riff-function               ok             projectriff/builder:function
+deployment/riff-build-controller    unavailable    Deployment does not have minimum availability.
+deployment/riff-core-controller     unavailable    0 of 1 replicas available
+
+BUILD                       STATUS    DETAILS
+configmap/riff-build        missing   
+credentials                 missing   no credentials found in default
+serviceaccount/riff-build   missing   
+
+RESOURCE                            READ      WRITE
+configmaps                          allowed   allowed
+secrets                             allowed   allowed
+pods                                allowed   n/a
+pods/log                            allowed   n/a
+applications.build.projectriff.io   allowed   allowed
+containers.build.projectriff.io     allowed   allowed
+functions.build.projectriff.io      allowed   allowed
+deployers.core.projectriff.io       allowed   allowed
+
+CHECK       NAME                                HINT
+component   applications.build.projectriff.io   install a version of riff build that serves v1alpha1, or a riff CLI matching the installed version
+component   functions.build.projectriff.io      install a version of riff build that serves v1alpha1, or a riff CLI matching the installed version
+component   deployers.core.projectriff.io       install a version of the riff core runtime that serves v1alpha1, or a riff CLI matching the installed version
+component   builder/riff-application            install riff build to register the builder
+component   deployment/riff-build-controller    run "kubectl -n riff-system describe deployment riff-build-controller" to find the cause
+component   deployment/riff-core-controller     run "kubectl -n riff-system describe deployment riff-core-controller" to find the cause
+build       configmap/riff-build                run "riff credential apply my-creds --namespace default --docker-hub my-docker-id --set-default-image-prefix"
+build       credentials                         run "riff credential apply my-creds --namespace default --docker-hub my-docker-id --set-default-image-prefix"
+build       serviceaccount/riff-build           run "riff credential apply my-creds --namespace default --docker-hub my-docker-id --set-default-image-prefix"
+`,
+			ShouldError: true,
+		},
+		{
+			Name: "knative runtime",
+			Args: []string{},
+			Config: &cli.Config{
+				CompiledEnv: cli.CompiledEnv{
+					Runtimes: map[string]bool{
+						cli.KnativeRuntime: true,
+					},
+				},
+			},
+			GivenObjects: []runtime.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "riff-system"}},
+				builders,
+				controller,
+				riffBuild,
+				credential,
+				serviceAccount,
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "applications.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "containers.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "functions.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "adapters.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "configurations.serving.knative.dev"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "revisions.serving.knative.dev"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "services.serving.knative.dev"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1beta1"}},
+			},
+			ExpectCreates: merge(
+				selfSubjectAccessReviewRequests("default", "core", "configmaps", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "core", "secrets", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "core", "pods", "", readVerbs...),
+				selfSubjectAccessReviewRequests("default", "core", "pods", "log", readVerbs...),
+				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "applications", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "containers", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "functions", "", verbs...),
 				selfSubjectAccessReviewRequests("default", "knative.projectriff.io", "adapters", "", verbs...),
 				selfSubjectAccessReviewRequests("default", "knative.projectriff.io", "deployers", "", verbs...),
 			),
@@ -120,34 +491,63 @@ deployers.knative.projectriff.io      missing   missing
 NAMESPACE     STATUS
 riff-system   ok
 
-RESOURCE                              READ      WRITE
-configmaps                            allowed   allowed
-secrets                               allowed   allowed
-pods                                  allowed   n/a
-pods/log                              allowed   n/a
-applications.build.projectriff.io     allowed   allowed
-containers.build.projectriff.io       allowed   allowed
-functions.build.projectriff.io        allowed   allowed
-deployers.core.projectriff.io         allowed   allowed
-processors.streaming.projectriff.io   allowed   allowed
-streams.streaming.projectriff.io      allowed   allowed
-adapters.knative.projectriff.io       allowed   allowed
-deployers.knative.projectriff.io      allowed   allowed
+RUNTIME   STATUS       DETAILS
+knative   incomplete   5 of 6 custom resources installed
+
+COMPONENT                            STATUS    DETAILS
+applications.build.projectriff.io    ok        v1alpha1
+containers.build.projectriff.io      ok        v1alpha1
+functions.build.projectriff.io       ok        v1alpha1
+adapters.knative.projectriff.io      ok        v1alpha1
+deployers.knative.projectriff.io     ok        v1alpha1
+configurations.serving.knative.dev   ok        v1alpha1
+revisions.serving.knative.dev        ok        v1alpha1
+routes.serving.knative.dev           missing   
+services.serving.knative.dev         ok        v1beta1
+builder/riff-application             ok        projectriff/builder:application
+builder/riff-function                ok        projectriff/builder:function
+deployment/riff-build-controller     ok        1 of 1 replicas available
+
+BUILD                       STATUS   DETAILS
+configmap/riff-build        ok       registry.example.com/my-user
+credentials                 ok       my-creds
+serviceaccount/riff-build   ok       my-creds
+
+RESOURCE                            READ      WRITE
+configmaps                          allowed   allowed
+secrets                             allowed   allowed
+pods                                allowed   n/a
+pods/log                            allowed   n/a
+applications.build.projectriff.io   allowed   allowed
+containers.build.projectriff.io     allowed   allowed
+functions.build.projectriff.io      allowed   allowed
+adapters.knative.projectriff.io     allowed   allowed
+deployers.knative.projectriff.io    allowed   allowed
+
+CHECK       NAME                         HINT
+runtime     knative                      install the missing components of the knative runtime
+component   routes.serving.knative.dev   install Knative Serving
 `,
+			ShouldError: true,
 		},
 		{
 			Name: "read-only access",
 			Args: []string{},
 			GivenObjects: []runtime.Object{
 				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "riff-system"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "applications.build.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "containers.build.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "functions.build.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.core.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "processors.streaming.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "streams.streaming.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "adapters.knative.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.knative.projectriff.io"}},
+				builders,
+				controller,
+				riffBuild,
+				credential,
+				serviceAccount,
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "applications.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "containers.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "functions.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.core.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "processors.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "streams.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "adapters.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
 			},
 			ExpectCreates: merge(
 				selfSubjectAccessReviewRequests("default", "core", "configmaps", "", verbs...),
@@ -158,10 +558,6 @@ deployers.knative.projectriff.io      allowed   allowed
 				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "containers", "", verbs...),
 				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "functions", "", verbs...),
 				selfSubjectAccessReviewRequests("default", "core.projectriff.io", "deployers", "", verbs...),
-				selfSubjectAccessReviewRequests("default", "streaming.projectriff.io", "processors", "", verbs...),
-				selfSubjectAccessReviewRequests("default", "streaming.projectriff.io", "streams", "", verbs...),
-				selfSubjectAccessReviewRequests("default", "knative.projectriff.io", "adapters", "", verbs...),
-				selfSubjectAccessReviewRequests("default", "knative.projectriff.io", "deployers", "", verbs...),
 			),
 			WithReactors: []rifftesting.ReactionFunc{
 				denyAccessReviewOn("*", "create"),
@@ -174,34 +570,61 @@ deployers.knative.projectriff.io      allowed   allowed
 NAMESPACE     STATUS
 riff-system   ok
 
-RESOURCE                              READ      WRITE
-configmaps                            allowed   denied
-secrets                               allowed   denied
-pods                                  allowed   n/a
-pods/log                              allowed   n/a
-applications.build.projectriff.io     allowed   denied
-containers.build.projectriff.io       allowed   denied
-functions.build.projectriff.io        allowed   denied
-deployers.core.projectriff.io         allowed   denied
-processors.streaming.projectriff.io   allowed   denied
-streams.streaming.projectriff.io      allowed   denied
-adapters.knative.projectriff.io       allowed   denied
-deployers.knative.projectriff.io      allowed   denied
+RUNTIME   STATUS      DETAILS
+core      installed   
+
+COMPONENT                           STATUS   DETAILS
+applications.build.projectriff.io   ok       v1alpha1
+containers.build.projectriff.io     ok       v1alpha1
+functions.build.projectriff.io      ok       v1alpha1
+deployers.core.projectriff.io       ok       v1alpha1
+builder/riff-application            ok       projectriff/builder:application
+builder/riff-function               ok       projectriff/builder:function
+deployment/riff-build-controller    ok       1 of 1 replicas available
+
+BUILD                       STATUS   DETAILS
+configmap/riff-build        ok       registry.example.com/my-user
+credentials                 ok       my-creds
+serviceaccount/riff-build   ok       my-creds
+
+RESOURCE                            READ      WRITE
+configmaps                          allowed   denied
+secrets                             allowed   denied
+pods                                allowed   n/a
+pods/log                            allowed   n/a
+applications.build.projectriff.io   allowed   denied
+containers.build.projectriff.io     allowed   denied
+functions.build.projectriff.io      allowed   denied
+deployers.core.projectriff.io       allowed   denied
+
+CHECK    NAME                                HINT
+access   configmaps                          ask a cluster admin to grant write access to configmaps in namespace default
+access   secrets                             ask a cluster admin to grant write access to secrets in namespace default
+access   applications.build.projectriff.io   ask a cluster admin to grant write access to applications.build.projectriff.io in namespace default
+access   containers.build.projectriff.io     ask a cluster admin to grant write access to containers.build.projectriff.io in namespace default
+access   functions.build.projectriff.io      ask a cluster admin to grant write access to functions.build.projectriff.io in namespace default
+access   deployers.core.projectriff.io       ask a cluster admin to grant write access to deployers.core.projectriff.io in namespace default
 `,
+			ShouldError: true,
 		},
 		{
 			Name: "no-watch access",
 			Args: []string{},
 			GivenObjects: []runtime.Object{
 				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "riff-system"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "applications.build.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "containers.build.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "functions.build.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.core.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "processors.streaming.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "streams.streaming.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "adapters.knative.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.knative.projectriff.io"}},
+				builders,
+				controller,
+				riffBuild,
+				credential,
+				serviceAccount,
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "applications.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "containers.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "functions.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.core.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "processors.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "streams.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "adapters.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
 			},
 			ExpectCreates: merge(
 				selfSubjectAccessReviewRequests("default", "core", "configmaps", "", verbs...),
@@ -212,10 +635,6 @@ deployers.knative.projectriff.io      allowed   denied
 				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "containers", "", verbs...),
 				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "functions", "", verbs...),
 				selfSubjectAccessReviewRequests("default", "core.projectriff.io", "deployers", "", verbs...),
-				selfSubjectAccessReviewRequests("default", "streaming.projectriff.io", "processors", "", verbs...),
-				selfSubjectAccessReviewRequests("default", "streaming.projectriff.io", "streams", "", verbs...),
-				selfSubjectAccessReviewRequests("default", "knative.projectriff.io", "adapters", "", verbs...),
-				selfSubjectAccessReviewRequests("default", "knative.projectriff.io", "deployers", "", verbs...),
 			),
 			WithReactors: []rifftesting.ReactionFunc{
 				denyAccessReviewOn("*", "watch"),
@@ -225,79 +644,449 @@ deployers.knative.projectriff.io      allowed   denied
 NAMESPACE     STATUS
 riff-system   ok
 
-RESOURCE                              READ    WRITE
-configmaps                            mixed   allowed
-secrets                               mixed   allowed
-pods                                  mixed   n/a
-pods/log                              mixed   n/a
-applications.build.projectriff.io     mixed   allowed
-containers.build.projectriff.io       mixed   allowed
-functions.build.projectriff.io        mixed   allowed
-deployers.core.projectriff.io         mixed   allowed
-processors.streaming.projectriff.io   mixed   allowed
-streams.streaming.projectriff.io      mixed   allowed
-adapters.knative.projectriff.io       mixed   allowed
-deployers.knative.projectriff.io      mixed   allowed
+RUNTIME   STATUS      DETAILS
+core      installed   
+
+COMPONENT                           STATUS   DETAILS
+applications.build.projectriff.io   ok       v1alpha1
+containers.build.projectriff.io     ok       v1alpha1
+functions.build.projectriff.io      ok       v1alpha1
+deployers.core.projectriff.io       ok       v1alpha1
+builder/riff-application            ok       projectriff/builder:application
+builder/riff-function               ok       projectriff/builder:function
+deployment/riff-build-controller    ok       1 of 1 replicas available
+
+BUILD                       STATUS   DETAILS
+configmap/riff-build        ok       registry.example.com/my-user
+credentials                 ok       my-creds
+serviceaccount/riff-build   ok       my-creds
+
+RESOURCE                            READ    WRITE
+configmaps                          mixed   allowed
+secrets                             mixed   allowed
+pods                                mixed   n/a
+pods/log                            mixed   n/a
+applications.build.projectriff.io   mixed   allowed
+containers.build.projectriff.io     mixed   allowed
+functions.build.projectriff.io      mixed   allowed
+deployers.core.projectriff.io       mixed   allowed
+
+CHECK    NAME                                HINT
+access   configmaps                          ask a cluster admin to grant read access to configmaps in namespace default
+access   secrets                             ask a cluster admin to grant read access to secrets in namespace default
+access   pods                                ask a cluster admin to grant read access to pods in namespace default
+access   pods/log                            ask a cluster admin to grant read access to pods/log in namespace default
+access   applications.build.projectriff.io   ask a cluster admin to grant read access to applications.build.projectriff.io in namespace default
+access   containers.build.projectriff.io     ask a cluster admin to grant read access to containers.build.projectriff.io in namespace default
+access   functions.build.projectriff.io      ask a cluster admin to grant read access to functions.build.projectriff.io in namespace default
+access   deployers.core.projectriff.io       ask a cluster admin to grant read access to deployers.core.projectriff.io in namespace default
+`,
+			ShouldError: true,
+		},
+		{
+			Name: "json output",
+			Args: []string{cli.OutputFlagName, commands.DoctorOutputJSON},
+			GivenObjects: []runtime.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "riff-system"}},
+				builders,
+				controller,
+				riffBuild,
+				credential,
+				serviceAccount,
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "applications.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "containers.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "functions.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.core.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "processors.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "streams.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "adapters.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+			},
+			ExpectCreates: merge(
+				selfSubjectAccessReviewRequests("default", "core", "configmaps", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "core", "secrets", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "core", "pods", "", readVerbs...),
+				selfSubjectAccessReviewRequests("default", "core", "pods", "log", readVerbs...),
+				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "applications", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "containers", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "functions", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "core.projectriff.io", "deployers", "", verbs...),
+			),
+			WithReactors: []rifftesting.ReactionFunc{
+				denyAccessReviewOn("secrets", "delete"),
+				passAccessReview(),
+			},
+			ShouldError: true,
+			Verify: func(t *testing.T, output string, err error) {
+				report := doctorReport{}
+				if err := json.Unmarshal([]byte(output), &report); err != nil {
+					t.Fatalf("expected json output, actual %q: %v", output, err)
+				}
+				if report.Healthy {
+					t.Errorf("expected report to be unhealthy")
+				}
+				if expected, actual := 20, len(report.Checks); expected != actual {
+					t.Errorf("expected %d checks, actually %d", expected, actual)
+				}
+				expected := []doctorReportCheck{
+					{Type: "namespace", Name: "riff-system", Status: "ok", Healthy: true},
+					{Type: "runtime", Name: "core", Status: "installed", Healthy: true},
+				}
+				if diff := cmp.Diff(expected, report.Checks[:2]); diff != "" {
+					t.Errorf("unexpected checks (-expected, +actual): %s", diff)
+				}
+				expected = []doctorReportCheck{
+					{Type: "access", Name: "configmaps", Status: "allowed", Healthy: true, Read: "allowed", Write: "allowed"},
+					{Type: "access", Name: "secrets", Status: "mixed", Read: "allowed", Write: "mixed", Hint: "ask a cluster admin to grant write access to secrets in namespace default"},
+					{Type: "access", Name: "pods", Status: "allowed", Healthy: true, Read: "allowed", Write: "n/a"},
+				}
+				if diff := cmp.Diff(expected, report.Checks[12:15]); diff != "" {
+					t.Errorf("unexpected checks (-expected, +actual): %s", diff)
+				}
+			},
+		},
+		{
+			Name: "yaml output",
+			Args: []string{cli.OutputFlagName, commands.DoctorOutputYAML},
+			ExpectCreates: merge(
+				selfSubjectAccessReviewRequests("default", "core", "configmaps", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "core", "secrets", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "core", "pods", "", readVerbs...),
+				selfSubjectAccessReviewRequests("default", "core", "pods", "log", readVerbs...),
+			),
+			WithReactors: []rifftesting.ReactionFunc{
+				passAccessReview(),
+			},
+			ShouldError: true,
+			Verify: func(t *testing.T, output string, err error) {
+				if actual := err; !cli.IsSilent(err) {
+					t.Errorf("expected error to be silent, actual %#v", actual)
+				}
+				report := doctorReport{}
+				if err := yaml.Unmarshal([]byte(output), &report); err != nil {
+					t.Fatalf("expected yaml output, actual %q: %v", output, err)
+				}
+				if report.Healthy {
+					t.Errorf("expected report to be unhealthy")
+				}
+				expected := []doctorReportCheck{
+					{Type: "namespace", Name: "riff-system", Status: "missing", Hint: "install riff"},
+					{Type: "runtime", Name: "core", Status: "missing", Healthy: true, Details: "not installed, skipped"},
+				}
+				if diff := cmp.Diff(expected, report.Checks[:2]); diff != "" {
+					t.Errorf("unexpected checks (-expected, +actual): %s", diff)
+				}
+			},
+		},
+		{
+			Name: "namespace not ready for builds",
+			Args: []string{},
+			GivenObjects: []runtime.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "riff-system"}},
+				builders,
+				controller,
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "riff-build"},
+				},
+				credential,
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "default",
+						Name:      "my-other-creds",
+						Labels:    map[string]string{build.CredentialLabelKey: "docker-hub"},
+					},
+				},
+				serviceAccount,
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "applications.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "containers.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "functions.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.core.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "processors.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "streams.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "adapters.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+			},
+			ExpectCreates: merge(
+				selfSubjectAccessReviewRequests("default", "core", "configmaps", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "core", "secrets", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "core", "pods", "", readVerbs...),
+				selfSubjectAccessReviewRequests("default", "core", "pods", "log", readVerbs...),
+				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "applications", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "containers", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "functions", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "core.projectriff.io", "deployers", "", verbs...),
+			),
+			WithReactors: []rifftesting.ReactionFunc{
+				passAccessReview(),
+			},
+			ShouldError: true,
+			ExpectOutput: `
+NAMESPACE     STATUS
+riff-system   ok
+
+RUNTIME   STATUS      DETAILS
+core      installed   
+
+COMPONENT                           STATUS   DETAILS
+applications.build.projectriff.io   ok       v1alpha1
+containers.build.projectriff.io     ok       v1alpha1
+functions.build.projectriff.io      ok       v1alpha1
+deployers.core.projectriff.io       ok       v1alpha1
+builder/riff-application            ok       projectriff/builder:application
+builder/riff-function               ok       projectriff/builder:function
+deployment/riff-build-controller    ok       1 of 1 replicas available
+
+BUILD                       STATUS        DETAILS
+configmap/riff-build        missing       default-image-prefix not set
+credentials                 ok            my-creds, my-other-creds
+serviceaccount/riff-build   unavailable   credentials not attached: my-other-creds
+
+RESOURCE                            READ      WRITE
+configmaps                          allowed   allowed
+secrets                             allowed   allowed
+pods                                allowed   n/a
+pods/log                            allowed   n/a
+applications.build.projectriff.io   allowed   allowed
+containers.build.projectriff.io     allowed   allowed
+functions.build.projectriff.io      allowed   allowed
+deployers.core.projectriff.io       allowed   allowed
+
+CHECK   NAME                        HINT
+build   configmap/riff-build        run "riff credential apply my-creds --namespace default --docker-hub my-docker-id --set-default-image-prefix"
+build   serviceaccount/riff-build   the build controller attaches credentials to the service account, check it is available
 `,
 		},
 		{
-			Name: "error getting namespace",
+			Name: "error listing credentials",
 			Args: []string{},
 			GivenObjects: []runtime.Object{
 				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "riff-system"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "applications.build.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "containers.build.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "functions.build.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.core.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "processors.streaming.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "streams.streaming.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "adapters.knative.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.knative.projectriff.io"}},
+				builders,
+				controller,
+				riffBuild,
+				credential,
+				serviceAccount,
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "applications.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "containers.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "functions.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.core.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "processors.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "streams.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "adapters.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
 			},
 			WithReactors: []rifftesting.ReactionFunc{
-				rifftesting.InduceFailure("get", "namespaces"),
+				rifftesting.InduceFailure("list", "secrets"),
 			},
 			ShouldError: true,
 		},
 		{
-			Name: "error getting crd",
+			Name: "error getting service account",
 			Args: []string{},
 			GivenObjects: []runtime.Object{
 				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "riff-system"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "applications.build.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "containers.build.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "functions.build.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.core.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "processors.streaming.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "streams.streaming.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "adapters.knative.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.knative.projectriff.io"}},
+				builders,
+				controller,
+				riffBuild,
+				credential,
+				serviceAccount,
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "applications.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "containers.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "functions.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.core.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "processors.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "streams.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "adapters.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+			},
+			WithReactors: []rifftesting.ReactionFunc{
+				rifftesting.InduceFailure("get", "serviceaccounts"),
+			},
+			ShouldError: true,
+		},
+		{
+			Name: "required runtime missing",
+			Args: []string{cli.RuntimeFlagName, cli.StreamingRuntime},
+			Config: &cli.Config{
+				CompiledEnv: cli.CompiledEnv{
+					Runtimes: map[string]bool{
+						cli.CoreRuntime:      true,
+						cli.StreamingRuntime: true,
+					},
+				},
+			},
+			GivenObjects: []runtime.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "riff-system"}},
+				builders,
+				controller,
+				riffBuild,
+				credential,
+				serviceAccount,
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "applications.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "containers.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "functions.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.core.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
 			},
 			ExpectCreates: merge(
 				selfSubjectAccessReviewRequests("default", "core", "configmaps", "", verbs...),
 				selfSubjectAccessReviewRequests("default", "core", "secrets", "", verbs...),
 				selfSubjectAccessReviewRequests("default", "core", "pods", "", readVerbs...),
 				selfSubjectAccessReviewRequests("default", "core", "pods", "log", readVerbs...),
+				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "applications", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "containers", "", verbs...),
+				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "functions", "", verbs...),
 			),
+			WithReactors: []rifftesting.ReactionFunc{
+				passAccessReview(),
+			},
+			ShouldError: true,
+			ExpectOutput: `
+NAMESPACE     STATUS
+riff-system   ok
+
+RUNTIME     STATUS    DETAILS
+streaming   missing   not installed
+
+COMPONENT                             STATUS    DETAILS
+applications.build.projectriff.io     ok        v1alpha1
+containers.build.projectriff.io       ok        v1alpha1
+functions.build.projectriff.io        ok        v1alpha1
+processors.streaming.projectriff.io   missing   
+streams.streaming.projectriff.io      missing   
+builder/riff-application              ok        projectriff/builder:application
+builder/riff-function                 ok        projectriff/builder:function
+deployment/riff-build-controller      ok        1 of 1 replicas available
+
+BUILD                       STATUS   DETAILS
+configmap/riff-build        ok       registry.example.com/my-user
+credentials                 ok       my-creds
+serviceaccount/riff-build   ok       my-creds
+
+RESOURCE                              READ      WRITE
+configmaps                            allowed   allowed
+secrets                               allowed   allowed
+pods                                  allowed   n/a
+pods/log                              allowed   n/a
+applications.build.projectriff.io     allowed   allowed
+containers.build.projectriff.io       allowed   allowed
+functions.build.projectriff.io        allowed   allowed
+processors.streaming.projectriff.io   missing   missing
+streams.streaming.projectriff.io      missing   missing
+
+CHECK       NAME                                  HINT
+runtime     streaming                             install the riff streaming runtime
+component   processors.streaming.projectriff.io   install the riff streaming runtime
+component   streams.streaming.projectriff.io      install the riff streaming runtime
+access      processors.streaming.projectriff.io   install the custom resource definition for processors.streaming.projectriff.io
+access      streams.streaming.projectriff.io      install the custom resource definition for streams.streaming.projectriff.io
+`,
+		},
+		{
+			Name:        "runtime not enabled",
+			Args:        []string{cli.RuntimeFlagName, cli.KnativeRuntime},
+			ShouldError: true,
+		},
+		{
+			Name: "error getting namespace",
+			Args: []string{},
+			GivenObjects: []runtime.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "riff-system"}},
+				builders,
+				controller,
+				riffBuild,
+				credential,
+				serviceAccount,
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "applications.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "containers.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "functions.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.core.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "processors.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "streams.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "adapters.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+			},
+			WithReactors: []rifftesting.ReactionFunc{
+				rifftesting.InduceFailure("get", "namespaces"),
+			},
+			ShouldError: true,
+		},
+		{
+			Name: "error getting crd",
+			Args: []string{},
+			GivenObjects: []runtime.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "riff-system"}},
+				builders,
+				controller,
+				riffBuild,
+				credential,
+				serviceAccount,
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "applications.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "containers.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "functions.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.core.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "processors.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "streams.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "adapters.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+			},
 			WithReactors: []rifftesting.ReactionFunc{
 				rifftesting.InduceFailure("get", "customresourcedefinitions"),
 				passAccessReview(),
 			},
 			ShouldError: true,
 		},
+		{
+			Name: "error getting builders",
+			Args: []string{},
+			GivenObjects: []runtime.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "riff-system"}},
+				builders,
+				controller,
+				riffBuild,
+				credential,
+				serviceAccount,
+			},
+			WithReactors: []rifftesting.ReactionFunc{
+				rifftesting.InduceFailure("get", "configmaps"),
+				passAccessReview(),
+			},
+			ShouldError: true,
+		},
+		{
+			Name: "error listing deployments",
+			Args: []string{},
+			GivenObjects: []runtime.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "riff-system"}},
+				builders,
+				controller,
+				riffBuild,
+				credential,
+				serviceAccount,
+			},
+			WithReactors: []rifftesting.ReactionFunc{
+				rifftesting.InduceFailure("list", "deployments"),
+				passAccessReview(),
+			},
+			ShouldError: true,
+		},
 		{
 			Name: "error creating selfsubjectaccessreview",
 			Args: []string{},
 			GivenObjects: []runtime.Object{
 				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "riff-system"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "applications.build.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "containers.build.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "functions.build.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.core.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "processors.streaming.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "streams.streaming.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "adapters.knative.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.knative.projectriff.io"}},
+				builders,
+				controller,
+				riffBuild,
+				credential,
+				serviceAccount,
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "applications.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "containers.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "functions.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.core.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "processors.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "streams.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "adapters.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
 			},
 			ExpectCreates: selfSubjectAccessReviewRequests("default", "core", "configmaps", "", "get"),
 			WithReactors: []rifftesting.ReactionFunc{
@@ -310,14 +1099,19 @@ deployers.knative.projectriff.io      mixed   allowed
 			Args: []string{},
 			GivenObjects: []runtime.Object{
 				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "riff-system"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "applications.build.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "containers.build.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "functions.build.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.core.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "processors.streaming.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "streams.streaming.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "adapters.knative.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.knative.projectriff.io"}},
+				builders,
+				controller,
+				riffBuild,
+				credential,
+				serviceAccount,
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "applications.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "containers.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "functions.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.core.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "processors.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "streams.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "adapters.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
 			},
 			ExpectCreates: selfSubjectAccessReviewRequests("default", "core", "configmaps", "", "get"),
 			WithReactors: []rifftesting.ReactionFunc{
@@ -330,14 +1124,19 @@ deployers.knative.projectriff.io      mixed   allowed
 			Args: []string{},
 			GivenObjects: []runtime.Object{
 				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "riff-system"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "applications.build.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "containers.build.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "functions.build.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.core.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "processors.streaming.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "streams.streaming.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "adapters.knative.projectriff.io"}},
-				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.knative.projectriff.io"}},
+				builders,
+				controller,
+				riffBuild,
+				credential,
+				serviceAccount,
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "applications.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "containers.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "functions.build.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.core.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "processors.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "streams.streaming.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "adapters.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
+				&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "deployers.knative.projectriff.io"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Version: "v1alpha1"}},
 			},
 			ExpectCreates: merge(
 				selfSubjectAccessReviewRequests("default", "core", "configmaps", "", verbs...),
@@ -348,10 +1147,6 @@ deployers.knative.projectriff.io      mixed   allowed
 				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "containers", "", verbs...),
 				selfSubjectAccessReviewRequests("default", "build.projectriff.io", "functions", "", verbs...),
 				selfSubjectAccessReviewRequests("default", "core.projectriff.io", "deployers", "", verbs...),
-				selfSubjectAccessReviewRequests("default", "streaming.projectriff.io", "processors", "", verbs...),
-				selfSubjectAccessReviewRequests("default", "streaming.projectriff.io", "streams", "", verbs...),
-				selfSubjectAccessReviewRequests("default", "knative.projectriff.io", "adapters", "", verbs...),
-				selfSubjectAccessReviewRequests("default", "knative.projectriff.io", "deployers", "", verbs...),
 			),
 			WithReactors: []rifftesting.ReactionFunc{
 				unknownAccessReviewOn("*", "*"),
@@ -360,20 +1155,44 @@ deployers.knative.projectriff.io      mixed   allowed
 NAMESPACE     STATUS
 riff-system   ok
 
-RESOURCE                              READ      WRITE
-configmaps                            unknown   unknown
-secrets                               unknown   unknown
-pods                                  unknown   n/a
-pods/log                              unknown   n/a
-applications.build.projectriff.io     unknown   unknown
-containers.build.projectriff.io       unknown   unknown
-functions.build.projectriff.io        unknown   unknown
-deployers.core.projectriff.io         unknown   unknown
-processors.streaming.projectriff.io   unknown   unknown
-streams.streaming.projectriff.io      unknown   unknown
-adapters.knative.projectriff.io       unknown   unknown
-deployers.knative.projectriff.io      unknown   unknown
+RUNTIME   STATUS      DETAILS
+core      installed   
+
+COMPONENT                           STATUS   DETAILS
+applications.build.projectriff.io   ok       v1alpha1
+containers.build.projectriff.io     ok       v1alpha1
+functions.build.projectriff.io      ok       v1alpha1
+deployers.core.projectriff.io       ok       v1alpha1
+builder/riff-application            ok       projectriff/builder:application
+builder/riff-function               ok       projectriff/builder:function
+deployment/riff-build-controller    ok       1 of 1 replicas available
+
+BUILD                       STATUS   DETAILS
+configmap/riff-build        ok       registry.example.com/my-user
+credentials                 ok       my-creds
+serviceaccount/riff-build   ok       my-creds
+
+RESOURCE                            READ      WRITE
+configmaps                          unknown   unknown
+secrets                             unknown   unknown
+pods                                unknown   n/a
+pods/log                            unknown   n/a
+applications.build.projectriff.io   unknown   unknown
+containers.build.projectriff.io     unknown   unknown
+functions.build.projectriff.io      unknown   unknown
+deployers.core.projectriff.io       unknown   unknown
+
+CHECK    NAME                                HINT
+access   configmaps                          access could not be determined, ask a cluster admin to review the rules for configmaps in namespace default
+access   secrets                             access could not be determined, ask a cluster admin to review the rules for secrets in namespace default
+access   pods                                access could not be determined, ask a cluster admin to review the rules for pods in namespace default
+access   pods/log                            access could not be determined, ask a cluster admin to review the rules for pods/log in namespace default
+access   applications.build.projectriff.io   access could not be determined, ask a cluster admin to review the rules for applications.build.projectriff.io in namespace default
+access   containers.build.projectriff.io     access could not be determined, ask a cluster admin to review the rules for containers.build.projectriff.io in namespace default
+access   functions.build.projectriff.io      access could not be determined, ask a cluster admin to review the rules for functions.build.projectriff.io in namespace default
+access   deployers.core.projectriff.io       access could not be determined, ask a cluster admin to review the rules for deployers.core.projectriff.io in namespace default
 `,
+			ShouldError: true,
 		},
 	}
 
@@ -406,6 +1225,16 @@ func selfSubjectAccessReviewRequests(namespace, group, resource string, subresou
 	return result
 }
 
+func forbidOn(verb, resource, namespace string) func(action clientgotesting.Action) (handled bool, ret runtime.Object, err error) {
+	return func(action clientgotesting.Action) (handled bool, ret runtime.Object, err error) {
+		if !action.Matches(verb, resource) || action.GetNamespace() != namespace {
+			return false, nil, nil
+		}
+		gr := action.GetResource().GroupResource()
+		return true, nil, apierrs.NewForbidden(gr, "", fmt.Errorf("no access"))
+	}
+}
+
 func failAccessReview() func(action clientgotesting.Action) (handled bool, ret runtime.Object, err error) {
 	// fork of riffesting.InduceFailure that returns the review to avoid a panic in the fake client
 	return func(action clientgotesting.Action) (handled bool, ret runtime.Object, err error) {
@@ -476,3 +1305,19 @@ func passAccessReview() func(action clientgotesting.Action) (handled bool, ret r
 		return true, review, nil
 	}
 }
+
+type doctorReport struct {
+	Healthy bool                `json:"healthy"`
+	Checks  []doctorReportCheck `json:"checks"`
+}
+
+type doctorReportCheck struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Healthy bool   `json:"healthy"`
+	Read    string `json:"read"`
+	Write   string `json:"write"`
+	Details string `json:"details"`
+	Hint    string `json:"hint"`
+}
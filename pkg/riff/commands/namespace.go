@@ -0,0 +1,46 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"context"
+	"strings"
+
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+func NewNamespaceCommand(ctx context.Context, c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "namespace",
+		Short: "namespaces for " + c.Name + " workloads",
+		Long: strings.TrimSpace(`
+Namespaces hold the workloads, credentials and build configuration for a team.
+
+A namespace is ready for ` + c.Name + ` once it has credentials for a container
+registry, a default image prefix for builds, and the team has access to the
+resources ` + c.Name + ` manages.
+`),
+		Aliases: []string{"namespaces", "ns"},
+	}
+
+	cli.CompleteNameArgs(cmd, cli.NamespacesCompletion)
+
+	cmd.AddCommand(NewNamespaceInitCommand(ctx, c))
+
+	return cmd
+}
@@ -0,0 +1,407 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	buildcommands "github.com/projectriff/cli/pkg/build/commands"
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/projectriff/cli/pkg/k8s"
+	"github.com/projectriff/cli/pkg/validation"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	namespaceInitRoleName = "riff"
+)
+
+type NamespaceInitOptions struct {
+	Name string
+
+	Credential string
+
+	DockerHubId       string
+	DockerHubPassword []byte
+
+	GcrTokenPath string
+
+	Registry         string
+	RegistryUser     string
+	RegistryPassword []byte
+
+	DefaultImagePrefix string
+
+	Groups []string
+
+	DryRun bool
+}
+
+var (
+	_ cli.Validatable = (*NamespaceInitOptions)(nil)
+	_ cli.Executable  = (*NamespaceInitOptions)(nil)
+	_ cli.DryRunable  = (*NamespaceInitOptions)(nil)
+)
+
+func (opts *NamespaceInitOptions) Validate(ctx context.Context) *cli.FieldError {
+	errs := cli.EmptyFieldError
+
+	if opts.Name == "" {
+		errs = errs.Also(cli.ErrMissingField(cli.NameArgumentName))
+	} else {
+		errs = errs.Also(validation.K8sName(opts.Name, cli.NameArgumentName))
+	}
+
+	// docker-hub, gcr and registry are mutually exclusive, and optional
+	used := []string{}
+	if opts.DockerHubId != "" {
+		used = append(used, cli.DockerHubFlagName)
+	}
+	if opts.GcrTokenPath != "" {
+		used = append(used, cli.GcrFlagName)
+	}
+	if opts.Registry != "" {
+		used = append(used, cli.RegistryFlagName)
+	}
+	if len(used) > 1 {
+		errs = errs.Also(cli.ErrMultipleOneOf(used...))
+	}
+
+	if len(used) != 0 {
+		if opts.Credential == "" {
+			errs = errs.Also(cli.ErrMissingField(cli.CredentialFlagName))
+		} else {
+			errs = errs.Also(validation.K8sName(opts.Credential, cli.CredentialFlagName))
+		}
+	} else if opts.DefaultImagePrefix != "" {
+		errs = errs.Also(cli.ErrMissingOneOf(cli.DockerHubFlagName, cli.GcrFlagName, cli.RegistryFlagName))
+	}
+
+	if opts.DockerHubId != "" && len(opts.DockerHubPassword) == 0 {
+		errs = errs.Also(cli.ErrMissingField("<docker-hub-password>"))
+	}
+
+	if len(opts.RegistryPassword) != 0 && opts.RegistryUser == "" {
+		errs = errs.Also(cli.ErrMissingField(cli.RegistryUserFlagName))
+	}
+
+	for i, group := range opts.Groups {
+		if strings.TrimSpace(group) == "" {
+			errs = errs.Also(cli.ErrInvalidArrayValue(group, cli.GroupFlagName, i))
+		}
+	}
+
+	return errs
+}
+
+func (opts *NamespaceInitOptions) Exec(ctx context.Context, c *cli.Config) error {
+	created, err := opts.applyNamespace(ctx, c)
+	if err != nil {
+		return err
+	}
+	if created && c.DryRunMode == cli.ServerDryRun {
+		// the server rejects resources in a namespace that does not exist,
+		// even in dry run mode
+		c.Infof("Namespace %q does not exist, resources in the namespace are printed without a server dry run\n", opts.Name)
+		defer func(mode string) { c.DryRunMode = mode }(c.DryRunMode)
+		c.DryRunMode = cli.ClientDryRun
+	}
+
+	if opts.DockerHubId != "" || opts.GcrTokenPath != "" || opts.Registry != "" {
+		credential := &buildcommands.CredentialApplyOptions{
+			ResourceOptions: cli.ResourceOptions{
+				Namespace: opts.Name,
+				Name:      opts.Credential,
+			},
+			DockerHubId:        opts.DockerHubId,
+			DockerHubPassword:  opts.DockerHubPassword,
+			GcrTokenPath:       opts.GcrTokenPath,
+			Registry:           opts.Registry,
+			RegistryUser:       opts.RegistryUser,
+			RegistryPassword:   opts.RegistryPassword,
+			DefaultImagePrefix: opts.DefaultImagePrefix,
+			// the prefix can only be derived for docker hub and gcr
+			SetDefaultImagePrefix: opts.Registry == "",
+			DryRun:                opts.DryRun,
+		}
+		if err := credential.Exec(ctx, c); err != nil {
+			return err
+		}
+	}
+
+	if len(opts.Groups) != 0 {
+		if err := opts.applyRole(ctx, c); err != nil {
+			return err
+		}
+		if err := opts.applyRoleBinding(ctx, c); err != nil {
+			return err
+		}
+	}
+
+	c.Successf("Initialized namespace %q\n", opts.Name)
+	return nil
+}
+
+func (opts *NamespaceInitOptions) IsDryRun() bool {
+	return opts.DryRun
+}
+
+func NewNamespaceInitCommand(ctx context.Context, c *cli.Config) *cobra.Command {
+	opts := &NamespaceInitOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "prepare a namespace for " + c.Name + " workloads",
+		Long: strings.TrimSpace(`
+Prepare a namespace for ` + c.Name + ` workloads. The namespace is created if it does
+not exist.
+
+Credentials for a container registry are applied when ` + cli.DockerHubFlagName + `, ` + cli.GcrFlagName + ` or
+` + cli.RegistryFlagName + ` is specified, as with the 'credential apply' command. For Docker Hub and
+GCR the default image prefix is derived from the credential, for other
+registries specify ` + cli.DefaultImagePrefixFlagName + `.
+
+Each ` + cli.GroupFlagName + ` is bound to the '` + namespaceInitRoleName + `' Role, which grants access to the resources
+'` + c.Name + ` doctor' checks for the runtimes enabled in this CLI.
+
+Running init again is safe: existing resources are updated to the desired state
+and existing group bindings are kept.
+`),
+		Example: strings.Join([]string{
+			fmt.Sprintf("%s namespace init my-namespace", c.Name),
+			fmt.Sprintf("%s namespace init my-namespace %s my-docker-id", c.Name, cli.DockerHubFlagName),
+			fmt.Sprintf("%s namespace init my-namespace %s path/to/token.json %s my-team", c.Name, cli.GcrFlagName, cli.GroupFlagName),
+			fmt.Sprintf("%s namespace init my-namespace %s http://registry.example.com %s my-username %s registry.example.com/my-username", c.Name, cli.RegistryFlagName, cli.RegistryUserFlagName, cli.DefaultImagePrefixFlagName),
+		}, "\n"),
+		PreRunE: cli.Sequence(
+			func(cmd *cobra.Command, args []string) error {
+				if opts.DockerHubId != "" {
+					return cli.ReadStdin(c, &opts.DockerHubPassword, "Docker Hub password")(cmd, args)
+				}
+				if opts.RegistryUser != "" {
+					return cli.ReadStdin(c, &opts.RegistryPassword, "Registry password")(cmd, args)
+				}
+				return nil
+			},
+			cli.ValidateOptions(ctx, opts),
+		),
+		RunE: cli.ExecOptions(ctx, c, opts),
+	}
+
+	cli.Args(cmd,
+		cli.NameArg(&opts.Name),
+	)
+
+	cmd.Flags().StringVar(&opts.Credential, cli.StripDash(cli.CredentialFlagName), "registry-credentials", "`name` of the credential to apply")
+	cmd.Flags().StringVar(&opts.DockerHubId, cli.StripDash(cli.DockerHubFlagName), "", "Docker Hub `username`, the password must be provided via stdin")
+	cmd.Flags().StringVar(&opts.GcrTokenPath, cli.StripDash(cli.GcrFlagName), "", "path to Google Container Registry service account token `file`")
+	cmd.Flags().StringVar(&opts.Registry, cli.StripDash(cli.RegistryFlagName), "", "registry `url`")
+	cmd.Flags().StringVar(&opts.RegistryUser, cli.StripDash(cli.RegistryUserFlagName), "", "`username` for a registry, the password must be provided via stdin")
+	cmd.Flags().StringVar(&opts.DefaultImagePrefix, cli.StripDash(cli.DefaultImagePrefixFlagName), "", "default `repository` prefix for built images")
+	cmd.Flags().StringArrayVar(&opts.Groups, cli.StripDash(cli.GroupFlagName), []string{}, "`name` of a group to grant access to the namespace (may be set multiple times)")
+	cli.DryRunFlags(cmd, c, &opts.DryRun)
+
+	return cmd
+}
+
+// applyNamespace creates the namespace if it does not exist, created is true
+// when the namespace is created, or would be for a dry run.
+func (opts *NamespaceInitOptions) applyNamespace(ctx context.Context, c *cli.Config) (bool, error) {
+	_, err := c.Core().Namespaces().Get(opts.Name, metav1.GetOptions{})
+	if err == nil {
+		return false, nil
+	}
+	if !apierrs.IsNotFound(err) {
+		return false, err
+	}
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: opts.Name,
+		},
+	}
+	if opts.DryRun {
+		create := func() (runtime.Object, error) {
+			return k8s.DryRunCreate(c.Core().RESTClient(), "namespaces", namespace)
+		}
+		return true, cli.DryRunApply(ctx, c, nil, namespace, corev1.SchemeGroupVersion.WithKind("Namespace"), create)
+	}
+	if _, err := c.Core().Namespaces().Create(namespace); err != nil {
+		return false, err
+	}
+	c.Successf("Created namespace %q\n", opts.Name)
+	return true, nil
+}
+
+func (opts *NamespaceInitOptions) applyRole(ctx context.Context, c *cli.Config) error {
+	rules := namespaceInitRules(namespaceUserResources(opts.Name, c.Runtimes))
+
+	existing, err := c.Rbac().Roles(opts.Name).Get(namespaceInitRoleName, metav1.GetOptions{})
+	if err != nil {
+		if !apierrs.IsNotFound(err) {
+			return err
+		}
+
+		role := &rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: opts.Name,
+				Name:      namespaceInitRoleName,
+			},
+			Rules: rules,
+		}
+		if opts.DryRun {
+			create := func() (runtime.Object, error) {
+				return k8s.DryRunCreate(c.Rbac().RESTClient(), "roles", role)
+			}
+			return cli.DryRunApply(ctx, c, nil, role, rbacv1.SchemeGroupVersion.WithKind("Role"), create)
+		}
+		if _, err := c.Rbac().Roles(opts.Name).Create(role); err != nil {
+			return err
+		}
+		c.Successf("Created role %q\n", namespaceInitRoleName)
+		return nil
+	}
+
+	if equality.Semantic.DeepEqual(existing.Rules, rules) {
+		return nil
+	}
+	role := existing.DeepCopy()
+	role.Rules = rules
+	if opts.DryRun {
+		update := func() (runtime.Object, error) {
+			return k8s.DryRunUpdate(c.Rbac().RESTClient(), "roles", role)
+		}
+		return cli.DryRunApply(ctx, c, existing, role, rbacv1.SchemeGroupVersion.WithKind("Role"), update)
+	}
+	if _, err := c.Rbac().Roles(opts.Name).Update(role); err != nil {
+		return err
+	}
+	c.Successf("Updated role %q\n", namespaceInitRoleName)
+	return nil
+}
+
+func (opts *NamespaceInitOptions) applyRoleBinding(ctx context.Context, c *cli.Config) error {
+	roleRef := rbacv1.RoleRef{
+		APIGroup: rbacv1.GroupName,
+		Kind:     "Role",
+		Name:     namespaceInitRoleName,
+	}
+
+	existing, err := c.Rbac().RoleBindings(opts.Name).Get(namespaceInitRoleName, metav1.GetOptions{})
+	if err != nil {
+		if !apierrs.IsNotFound(err) {
+			return err
+		}
+
+		binding := &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: opts.Name,
+				Name:      namespaceInitRoleName,
+			},
+			RoleRef: roleRef,
+		}
+		for _, group := range opts.Groups {
+			binding.Subjects = addGroupSubject(binding.Subjects, group)
+		}
+		if opts.DryRun {
+			create := func() (runtime.Object, error) {
+				return k8s.DryRunCreate(c.Rbac().RESTClient(), "rolebindings", binding)
+			}
+			return cli.DryRunApply(ctx, c, nil, binding, rbacv1.SchemeGroupVersion.WithKind("RoleBinding"), create)
+		}
+		if _, err := c.Rbac().RoleBindings(opts.Name).Create(binding); err != nil {
+			return err
+		}
+		c.Successf("Created role binding %q for groups %s\n", namespaceInitRoleName, strings.Join(opts.Groups, ", "))
+		return nil
+	}
+
+	// the role of a binding cannot be changed
+	if existing.RoleRef != roleRef {
+		return fmt.Errorf("role binding %q exists, but is not bound to role %q", namespaceInitRoleName, namespaceInitRoleName)
+	}
+	binding := existing.DeepCopy()
+	added := []string{}
+	for _, group := range opts.Groups {
+		subjects := addGroupSubject(binding.Subjects, group)
+		if len(subjects) != len(binding.Subjects) {
+			added = append(added, group)
+		}
+		binding.Subjects = subjects
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	if opts.DryRun {
+		update := func() (runtime.Object, error) {
+			return k8s.DryRunUpdate(c.Rbac().RESTClient(), "rolebindings", binding)
+		}
+		return cli.DryRunApply(ctx, c, existing, binding, rbacv1.SchemeGroupVersion.WithKind("RoleBinding"), update)
+	}
+	if _, err := c.Rbac().RoleBindings(opts.Name).Update(binding); err != nil {
+		return err
+	}
+	c.Successf("Updated role binding %q for groups %s\n", namespaceInitRoleName, strings.Join(added, ", "))
+	return nil
+}
+
+// namespaceInitRules converts user resources to policy rules, combining consecutive resources
+// in the same group with the same verbs into a single rule
+func namespaceInitRules(resources []userResource) []rbacv1.PolicyRule {
+	rules := []rbacv1.PolicyRule{}
+	for _, access := range resources {
+		group := access.Attributes.Group
+		if group == "core" {
+			group = ""
+		}
+		resource := access.Attributes.Resource
+		if access.Attributes.Subresource != "" {
+			resource = fmt.Sprintf("%s/%s", resource, access.Attributes.Subresource)
+		}
+		if last := len(rules) - 1; last >= 0 && rules[last].APIGroups[0] == group && equality.Semantic.DeepEqual(rules[last].Verbs, access.Verbs) {
+			rules[last].Resources = append(rules[last].Resources, resource)
+			continue
+		}
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{group},
+			Resources: []string{resource},
+			Verbs:     append([]string{}, access.Verbs...),
+		})
+	}
+	return rules
+}
+
+func addGroupSubject(subjects []rbacv1.Subject, group string) []rbacv1.Subject {
+	for _, subject := range subjects {
+		if subject.Kind == rbacv1.GroupKind && subject.Name == group {
+			return subjects
+		}
+	}
+	return append(subjects, rbacv1.Subject{
+		Kind:     rbacv1.GroupKind,
+		APIGroup: rbacv1.GroupName,
+		Name:     group,
+	})
+}
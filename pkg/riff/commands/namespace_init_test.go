@@ -0,0 +1,629 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/projectriff/cli/pkg/riff/commands"
+	rifftesting "github.com/projectriff/cli/pkg/testing"
+	"github.com/projectriff/system/pkg/apis/build"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestNamespaceInitOptions(t *testing.T) {
+	table := rifftesting.OptionsTable{
+		{
+			Name: "valid",
+			Options: &commands.NamespaceInitOptions{
+				Name: "my-namespace",
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name:             "missing name",
+			Options:          &commands.NamespaceInitOptions{},
+			ExpectFieldError: cli.ErrMissingField(cli.NameArgumentName),
+		},
+		{
+			Name: "invalid name",
+			Options: &commands.NamespaceInitOptions{
+				Name: "my.namespace",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("my.namespace", cli.NameArgumentName),
+		},
+		{
+			Name: "docker hub",
+			Options: &commands.NamespaceInitOptions{
+				Name:              "my-namespace",
+				Credential:        "my-creds",
+				DockerHubId:       "projectriff",
+				DockerHubPassword: []byte("1password"),
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "docker hub missing password",
+			Options: &commands.NamespaceInitOptions{
+				Name:        "my-namespace",
+				Credential:  "my-creds",
+				DockerHubId: "projectriff",
+			},
+			ExpectFieldError: cli.ErrMissingField("<docker-hub-password>"),
+		},
+		{
+			Name: "gcr",
+			Options: &commands.NamespaceInitOptions{
+				Name:         "my-namespace",
+				Credential:   "my-creds",
+				GcrTokenPath: "gcr-credentials.json",
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "registry",
+			Options: &commands.NamespaceInitOptions{
+				Name:               "my-namespace",
+				Credential:         "my-creds",
+				Registry:           "example.com",
+				RegistryUser:       "projectriff",
+				RegistryPassword:   []byte("1password"),
+				DefaultImagePrefix: "example.com/projectriff",
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "registry password without user",
+			Options: &commands.NamespaceInitOptions{
+				Name:             "my-namespace",
+				Credential:       "my-creds",
+				Registry:         "example.com",
+				RegistryPassword: []byte("1password"),
+			},
+			ExpectFieldError: cli.ErrMissingField(cli.RegistryUserFlagName),
+		},
+		{
+			Name: "multiple credentials",
+			Options: &commands.NamespaceInitOptions{
+				Name:              "my-namespace",
+				Credential:        "my-creds",
+				DockerHubId:       "projectriff",
+				DockerHubPassword: []byte("1password"),
+				GcrTokenPath:      "gcr-credentials.json",
+			},
+			ExpectFieldError: cli.ErrMultipleOneOf(cli.DockerHubFlagName, cli.GcrFlagName),
+		},
+		{
+			Name: "missing credential name",
+			Options: &commands.NamespaceInitOptions{
+				Name:         "my-namespace",
+				GcrTokenPath: "gcr-credentials.json",
+			},
+			ExpectFieldError: cli.ErrMissingField(cli.CredentialFlagName),
+		},
+		{
+			Name: "invalid credential name",
+			Options: &commands.NamespaceInitOptions{
+				Name:         "my-namespace",
+				Credential:   "my.creds",
+				GcrTokenPath: "gcr-credentials.json",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("my.creds", cli.CredentialFlagName),
+		},
+		{
+			Name: "default image prefix without credentials",
+			Options: &commands.NamespaceInitOptions{
+				Name:               "my-namespace",
+				DefaultImagePrefix: "example.com/projectriff",
+			},
+			ExpectFieldError: cli.ErrMissingOneOf(cli.DockerHubFlagName, cli.GcrFlagName, cli.RegistryFlagName),
+		},
+		{
+			Name: "groups",
+			Options: &commands.NamespaceInitOptions{
+				Name:   "my-namespace",
+				Groups: []string{"my-team", "my-other-team"},
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "invalid group",
+			Options: &commands.NamespaceInitOptions{
+				Name:   "my-namespace",
+				Groups: []string{"my-team", ""},
+			},
+			ExpectFieldError: cli.ErrInvalidArrayValue("", cli.GroupFlagName, 1),
+		},
+		{
+			Name: "dry run",
+			Options: &commands.NamespaceInitOptions{
+				Name:   "my-namespace",
+				DryRun: true,
+			},
+			ShouldValidate: true,
+		},
+	}
+
+	table.Run(t)
+}
+
+func TestNamespaceInitCommand(t *testing.T) {
+	namespaceName := "my-namespace"
+	dockerHubId := "projectriff"
+	dockerHubPassword := "docker-password"
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: namespaceName,
+		},
+	}
+	verbs := []string{"get", "list", "create", "update", "delete", "patch", "watch"}
+	rules := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps", "secrets"}, Verbs: verbs},
+		{APIGroups: []string{""}, Resources: []string{"pods", "pods/log"}, Verbs: []string{"get", "list", "watch"}},
+		{APIGroups: []string{"build.projectriff.io"}, Resources: []string{"applications", "containers", "functions"}, Verbs: verbs},
+		{APIGroups: []string{"core.projectriff.io"}, Resources: []string{"deployers"}, Verbs: verbs},
+	}
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespaceName,
+			Name:      "riff",
+		},
+		Rules: rules,
+	}
+	roleRef := rbacv1.RoleRef{
+		APIGroup: "rbac.authorization.k8s.io",
+		Kind:     "Role",
+		Name:     "riff",
+	}
+	binding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespaceName,
+			Name:      "riff",
+		},
+		RoleRef: roleRef,
+		Subjects: []rbacv1.Subject{
+			{Kind: "Group", APIGroup: "rbac.authorization.k8s.io", Name: "my-team"},
+		},
+	}
+
+	table := rifftesting.CommandTable{
+		{
+			Name:        "invalid args",
+			Args:        []string{},
+			ShouldError: true,
+		},
+		{
+			Name: "create namespace",
+			Args: []string{namespaceName},
+			ExpectCreates: []runtime.Object{
+				namespace,
+			},
+			ExpectOutput: `
+Created namespace "my-namespace"
+Initialized namespace "my-namespace"
+`,
+		},
+		{
+			Name: "existing namespace",
+			Args: []string{namespaceName},
+			GivenObjects: []runtime.Object{
+				namespace,
+			},
+			ExpectOutput: `
+Initialized namespace "my-namespace"
+`,
+		},
+		{
+			Name:  "docker hub credentials",
+			Args:  []string{namespaceName, cli.DockerHubFlagName, dockerHubId, cli.CredentialFlagName, "my-creds"},
+			Stdin: []byte(dockerHubPassword),
+			GivenObjects: []runtime.Object{
+				namespace,
+			},
+			ExpectCreates: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: namespaceName,
+						Name:      "my-creds",
+						Labels:    map[string]string{build.CredentialLabelKey: "docker-hub"},
+						Annotations: map[string]string{
+							"build.knative.dev/docker-0": "https://index.docker.io/v1/",
+							"build.pivotal.io/docker":    "https://index.docker.io/v1/",
+						},
+					},
+					Type: corev1.SecretTypeBasicAuth,
+					StringData: map[string]string{
+						"username": dockerHubId,
+						"password": dockerHubPassword,
+					},
+				},
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: namespaceName,
+						Name:      "riff-build",
+					},
+					Data: map[string]string{
+						"default-image-prefix": "docker.io/projectriff",
+					},
+				},
+			},
+			ExpectOutput: `
+Apply credentials "my-creds"
+Set default image prefix to "docker.io/projectriff"
+Initialized namespace "my-namespace"
+`,
+		},
+		{
+			Name:  "registry credentials without default image prefix",
+			Args:  []string{namespaceName, cli.RegistryFlagName, "https://example.com", cli.RegistryUserFlagName, "projectriff"},
+			Stdin: []byte("registry-password"),
+			GivenObjects: []runtime.Object{
+				namespace,
+			},
+			ExpectCreates: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: namespaceName,
+						Name:      "registry-credentials",
+						Labels:    map[string]string{build.CredentialLabelKey: "basic-auth"},
+						Annotations: map[string]string{
+							"build.knative.dev/docker-0": "https://example.com",
+							"build.pivotal.io/docker":    "https://example.com",
+						},
+					},
+					Type: corev1.SecretTypeBasicAuth,
+					StringData: map[string]string{
+						"username": "projectriff",
+						"password": "registry-password",
+					},
+				},
+			},
+			ExpectOutput: `
+Apply credentials "registry-credentials"
+Initialized namespace "my-namespace"
+`,
+		},
+		{
+			Name: "groups",
+			Args: []string{namespaceName, cli.GroupFlagName, "my-team", cli.GroupFlagName, "my-other-team"},
+			GivenObjects: []runtime.Object{
+				namespace,
+			},
+			ExpectCreates: []runtime.Object{
+				role,
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: namespaceName,
+						Name:      "riff",
+					},
+					RoleRef: roleRef,
+					Subjects: []rbacv1.Subject{
+						{Kind: "Group", APIGroup: "rbac.authorization.k8s.io", Name: "my-team"},
+						{Kind: "Group", APIGroup: "rbac.authorization.k8s.io", Name: "my-other-team"},
+					},
+				},
+			},
+			ExpectOutput: `
+Created role "riff"
+Created role binding "riff" for groups my-team, my-other-team
+Initialized namespace "my-namespace"
+`,
+		},
+		{
+			Name: "groups with all runtimes",
+			Args: []string{namespaceName, cli.GroupFlagName, "my-team"},
+			Config: &cli.Config{
+				CompiledEnv: cli.CompiledEnv{
+					Runtimes: map[string]bool{
+						cli.CoreRuntime:      true,
+						cli.StreamingRuntime: true,
+						cli.KnativeRuntime:   true,
+					},
+				},
+			},
+			GivenObjects: []runtime.Object{
+				namespace,
+			},
+			ExpectCreates: []runtime.Object{
+				&rbacv1.Role{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: namespaceName,
+						Name:      "riff",
+					},
+					Rules: append(rules[:len(rules):len(rules)],
+						rbacv1.PolicyRule{APIGroups: []string{"streaming.projectriff.io"}, Resources: []string{"processors", "streams"}, Verbs: verbs},
+						rbacv1.PolicyRule{APIGroups: []string{"knative.projectriff.io"}, Resources: []string{"adapters", "deployers"}, Verbs: verbs},
+					),
+				},
+				binding,
+			},
+			ExpectOutput: `
+Created role "riff"
+Created role binding "riff" for groups my-team
+Initialized namespace "my-namespace"
+`,
+		},
+		{
+			Name: "groups already bound",
+			Args: []string{namespaceName, cli.GroupFlagName, "my-team"},
+			GivenObjects: []runtime.Object{
+				namespace,
+				role,
+				binding,
+			},
+			ExpectOutput: `
+Initialized namespace "my-namespace"
+`,
+		},
+		{
+			Name: "add group to existing binding",
+			Args: []string{namespaceName, cli.GroupFlagName, "my-team", cli.GroupFlagName, "my-other-team"},
+			GivenObjects: []runtime.Object{
+				namespace,
+				role,
+				binding,
+			},
+			ExpectUpdates: []runtime.Object{
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: namespaceName,
+						Name:      "riff",
+					},
+					RoleRef: roleRef,
+					Subjects: []rbacv1.Subject{
+						{Kind: "Group", APIGroup: "rbac.authorization.k8s.io", Name: "my-team"},
+						{Kind: "Group", APIGroup: "rbac.authorization.k8s.io", Name: "my-other-team"},
+					},
+				},
+			},
+			ExpectOutput: `
+Updated role binding "riff" for groups my-other-team
+Initialized namespace "my-namespace"
+`,
+		},
+		{
+			Name: "update stale role",
+			Args: []string{namespaceName, cli.GroupFlagName, "my-team"},
+			GivenObjects: []runtime.Object{
+				namespace,
+				&rbacv1.Role{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: namespaceName,
+						Name:      "riff",
+					},
+					Rules: rules[:1],
+				},
+				binding,
+			},
+			ExpectUpdates: []runtime.Object{
+				role,
+			},
+			ExpectOutput: `
+Updated role "riff"
+Initialized namespace "my-namespace"
+`,
+		},
+		{
+			Name: "binding for another role",
+			Args: []string{namespaceName, cli.GroupFlagName, "my-team"},
+			GivenObjects: []runtime.Object{
+				namespace,
+				role,
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: namespaceName,
+						Name:      "riff",
+					},
+					RoleRef: rbacv1.RoleRef{
+						APIGroup: "rbac.authorization.k8s.io",
+						Kind:     "ClusterRole",
+						Name:     "admin",
+					},
+				},
+			},
+			ShouldError: true,
+		},
+		{
+			Name: "dry run",
+			Args: []string{namespaceName, cli.GroupFlagName, "my-team", cli.DryRunFlagName},
+			ExpectOutput: `
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  creationTimestamp: null
+  name: my-namespace
+spec: {}
+status: {}
+
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  creationTimestamp: null
+  name: riff
+  namespace: my-namespace
+rules:
+- apiGroups:
+  - ""
+  resources:
+  - configmaps
+  - secrets
+  verbs:
+  - get
+  - list
+  - create
+  - update
+  - delete
+  - patch
+  - watch
+- apiGroups:
+  - ""
+  resources:
+  - pods
+  - pods/log
+  verbs:
+  - get
+  - list
+  - watch
+- apiGroups:
+  - build.projectriff.io
+  resources:
+  - applications
+  - containers
+  - functions
+  verbs:
+  - get
+  - list
+  - create
+  - update
+  - delete
+  - patch
+  - watch
+- apiGroups:
+  - core.projectriff.io
+  resources:
+  - deployers
+  verbs:
+  - get
+  - list
+  - create
+  - update
+  - delete
+  - patch
+  - watch
+
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  creationTimestamp: null
+  name: riff
+  namespace: my-namespace
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: riff
+subjects:
+- apiGroup: rbac.authorization.k8s.io
+  kind: Group
+  name: my-team
+
+Initialized namespace "my-namespace"
+`,
+		},
+		{
+			Name: "server dry run",
+			Args: []string{namespaceName, cli.GroupFlagName, "my-team", cli.DryRunFlagName + "=" + cli.ServerDryRun},
+			GivenObjects: []runtime.Object{
+				namespace,
+			},
+			Verify: func(t *testing.T, output string, err error) {
+				// the role and binding are returned by the server
+				if expected, actual := 2, strings.Count(output, "uid: "+rifftesting.DryRunUID); expected != actual {
+					t.Errorf("expected %d resources from the server, actually %d", expected, actual)
+				}
+			},
+		},
+		{
+			Name: "server dry run, new namespace",
+			Args: []string{namespaceName, cli.GroupFlagName, "my-team", cli.DryRunFlagName + "=" + cli.ServerDryRun},
+			Verify: func(t *testing.T, output string, err error) {
+				// only the namespace is returned by the server
+				if expected, actual := 1, strings.Count(output, "uid: "+rifftesting.DryRunUID); expected != actual {
+					t.Errorf("expected %d resources from the server, actually %d", expected, actual)
+				}
+				if expected := `Namespace "my-namespace" does not exist, resources in the namespace are printed without a server dry run`; !strings.Contains(output, expected) {
+					t.Errorf("expected output to contain %q, actually %q", expected, output)
+				}
+				for _, kind := range []string{"kind: Namespace", "kind: Role", "kind: RoleBinding"} {
+					if !strings.Contains(output, kind) {
+						t.Errorf("expected output to contain %q", kind)
+					}
+				}
+			},
+		},
+		{
+			Name: "error getting namespace",
+			Args: []string{namespaceName},
+			WithReactors: []rifftesting.ReactionFunc{
+				rifftesting.InduceFailure("get", "namespaces"),
+			},
+			ShouldError: true,
+		},
+		{
+			Name: "error creating namespace",
+			Args: []string{namespaceName},
+			WithReactors: []rifftesting.ReactionFunc{
+				rifftesting.InduceFailure("create", "namespaces"),
+			},
+			ExpectCreates: []runtime.Object{
+				namespace,
+			},
+			ShouldError: true,
+		},
+		{
+			Name:  "error applying credentials",
+			Args:  []string{namespaceName, cli.DockerHubFlagName, dockerHubId},
+			Stdin: []byte(dockerHubPassword),
+			GivenObjects: []runtime.Object{
+				namespace,
+			},
+			WithReactors: []rifftesting.ReactionFunc{
+				rifftesting.InduceFailure("get", "secrets"),
+			},
+			ShouldError: true,
+		},
+		{
+			Name: "error creating role",
+			Args: []string{namespaceName, cli.GroupFlagName, "my-team"},
+			GivenObjects: []runtime.Object{
+				namespace,
+			},
+			WithReactors: []rifftesting.ReactionFunc{
+				rifftesting.InduceFailure("create", "roles"),
+			},
+			ExpectCreates: []runtime.Object{
+				role,
+			},
+			ShouldError: true,
+		},
+		{
+			Name: "error creating role binding",
+			Args: []string{namespaceName, cli.GroupFlagName, "my-team"},
+			GivenObjects: []runtime.Object{
+				namespace,
+			},
+			WithReactors: []rifftesting.ReactionFunc{
+				rifftesting.InduceFailure("create", "rolebindings"),
+			},
+			ExpectCreates: []runtime.Object{
+				role,
+				binding,
+			},
+			ShouldError: true,
+		},
+	}
+
+	table.Run(t, commands.NewNamespaceInitCommand)
+}
@@ -0,0 +1,222 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+func NewPluginCommand(ctx context.Context, c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plugin",
+		Short: "extend " + c.Name + " with executables on the PATH",
+		Long: strings.TrimSpace(`
+Plugins are executables on the PATH named ` + c.Name + `-<name>. Running
+'` + c.Name + ` <name>' executes the plugin with the remaining arguments, when
+<name> is not a built-in command. Dashes in the plugin name become separate
+words, '` + c.Name + `-cost-report' runs as '` + c.Name + ` cost report'.
+
+The plugin runs with the settings resolved by ` + c.Name + ` in its environment:
+
+- KUBECONFIG, the kubectl config file
+- ` + envName(c, "context") + `, the kubectl config context, when set
+- ` + envName(c, "cluster") + `, the kubectl config cluster, when set
+- ` + envName(c, "user") + `, the kubectl config user, when set
+- ` + envName(c, "as") + `, the username to impersonate, when set
+- ` + envName(c, "as-group") + `, the comma separated groups to impersonate, when set
+- ` + envName(c, "request-timeout") + `, the timeout for requests to the cluster, when set
+- ` + envName(c, "namespace") + `, the default namespace, unset when the kubectl config is invalid
+- ` + envName(c, "no-color") + `, "true" when color output is disabled
+`),
+		Aliases: []string{"plugins"},
+	}
+
+	cmd.AddCommand(NewPluginListCommand(ctx, c))
+
+	return cmd
+}
+
+// plugin is an executable on the PATH that extends the CLI.
+type plugin struct {
+	Name string
+	Path string
+	// ShadowedBy is the path of a plugin with the same name that is found
+	// earlier on the PATH
+	ShadowedBy string
+}
+
+func pluginPrefix(c *cli.Config) string {
+	return c.Name + "-"
+}
+
+// findPlugins lists the plugins in the directories of the PATH, in PATH order.
+func findPlugins(c *cli.Config) []plugin {
+	plugins := []plugin{}
+	found := map[string]string{}
+	seen := map[string]bool{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			if !strings.HasPrefix(file.Name(), pluginPrefix(c)) || !isExecutable(file) {
+				continue
+			}
+			name := strings.TrimPrefix(file.Name(), pluginPrefix(c))
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			p := plugin{
+				Name:       name,
+				Path:       filepath.Join(dir, file.Name()),
+				ShadowedBy: found[name],
+			}
+			if p.ShadowedBy == "" {
+				found[name] = p.Path
+			}
+			plugins = append(plugins, p)
+		}
+	}
+	return plugins
+}
+
+func isExecutable(file os.FileInfo) bool {
+	if file.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		switch strings.ToLower(filepath.Ext(file.Name())) {
+		case ".bat", ".cmd", ".com", ".exe", ".ps1":
+			return true
+		}
+		return false
+	}
+	return file.Mode()&0111 != 0
+}
+
+// lookupPlugin finds the plugin with the longest name matching the leading
+// args. The remaining args are returned for the plugin.
+func lookupPlugin(c *cli.Config, args []string) (string, []string) {
+	words := 0
+	for words < len(args) && !strings.HasPrefix(args[words], "-") {
+		words++
+	}
+	for i := words; i > 0; i-- {
+		path, err := exec.LookPath(pluginPrefix(c) + strings.Join(args[:i], "-"))
+		if err == nil {
+			return path, args[i:]
+		}
+	}
+	return "", args
+}
+
+// execPlugin runs the plugin for the args, which did not match a built-in
+// command.
+func execPlugin(ctx context.Context, c *cli.Config, root *cobra.Command, args []string) error {
+	path, pluginArgs := lookupPlugin(c, args)
+	if path == "" {
+		msg := fmt.Sprintf("unknown command %q for %q", args[0], root.Name())
+		if root.SuggestionsMinimumDistance <= 0 {
+			root.SuggestionsMinimumDistance = 2
+		}
+		if suggestions := root.SuggestionsFor(args[0]); len(suggestions) > 0 {
+			msg += "\n\nDid you mean this?\n"
+			for _, suggestion := range suggestions {
+				msg += fmt.Sprintf("\t%s\n", suggestion)
+			}
+		}
+		return fmt.Errorf("%s", msg)
+	}
+
+	cmd := c.Exec(ctx, path, pluginArgs...)
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	env, err := pluginEnv(c)
+	if err != nil {
+		// plugins do not always talk to the cluster, so the plugin still runs
+		c.Eerrorf("Unable to resolve the default namespace for plugin %q: %v\n", filepath.Base(path), err)
+	}
+	cmd.Env = append(cmd.Env, env...)
+	cmd.Stdin = c.Stdin
+	cmd.Stdout = c.Stdout
+	cmd.Stderr = c.Stderr
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			// the plugin is responsible for reporting its own errors
+			return cli.SilenceError(err)
+		}
+		return err
+	}
+	return nil
+}
+
+// pluginEnv passes the settings resolved by the CLI to plugins. The namespace
+// is left out when it can not be resolved from the kube config, the error is
+// returned along with the rest of the settings.
+func pluginEnv(c *cli.Config) ([]string, error) {
+	env := []string{}
+	if c.KubeConfigFile != "" {
+		env = append(env, fmt.Sprintf("KUBECONFIG=%s", c.KubeConfigFile))
+	}
+	overrides := c.KubeConfigOverrides
+	settings := []struct {
+		flag  string
+		value string
+	}{
+		{cli.ContextFlagName, overrides.CurrentContext},
+		{cli.ClusterFlagName, overrides.Context.Cluster},
+		{cli.UserFlagName, overrides.Context.AuthInfo},
+		{cli.AsFlagName, overrides.AuthInfo.Impersonate},
+		{cli.AsGroupFlagName, strings.Join(overrides.AuthInfo.ImpersonateGroups, ",")},
+		{cli.RequestTimeoutFlagName, overrides.Timeout},
+	}
+	for _, setting := range settings {
+		if setting.value != "" {
+			env = append(env, fmt.Sprintf("%s=%s", envName(c, cli.StripDash(setting.flag)), setting.value))
+		}
+	}
+	namespace, err := c.LoadDefaultNamespace()
+	if err == nil && namespace != "" {
+		env = append(env, fmt.Sprintf("%s=%s", envName(c, "namespace"), namespace))
+	}
+	env = append(env, fmt.Sprintf("%s=%s", envName(c, "no-color"), strconv.FormatBool(color.NoColor)))
+	return env, err
+}
+
+// envName is the environment variable for a setting, matching the variables
+// read from the environment for config settings.
+func envName(c *cli.Config, setting string) string {
+	return strings.ToUpper(strings.ReplaceAll(c.Name+"_"+setting, "-", "_"))
+}
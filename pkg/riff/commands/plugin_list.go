@@ -0,0 +1,100 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/projectriff/cli/pkg/cli/printers"
+	"github.com/spf13/cobra"
+)
+
+type PluginListOptions struct{}
+
+var (
+	_ cli.Validatable = (*PluginListOptions)(nil)
+	_ cli.Executable  = (*PluginListOptions)(nil)
+)
+
+func (opts *PluginListOptions) Validate(ctx context.Context) *cli.FieldError {
+	return cli.EmptyFieldError
+}
+
+func (opts *PluginListOptions) Exec(ctx context.Context, c *cli.Config) error {
+	plugins := findPlugins(c)
+	if len(plugins) == 0 {
+		c.Infof("No plugins found.\n")
+		return nil
+	}
+
+	root := cli.CommandFromContext(ctx).Root()
+	builtin := map[string]bool{}
+	for _, cmd := range root.Commands() {
+		builtin[cmd.Name()] = true
+		for _, alias := range cmd.Aliases {
+			builtin[alias] = true
+		}
+	}
+
+	printer := printers.GetNewTabWriter(c.Stdout)
+	fmt.Fprintf(printer, "NAME\tPATH\n")
+	warnings := []string{}
+	for _, p := range plugins {
+		fmt.Fprintf(printer, "%s\t%s\n", strings.ReplaceAll(p.Name, "-", " "), p.Path)
+		if p.ShadowedBy != "" {
+			warnings = append(warnings, fmt.Sprintf("%s is shadowed by %s earlier on the PATH", p.Path, p.ShadowedBy))
+		} else if command := strings.SplitN(p.Name, "-", 2)[0]; builtin[command] {
+			warnings = append(warnings, fmt.Sprintf("%s is never run, %q is a built-in command", p.Path, command))
+		}
+	}
+	printer.Flush()
+
+	if len(warnings) != 0 {
+		c.Printf("\n")
+	}
+	for _, warning := range warnings {
+		c.Printf("%s\n", cli.Swarnf("Warning: %s", warning))
+	}
+
+	return nil
+}
+
+func NewPluginListCommand(ctx context.Context, c *cli.Config) *cobra.Command {
+	opts := &PluginListOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "list plugins on the PATH",
+		Long: strings.TrimSpace(`
+List the plugins found on the PATH, along with the command to run each.
+
+Plugins are never run when a plugin with the same name is found earlier on the
+PATH, or when the plugin's name starts with a built-in command. A warning is
+printed for each plugin that will not run.
+`),
+		Example: strings.Join([]string{
+			fmt.Sprintf("%s plugin list", c.Name),
+		}, "\n"),
+		PreRunE: cli.ValidateOptions(ctx, opts),
+		RunE:    cli.ExecOptions(ctx, c, opts),
+	}
+
+	return cmd
+}
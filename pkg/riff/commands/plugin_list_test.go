@@ -0,0 +1,91 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/projectriff/cli/pkg/riff/commands"
+	rifftesting "github.com/projectriff/cli/pkg/testing"
+)
+
+// withPath sets the PATH for the test, restoring the original PATH on clean up.
+func withPath(dirs ...string) (func(*testing.T, context.Context, *cli.Config) (context.Context, error), func(*testing.T, context.Context, *cli.Config) error) {
+	original := os.Getenv("PATH")
+	prepare := func(t *testing.T, ctx context.Context, c *cli.Config) (context.Context, error) {
+		return ctx, os.Setenv("PATH", strings.Join(dirs, string(filepath.ListSeparator)))
+	}
+	cleanUp := func(t *testing.T, ctx context.Context, c *cli.Config) error {
+		return os.Setenv("PATH", original)
+	}
+	return prepare, cleanUp
+}
+
+func TestPluginListOptions(t *testing.T) {
+	table := rifftesting.OptionsTable{
+		{
+			Name:           "valid",
+			Options:        &commands.PluginListOptions{},
+			ShouldValidate: true,
+		},
+	}
+
+	table.Run(t)
+}
+
+func TestPluginListCommand(t *testing.T) {
+	prepareEmpty, cleanUpEmpty := withPath(filepath.Join("testdata", "plugins", "missing"))
+	preparePlugins, cleanUpPlugins := withPath(
+		filepath.Join("testdata", "plugins", "first"),
+		filepath.Join("testdata", "plugins", "second"),
+	)
+
+	table := rifftesting.CommandTable{
+		{
+			Name:    "no plugins",
+			Args:    []string{"plugin", "list"},
+			Prepare: prepareEmpty,
+			CleanUp: cleanUpEmpty,
+			ExpectOutput: `
+No plugins found.
+`,
+		},
+		{
+			Name:    "plugins",
+			Args:    []string{"plugin", "list"},
+			Prepare: preparePlugins,
+			CleanUp: cleanUpPlugins,
+			ExpectOutput: `
+NAME            PATH
+cost report     testdata/plugins/first/riff-cost-report
+function logs   testdata/plugins/first/riff-function-logs
+hello           testdata/plugins/first/riff-hello
+hello           testdata/plugins/second/riff-hello
+
+Warning: testdata/plugins/first/riff-function-logs is never run, "function" is a built-in command
+Warning: testdata/plugins/second/riff-hello is shadowed by testdata/plugins/first/riff-hello earlier on the PATH
+`,
+		},
+	}
+
+	table.Run(t, commands.NewRootCommand)
+}
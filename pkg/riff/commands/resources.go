@@ -0,0 +1,99 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"github.com/projectriff/cli/pkg/cli"
+	authv1 "k8s.io/api/authorization/v1"
+)
+
+var (
+	userVerbs     = []string{"get", "list", "create", "update", "delete", "patch", "watch"}
+	userReadVerbs = []string{"get", "list", "watch"}
+)
+
+// userResource is a resource a riff user works with in a namespace and the verbs they need
+type userResource struct {
+	Attributes authv1.ResourceAttributes
+	Verbs      []string
+}
+
+// userResources are the resources every riff user needs access to in a namespace
+var userResources = []userResource{
+	{Attributes: authv1.ResourceAttributes{Group: "core", Resource: "configmaps"}, Verbs: userVerbs},
+	{Attributes: authv1.ResourceAttributes{Group: "core", Resource: "secrets"}, Verbs: userVerbs},
+	{Attributes: authv1.ResourceAttributes{Group: "core", Resource: "pods"}, Verbs: userReadVerbs},
+	{Attributes: authv1.ResourceAttributes{Group: "core", Resource: "pods", Subresource: "log"}, Verbs: userReadVerbs},
+	{Attributes: authv1.ResourceAttributes{Group: "build.projectriff.io", Resource: "applications"}, Verbs: userVerbs},
+	{Attributes: authv1.ResourceAttributes{Group: "build.projectriff.io", Resource: "containers"}, Verbs: userVerbs},
+	{Attributes: authv1.ResourceAttributes{Group: "build.projectriff.io", Resource: "functions"}, Verbs: userVerbs},
+}
+
+// runtimeUserResources are the resources a user of each runtime needs access to, in the order
+// the runtimes are listed
+var runtimeUserResources = []struct {
+	Runtime   string
+	Resources []userResource
+}{
+	{
+		Runtime: cli.CoreRuntime,
+		Resources: []userResource{
+			{Attributes: authv1.ResourceAttributes{Group: "core.projectriff.io", Resource: "deployers"}, Verbs: userVerbs},
+		},
+	},
+	{
+		Runtime: cli.StreamingRuntime,
+		Resources: []userResource{
+			{Attributes: authv1.ResourceAttributes{Group: "streaming.projectriff.io", Resource: "processors"}, Verbs: userVerbs},
+			{Attributes: authv1.ResourceAttributes{Group: "streaming.projectriff.io", Resource: "streams"}, Verbs: userVerbs},
+		},
+	},
+	{
+		Runtime: cli.KnativeRuntime,
+		Resources: []userResource{
+			{Attributes: authv1.ResourceAttributes{Group: "knative.projectriff.io", Resource: "adapters"}, Verbs: userVerbs},
+			{Attributes: authv1.ResourceAttributes{Group: "knative.projectriff.io", Resource: "deployers"}, Verbs: userVerbs},
+		},
+	},
+}
+
+// namespaceUserResources are the resources a user needs access to in the namespace, including
+// the resources of each of the runtimes
+func namespaceUserResources(namespace string, runtimes map[string]bool) []userResource {
+	resources := []userResource{}
+	for _, resource := range userResources {
+		resources = append(resources, resource.inNamespace(namespace))
+	}
+	for _, runtime := range runtimeUserResources {
+		if !runtimes[runtime.Runtime] {
+			continue
+		}
+		for _, resource := range runtime.Resources {
+			resources = append(resources, resource.inNamespace(namespace))
+		}
+	}
+	return resources
+}
+
+func (resource userResource) inNamespace(namespace string) userResource {
+	attributes := resource.Attributes.DeepCopy()
+	attributes.Namespace = namespace
+	return userResource{
+		Attributes: *attributes,
+		Verbs:      append([]string{}, resource.Verbs...),
+	}
+}
@@ -37,14 +37,16 @@ API of which this CLI is a client.
 Before running ` + c.Name + `, please install the projectriff system and its dependencies.
 See https://projectriff.io/docs/getting-started/
 
-The application, function and container commands define build plans and the
-credential commands to authenticate builds to container registries.
+The application, function and container commands define build plans, the
+credential commands to authenticate builds to container registries and the build
+commands to configure builds in a namespace.
 
 Runtimes provide ways to execute the workloads. Different runtimes provide
 alternate execution models and capabilities.
 `),
 	}
 
+	cmd.AddCommand(buildcommands.NewBuildCommand(ctx, c))
 	cmd.AddCommand(buildcommands.NewCredentialCommand(ctx, c))
 	cmd.AddCommand(buildcommands.NewApplicationCommand(ctx, c))
 	cmd.AddCommand(buildcommands.NewContainerCommand(ctx, c))
@@ -24,6 +24,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/projectriff/cli/pkg/cli"
 	corecommands "github.com/projectriff/cli/pkg/core/commands"
+	"github.com/projectriff/cli/pkg/k8s"
 	knativecommands "github.com/projectriff/cli/pkg/knative/commands"
 	streamingcommands "github.com/projectriff/cli/pkg/streaming/commands"
 	"github.com/spf13/cobra"
@@ -48,6 +49,17 @@ func NewRootCommand(ctx context.Context, c *cli.Config) *cobra.Command {
 	// add root persistent flags
 	cmd.PersistentFlags().StringVar(&c.ViperConfigFile, cli.StripDash(cli.ConfigFlagName), "", fmt.Sprintf("config `file` (default is $HOME/.%s.yaml)", c.Name))
 	cmd.PersistentFlags().StringVar(&c.KubeConfigFile, cli.StripDash(cli.KubeConfigFlagName), "", "kubectl config `file` (default is $HOME/.kube/config)")
+	cmd.PersistentFlags().StringVar(&c.KubeConfigOverrides.CurrentContext, cli.StripDash(cli.ContextFlagName), "", "kubectl config context `name` to use (default is the current context)")
+	cmd.PersistentFlags().StringVar(&c.KubeConfigOverrides.Context.Cluster, cli.StripDash(cli.ClusterFlagName), "", "kubectl config cluster `name` to use, overrides the context's cluster")
+	cmd.PersistentFlags().StringVar(&c.KubeConfigOverrides.Context.AuthInfo, cli.StripDash(cli.UserFlagName), "", "kubectl config user `name` to use, overrides the context's user")
+	cmd.PersistentFlags().StringVar(&c.KubeConfigOverrides.AuthInfo.Impersonate, cli.StripDash(cli.AsFlagName), "", "`username` to impersonate for requests to the cluster")
+	cmd.PersistentFlags().StringArrayVar(&c.KubeConfigOverrides.AuthInfo.ImpersonateGroups, cli.StripDash(cli.AsGroupFlagName), []string{}, "`group` to impersonate for requests to the cluster, requires "+cli.AsFlagName+" (may be set multiple times)")
+	cmd.PersistentFlags().StringVar(&c.KubeConfigOverrides.Timeout, cli.StripDash(cli.RequestTimeoutFlagName), "", "`duration` to wait for a single request to the cluster before giving up, e.g. 30s (default is no timeout)")
+	cmd.PersistentFlags().Float32Var(&c.QPS, cli.StripDash(cli.QPSFlagName), 0, "maximum `queries` per second to the cluster (default is the client-go default)")
+	cmd.PersistentFlags().IntVar(&c.Burst, cli.StripDash(cli.BurstFlagName), 0, "maximum burst of `queries` above "+cli.QPSFlagName+" (default is the client-go default)")
+	cmd.PersistentFlags().IntVarP(&c.Verbosity, cli.StripDash(cli.VerbosityFlagName), "v", 0, fmt.Sprintf("log `level`, %d traces requests to the cluster, %d adds headers, %d adds bodies, %d adds build and log streaming activity", k8s.TraceRequests, k8s.TraceHeaders, k8s.TraceBodies, cli.DebugVerbosity))
+	cmd.PersistentFlags().StringVar(&c.ErrorFormat, cli.StripDash(cli.ErrorFormatFlagName), cli.TextErrorFormat, fmt.Sprintf("`format` of errors, either %q or %q, json errors are written to stderr", cli.TextErrorFormat, cli.JSONErrorFormat))
+	cli.CompleteFlagValues(cmd, cli.ErrorFormatFlagName, cli.TextErrorFormat, cli.JSONErrorFormat)
 	cmd.PersistentFlags().BoolVar(&color.NoColor, cli.StripDash(cli.NoColorFlagName), color.NoColor, "disable color output in terminals")
 
 	// add runtimes
@@ -77,18 +89,79 @@ zero-to-n autoscaling and managed ingress.
 `),
 	}}
 	for _, runtime := range runtimes {
-		if c.Runtimes[runtime.name] {
-			cmd.Long = cmd.Long + "\n\n" + runtime.doc
-		} else {
-			runtime.command.Hidden = true
-		}
 		cmd.AddCommand(runtime.command)
 	}
 
+	long := cmd.Long
+	trailer := "\n\n" + strings.TrimSpace(fmt.Sprintf(`
+Commands exit with a status code for the class of error:
+- %d: an error that does not fit another class
+- %d: an invalid flag, argument or resource
+- %d: a resource was not found
+- %d: a resource already exists or was changed concurrently
+- %d: permission was denied
+- %d: a request or wait timed out
+- %d: an error within the cluster
+`, cli.ExitCodeError, cli.ExitCodeInvalid, cli.ExitCodeNotFound, cli.ExitCodeConflict, cli.ExitCodeForbidden, cli.ExitCodeTimeout, cli.ExitCodeServerError))
+	trailer += "\n\nCommands that are not built in run the " + c.Name + "-<command> plugin from the PATH,\nsee '" + c.Name + " plugin --help'."
+
+	// show the commands of enabled runtimes, the profile may change the
+	// runtimes after the commands are defined
+	showRuntimes := func() {
+		cmd.Long = long
+		for _, runtime := range runtimes {
+			runtime.command.Hidden = !c.Runtimes[runtime.name]
+			if !runtime.command.Hidden {
+				cmd.Long = cmd.Long + "\n\n" + runtime.doc
+			}
+		}
+		cmd.Long = cmd.Long + trailer
+	}
+	showRuntimes()
+	help := cmd.HelpFunc()
+	cmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		c.LoadConfigFile()
+		showRuntimes()
+		help(cmd, args)
+	})
+
 	// add root-only commands
+	cmd.AddCommand(NewCompleteCommand(ctx, c))
 	cmd.AddCommand(NewCompletionCommand(ctx, c))
+	cmd.AddCommand(NewConfigCommand(ctx, c))
 	cmd.AddCommand(NewDocsCommand(ctx, c))
 	cmd.AddCommand(NewDoctorCommand(ctx, c))
+	cmd.AddCommand(NewNamespaceCommand(ctx, c))
+	cmd.AddCommand(NewPluginCommand(ctx, c))
+	cmd.AddCommand(NewTailCommand(ctx, c))
+
+	// apply runtimes and flag defaults from the active profile, flags on the
+	// command line win
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if c.ErrorFormat != cli.TextErrorFormat && c.ErrorFormat != cli.JSONErrorFormat {
+			return cli.ErrInvalidValue(c.ErrorFormat, cli.ErrorFormatFlagName)
+		}
+		showRuntimes()
+		return cli.ApplyFlagDefaults(cmd, c)
+	}
+
+	// classify flag errors, so they exit as invalid
+	cmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &cli.UsageError{Err: err}
+	})
+
+	// dispatch unknown commands to plugins, flags after the plugin name belong
+	// to the plugin
+	cmd.Args = cobra.ArbitraryArgs
+	cmd.Flags().SetInterspersed(false)
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return cmd.Help()
+		}
+		// the usage is for riff, not the plugin
+		cmd.SilenceUsage = true
+		return execPlugin(ctx, c, cmd, args)
+	}
 
 	// override usage template to add arguments
 	cmd.SetUsageTemplate(strings.ReplaceAll(cmd.UsageTemplate(), "{{.UseLine}}", "{{useLine .}}"))
@@ -17,11 +17,17 @@
 package commands_test
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/projectriff/cli/pkg/cli"
 	"github.com/projectriff/cli/pkg/riff/commands"
 	rifftesting "github.com/projectriff/cli/pkg/testing"
+	"github.com/spf13/viper"
 )
 
 func TestRootCommand(t *testing.T) {
@@ -48,7 +54,242 @@ func TestRootCommand(t *testing.T) {
 				}
 			},
 		},
+		{
+			Name:        "invalid error format",
+			Args:        []string{"plugin", "list", cli.ErrorFormatFlagName, "xml"},
+			ShouldError: true,
+			Verify: func(t *testing.T, output string, err error) {
+				if expected, actual := cli.ExitCodeInvalid, cli.ExitCode(err); expected != actual {
+					t.Errorf("expected exit code %d, actually %d", expected, actual)
+				}
+			},
+		},
+		{
+			Name:        "unknown flag",
+			Args:        []string{"plugin", "list", "--bogus"},
+			ShouldError: true,
+			Verify: func(t *testing.T, output string, err error) {
+				if expected, actual := cli.ExitCodeInvalid, cli.ExitCode(err); expected != actual {
+					t.Errorf("expected exit code %d, actually %d", expected, actual)
+				}
+			},
+		},
+	}
+
+	table.Run(t, commands.NewRootCommand)
+}
+
+func TestRootCommand_ProfileRuntimes(t *testing.T) {
+	file, cleanUpFile := tempConfigFile(t)
+	defer cleanUpFile()
+	prepare, _ := withConfigFile(file, `
+profile: knative
+profiles:
+  knative:
+    runtimes:
+    - knative
+`, "")
+	cleanUp := func(t *testing.T, ctx context.Context, c *cli.Config) error {
+		viper.Reset()
+		return nil
+	}
+
+	table := rifftesting.CommandTable{
+		{
+			Name:    "help",
+			Args:    []string{cli.ConfigFlagName, file, "--help"},
+			Prepare: prepare,
+			CleanUp: cleanUp,
+			Verify: func(t *testing.T, output string, err error) {
+				if !strings.Contains(output, "\n  knative ") {
+					t.Errorf("expected knative command to be shown by the profile, actually %q", output)
+				}
+				if strings.Contains(output, "\n  core ") {
+					t.Errorf("expected core command to be hidden by the profile, actually %q", output)
+				}
+			},
+		},
+		{
+			Name:    "help command",
+			Args:    []string{cli.ConfigFlagName, file, "help"},
+			Prepare: prepare,
+			CleanUp: cleanUp,
+			Verify: func(t *testing.T, output string, err error) {
+				if !strings.Contains(output, "\n  knative ") {
+					t.Errorf("expected knative command to be shown by the profile, actually %q", output)
+				}
+				if strings.Contains(output, "\n  core ") {
+					t.Errorf("expected core command to be hidden by the profile, actually %q", output)
+				}
+			},
+		},
 	}
 
 	table.Run(t, commands.NewRootCommand)
 }
+
+func TestRootCommand_Plugins(t *testing.T) {
+	// plugins are only found in absolute directories
+	first, _ := filepath.Abs(filepath.Join("testdata", "plugins", "first"))
+	second, _ := filepath.Abs(filepath.Join("testdata", "plugins", "second"))
+	preparePlugins, cleanUpPlugins := withPath(first, second)
+
+	table := rifftesting.CommandTable{
+		{
+			Name:       "plugin",
+			Args:       []string{"--kube-config", "/home/me/.kube/config", "hello", "world", "--loud"},
+			Prepare:    preparePlugins,
+			CleanUp:    cleanUpPlugins,
+			ExecHelper: "Plugin",
+			ExpectOutput: `
+riff-hello [world --loud]
+KUBECONFIG=/home/me/.kube/config
+RIFF_CONTEXT=
+RIFF_CLUSTER=
+RIFF_USER=
+RIFF_AS=
+RIFF_AS_GROUP=
+RIFF_REQUEST_TIMEOUT=
+RIFF_NAMESPACE=default
+RIFF_NO_COLOR=true
+`,
+		},
+		{
+			Name:       "plugin with multiple words",
+			Args:       []string{"--kube-config", "/home/me/.kube/config", "cost", "report", "--month", "june"},
+			Prepare:    preparePlugins,
+			CleanUp:    cleanUpPlugins,
+			ExecHelper: "Plugin",
+			ExpectOutput: `
+riff-cost-report [--month june]
+KUBECONFIG=/home/me/.kube/config
+RIFF_CONTEXT=
+RIFF_CLUSTER=
+RIFF_USER=
+RIFF_AS=
+RIFF_AS_GROUP=
+RIFF_REQUEST_TIMEOUT=
+RIFF_NAMESPACE=default
+RIFF_NO_COLOR=true
+`,
+		},
+		{
+			Name:       "plugin with context",
+			Args:       []string{"--kube-config", "/home/me/.kube/config", "--context", "prod", "hello"},
+			Prepare:    preparePlugins,
+			CleanUp:    cleanUpPlugins,
+			ExecHelper: "Plugin",
+			ExpectOutput: `
+riff-hello []
+KUBECONFIG=/home/me/.kube/config
+RIFF_CONTEXT=prod
+RIFF_CLUSTER=
+RIFF_USER=
+RIFF_AS=
+RIFF_AS_GROUP=
+RIFF_REQUEST_TIMEOUT=
+RIFF_NAMESPACE=default
+RIFF_NO_COLOR=true
+`,
+		},
+		{
+			Name: "plugin with overrides",
+			Args: []string{
+				"--kube-config", "/home/me/.kube/config",
+				"--cluster", "prod-cluster", "--user", "admin",
+				"--as", "jane", "--as-group", "developers", "--as-group", "testers",
+				"--request-timeout", "30s",
+				"hello",
+			},
+			Prepare:    preparePlugins,
+			CleanUp:    cleanUpPlugins,
+			ExecHelper: "Plugin",
+			ExpectOutput: `
+riff-hello []
+KUBECONFIG=/home/me/.kube/config
+RIFF_CONTEXT=
+RIFF_CLUSTER=prod-cluster
+RIFF_USER=admin
+RIFF_AS=jane
+RIFF_AS_GROUP=developers,testers
+RIFF_REQUEST_TIMEOUT=30s
+RIFF_NAMESPACE=default
+RIFF_NO_COLOR=true
+`,
+		},
+		{
+			Name: "plugin with invalid kube config",
+			Args: []string{"--kube-config", "/home/me/.kube/config", "hello"},
+			Prepare: func(t *testing.T, ctx context.Context, c *cli.Config) (context.Context, error) {
+				c.Client.(*rifftesting.FakeClient).NamespaceError = fmt.Errorf("invalid configuration")
+				return preparePlugins(t, ctx, c)
+			},
+			CleanUp:    cleanUpPlugins,
+			ExecHelper: "Plugin",
+			ExpectOutput: `
+Unable to resolve the default namespace for plugin "riff-hello": invalid configuration
+riff-hello []
+KUBECONFIG=/home/me/.kube/config
+RIFF_CONTEXT=
+RIFF_CLUSTER=
+RIFF_USER=
+RIFF_AS=
+RIFF_AS_GROUP=
+RIFF_REQUEST_TIMEOUT=
+RIFF_NAMESPACE=
+RIFF_NO_COLOR=true
+`,
+		},
+		{
+			Name:        "plugin failure",
+			Args:        []string{"hello"},
+			Prepare:     preparePlugins,
+			CleanUp:     cleanUpPlugins,
+			ExecHelper:  "PluginFailure",
+			ShouldError: true,
+			Verify: func(t *testing.T, output string, err error) {
+				if !cli.IsSilent(err) {
+					t.Errorf("expected plugin error to be silent, actually %v", err)
+				}
+			},
+		},
+		{
+			Name:        "unknown command",
+			Args:        []string{"functoin"},
+			Prepare:     preparePlugins,
+			CleanUp:     cleanUpPlugins,
+			ShouldError: true,
+			Verify: func(t *testing.T, output string, err error) {
+				expected := "unknown command \"functoin\" for \"riff\"\n\nDid you mean this?\n\tfunction\n"
+				if err == nil || err.Error() != expected {
+					t.Errorf("expected error %q, actually %v", expected, err)
+				}
+			},
+		},
+	}
+
+	table.Run(t, commands.NewRootCommand)
+}
+
+func TestHelperProcess_Plugin(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	fmt.Printf("%s %v\n", filepath.Base(args[1]), args[2:])
+	for _, env := range []string{"KUBECONFIG", "RIFF_CONTEXT", "RIFF_CLUSTER", "RIFF_USER", "RIFF_AS", "RIFF_AS_GROUP", "RIFF_REQUEST_TIMEOUT", "RIFF_NAMESPACE", "RIFF_NO_COLOR"} {
+		fmt.Printf("%s=%s\n", env, os.Getenv(env))
+	}
+	os.Exit(0)
+}
+
+func TestHelperProcess_PluginFailure(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "something went wrong\n")
+	os.Exit(3)
+}
@@ -0,0 +1,260 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/projectriff/cli/pkg/cli"
+	"github.com/projectriff/cli/pkg/validation"
+	"github.com/projectriff/system/pkg/apis/build"
+	"github.com/projectriff/system/pkg/apis/core"
+	"github.com/projectriff/system/pkg/apis/knative"
+	"github.com/projectriff/system/pkg/apis/streaming"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	ResourcesArgumentName = "kind/name(s)"
+)
+
+// tailKind describes a kind of resource whose logs can be tailed
+type tailKind struct {
+	name     string
+	labelKey string
+	get      func(c *cli.Config, namespace, name string) error
+}
+
+var tailKinds = []tailKind{
+	{
+		name:     "application",
+		labelKey: build.ApplicationLabelKey,
+		get: func(c *cli.Config, namespace, name string) error {
+			_, err := c.Build().Applications(namespace).Get(name, metav1.GetOptions{})
+			return err
+		},
+	},
+	{
+		name:     "function",
+		labelKey: build.FunctionLabelKey,
+		get: func(c *cli.Config, namespace, name string) error {
+			_, err := c.Build().Functions(namespace).Get(name, metav1.GetOptions{})
+			return err
+		},
+	},
+	{
+		name:     "core-deployer",
+		labelKey: core.DeployerLabelKey,
+		get: func(c *cli.Config, namespace, name string) error {
+			_, err := c.CoreRuntime().Deployers(namespace).Get(name, metav1.GetOptions{})
+			return err
+		},
+	},
+	{
+		name:     "knative-deployer",
+		labelKey: knative.DeployerLabelKey,
+		get: func(c *cli.Config, namespace, name string) error {
+			_, err := c.KnativeRuntime().Deployers(namespace).Get(name, metav1.GetOptions{})
+			return err
+		},
+	},
+	{
+		name:     "processor",
+		labelKey: streaming.ProcessorLabelKey,
+		get: func(c *cli.Config, namespace, name string) error {
+			_, err := c.StreamingRuntime().Processors(namespace).Get(name, metav1.GetOptions{})
+			return err
+		},
+	},
+}
+
+func findTailKind(name string) (tailKind, bool) {
+	for _, kind := range tailKinds {
+		if kind.name == name {
+			return kind, true
+		}
+	}
+	return tailKind{}, false
+}
+
+func tailKindNames() []string {
+	names := make([]string, len(tailKinds))
+	for i, kind := range tailKinds {
+		names[i] = kind.name
+	}
+	return names
+}
+
+// parseResource splits a kind/name resource reference. The kind is only valid if the second
+// return value is true.
+func parseResource(resource string) (tailKind, string, bool) {
+	parts := strings.SplitN(resource, "/", 2)
+	if len(parts) != 2 {
+		return tailKind{}, "", false
+	}
+	kind, ok := findTailKind(parts[0])
+	return kind, parts[1], ok
+}
+
+type TailOptions struct {
+	Namespace string
+	Resources []string
+	Selector  string
+	All       bool
+
+	cli.TailOptions
+}
+
+var (
+	_ cli.Validatable = (*TailOptions)(nil)
+	_ cli.Executable  = (*TailOptions)(nil)
+)
+
+func (opts *TailOptions) Validate(ctx context.Context) *cli.FieldError {
+	errs := cli.EmptyFieldError
+
+	if opts.Namespace == "" {
+		errs = errs.Also(cli.ErrMissingField(cli.NamespaceFlagName))
+	}
+
+	sources := 0
+	if len(opts.Resources) != 0 {
+		sources++
+	}
+	if opts.Selector != "" {
+		sources++
+	}
+	if opts.All {
+		sources++
+	}
+	if sources == 0 {
+		errs = errs.Also(cli.ErrMissingOneOf(ResourcesArgumentName, cli.SelectorFlagName, cli.AllFlagName))
+	} else if sources > 1 {
+		errs = errs.Also(cli.ErrMultipleOneOf(ResourcesArgumentName, cli.SelectorFlagName, cli.AllFlagName))
+	}
+
+	for i, resource := range opts.Resources {
+		if _, name, ok := parseResource(resource); !ok || validation.K8sName(name, cli.CurrentField).Error() != "" {
+			errs = errs.Also(cli.ErrInvalidArrayValue(resource, ResourcesArgumentName, i))
+		}
+	}
+
+	if opts.Selector != "" {
+		if _, err := labels.Parse(opts.Selector); err != nil {
+			errs = errs.Also(cli.ErrInvalidValue(opts.Selector, cli.SelectorFlagName))
+		}
+	}
+
+	errs = errs.Also(opts.TailOptions.Validate(ctx))
+
+	return errs
+}
+
+func (opts *TailOptions) Exec(ctx context.Context, c *cli.Config) error {
+	selectors := []labels.Selector{}
+	switch {
+	case opts.Selector != "":
+		// error is protected by Validate()
+		selector, _ := labels.Parse(opts.Selector)
+		selectors = append(selectors, selector)
+	case len(opts.Resources) != 0:
+		// combine resources of the same kind into a single selector
+		names := map[string]sets.String{}
+		for _, resource := range opts.Resources {
+			// error is protected by Validate()
+			kind, name, _ := parseResource(resource)
+			if err := kind.get(c, opts.Namespace, name); err != nil {
+				return err
+			}
+			if _, ok := names[kind.name]; !ok {
+				names[kind.name] = sets.NewString()
+			}
+			names[kind.name].Insert(name)
+		}
+		for _, kind := range tailKinds {
+			if _, ok := names[kind.name]; !ok {
+				continue
+			}
+			requirement, err := labels.NewRequirement(kind.labelKey, selection.In, names[kind.name].List())
+			if err != nil {
+				return err
+			}
+			selectors = append(selectors, labels.NewSelector().Add(*requirement))
+		}
+	}
+	return c.Kail.Logs(ctx, opts.Namespace, selectors, opts.TailOptions.LogOptions(), c.Stdout)
+}
+
+func NewTailCommand(ctx context.Context, c *cli.Config) *cobra.Command {
+	opts := &TailOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "tail",
+		Short: "watch logs from many resources",
+		Long: strings.TrimSpace(`
+Stream runtime logs for several resources at once until canceled. To cancel,
+press Ctl-c in the shell or kill the process.
+
+Resources are referenced as ` + "`kind/name`" + `, where kind is one of:
+` + strings.Join(tailKindNames(), ", ") + `
+
+Alternatively, select pods with a label selector using ` + cli.SelectorFlagName + `, or all pods
+in the namespace with ` + cli.AllFlagName + `.
+
+Logs from every container of the matching pods are interleaved into a single
+stream, with each pod printed in a different color. To show historical logs use
+` + cli.SinceFlagName + `.
+
+Use ` + cli.NoFollowFlagName + ` to read existing logs and exit, or ` + cli.PreviousFlagName + ` to read
+the logs of containers from before they last restarted.
+`),
+		Example: strings.Join([]string{
+			fmt.Sprintf("%s tail processor/my-processor processor/my-other-processor", c.Name),
+			fmt.Sprintf("%s tail function/my-function core-deployer/my-deployer", c.Name),
+			fmt.Sprintf("%s tail -l app=my-app", c.Name),
+			fmt.Sprintf("%s tail %s %s 1h", c.Name, cli.AllFlagName, cli.SinceFlagName),
+			fmt.Sprintf("%s tail processor/my-processor %s", c.Name, cli.PreviousFlagName),
+		}, "\n"),
+		PreRunE: cli.ValidateOptions(ctx, opts),
+		RunE:    cli.ExecOptions(ctx, c, opts),
+	}
+
+	cli.Args(cmd,
+		cli.Arg{
+			Name:     ResourcesArgumentName,
+			Arity:    -1,
+			Optional: true,
+			Set: func(cmd *cobra.Command, args []string, offset int) error {
+				opts.Resources = args[offset:]
+				return nil
+			},
+		},
+	)
+
+	cli.NamespaceFlag(cmd, c, &opts.Namespace)
+	cmd.Flags().StringVarP(&opts.Selector, cli.StripDash(cli.SelectorFlagName), "l", "", "label `selector` for pods to read logs from")
+	cmd.Flags().BoolVar(&opts.All, cli.StripDash(cli.AllFlagName), false, "read logs from all pods in the namespace")
+	cli.TailFlags(cmd, &opts.TailOptions)
+
+	return cmd
+}
@@ -0,0 +1,256 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/projectriff/cli/pkg/cli"
+	riffkail "github.com/projectriff/cli/pkg/kail"
+	"github.com/projectriff/cli/pkg/riff/commands"
+	rifftesting "github.com/projectriff/cli/pkg/testing"
+	kailtesting "github.com/projectriff/cli/pkg/testing/kail"
+	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+	streamv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	"github.com/stretchr/testify/mock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestTailOptions(t *testing.T) {
+	table := rifftesting.OptionsTable{
+		{
+			Name: "resources",
+			Options: &commands.TailOptions{
+				Namespace: "default",
+				Resources: []string{"processor/my-processor", "core-deployer/my-deployer"},
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "selector",
+			Options: &commands.TailOptions{
+				Namespace: "default",
+				Selector:  "app=my-app",
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "all",
+			Options: &commands.TailOptions{
+				Namespace: "default",
+				All:       true,
+			},
+			ShouldValidate: true,
+		},
+		{
+			Name: "missing namespace",
+			Options: &commands.TailOptions{
+				All: true,
+			},
+			ExpectFieldError: cli.ErrMissingField(cli.NamespaceFlagName),
+		},
+		{
+			Name: "missing source",
+			Options: &commands.TailOptions{
+				Namespace: "default",
+			},
+			ExpectFieldError: cli.ErrMissingOneOf(commands.ResourcesArgumentName, cli.SelectorFlagName, cli.AllFlagName),
+		},
+		{
+			Name: "multiple sources",
+			Options: &commands.TailOptions{
+				Namespace: "default",
+				Resources: []string{"processor/my-processor"},
+				Selector:  "app=my-app",
+				All:       true,
+			},
+			ExpectFieldError: cli.ErrMultipleOneOf(commands.ResourcesArgumentName, cli.SelectorFlagName, cli.AllFlagName),
+		},
+		{
+			Name: "invalid resources",
+			Options: &commands.TailOptions{
+				Namespace: "default",
+				Resources: []string{"my-processor", "widget/my-widget", "processor/my.processor", "processor/"},
+			},
+			ExpectFieldError: cli.EmptyFieldError.Also(
+				cli.ErrInvalidArrayValue("my-processor", commands.ResourcesArgumentName, 0),
+				cli.ErrInvalidArrayValue("widget/my-widget", commands.ResourcesArgumentName, 1),
+				cli.ErrInvalidArrayValue("processor/my.processor", commands.ResourcesArgumentName, 2),
+				cli.ErrInvalidArrayValue("processor/", commands.ResourcesArgumentName, 3),
+			),
+		},
+		{
+			Name: "invalid selector",
+			Options: &commands.TailOptions{
+				Namespace: "default",
+				Selector:  "app=(",
+			},
+			ExpectFieldError: cli.ErrInvalidValue("app=(", cli.SelectorFlagName),
+		},
+		{
+			Name: "invalid tail options",
+			Options: &commands.TailOptions{
+				Namespace: "default",
+				All:       true,
+				TailOptions: cli.TailOptions{
+					Since: "1",
+				},
+			},
+			ExpectFieldError: cli.ErrInvalidValue("1", cli.SinceFlagName),
+		},
+	}
+
+	table.Run(t)
+}
+
+func TestTailCommand(t *testing.T) {
+	defaultNamespace := "default"
+	function := &buildv1alpha1.Function{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: defaultNamespace,
+			Name:      "my-function",
+		},
+	}
+	processor := &streamv1alpha1.Processor{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: defaultNamespace,
+			Name:      "my-processor",
+		},
+	}
+	otherProcessor := &streamv1alpha1.Processor{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: defaultNamespace,
+			Name:      "my-other-processor",
+		},
+	}
+
+	parseSelectors := func(selectors ...string) []labels.Selector {
+		parsed := make([]labels.Selector, len(selectors))
+		for i, selector := range selectors {
+			parsed[i], _ = labels.Parse(selector)
+		}
+		return parsed
+	}
+
+	table := rifftesting.CommandTable{
+		{
+			Name:        "missing source",
+			Args:        []string{},
+			ShouldError: true,
+		},
+		{
+			Name: "show logs for resources",
+			Args: []string{"processor/my-processor", "function/my-function", "processor/my-other-processor"},
+			Prepare: func(t *testing.T, ctx context.Context, c *cli.Config) (context.Context, error) {
+				kail := &kailtesting.Logger{}
+				c.Kail = kail
+				selectors := parseSelectors(
+					"build.projectriff.io/function in (my-function)",
+					"streaming.projectriff.io/processor in (my-other-processor,my-processor)",
+				)
+				kail.On("Logs", mock.Anything, defaultNamespace, selectors, riffkail.LogOptions{Since: cli.TailSinceDefault, Containers: []string{}}, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+					fmt.Fprintf(c.Stdout, "...log output...\n")
+				})
+				return ctx, nil
+			},
+			CleanUp: func(t *testing.T, ctx context.Context, c *cli.Config) error {
+				kail := c.Kail.(*kailtesting.Logger)
+				kail.AssertExpectations(t)
+				return nil
+			},
+			GivenObjects: []runtime.Object{
+				function,
+				processor,
+				otherProcessor,
+			},
+			ExpectOutput: `
+...log output...
+`,
+		},
+		{
+			Name: "show logs for selector",
+			Args: []string{"-l", "app=my-app", cli.SinceFlagName, "1h"},
+			Prepare: func(t *testing.T, ctx context.Context, c *cli.Config) (context.Context, error) {
+				kail := &kailtesting.Logger{}
+				c.Kail = kail
+				kail.On("Logs", mock.Anything, defaultNamespace, parseSelectors("app=my-app"), riffkail.LogOptions{Since: time.Hour, Containers: []string{}}, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+					fmt.Fprintf(c.Stdout, "...log output...\n")
+				})
+				return ctx, nil
+			},
+			CleanUp: func(t *testing.T, ctx context.Context, c *cli.Config) error {
+				kail := c.Kail.(*kailtesting.Logger)
+				kail.AssertExpectations(t)
+				return nil
+			},
+			ExpectOutput: `
+...log output...
+`,
+		},
+		{
+			Name: "show logs for namespace",
+			Args: []string{cli.AllFlagName, cli.NamespaceFlagName, "my-namespace"},
+			Prepare: func(t *testing.T, ctx context.Context, c *cli.Config) (context.Context, error) {
+				kail := &kailtesting.Logger{}
+				c.Kail = kail
+				kail.On("Logs", mock.Anything, "my-namespace", []labels.Selector{}, riffkail.LogOptions{Since: cli.TailSinceDefault, Containers: []string{}}, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+					fmt.Fprintf(c.Stdout, "...log output...\n")
+				})
+				return ctx, nil
+			},
+			CleanUp: func(t *testing.T, ctx context.Context, c *cli.Config) error {
+				kail := c.Kail.(*kailtesting.Logger)
+				kail.AssertExpectations(t)
+				return nil
+			},
+			ExpectOutput: `
+...log output...
+`,
+		},
+		{
+			Name: "unknown resource",
+			Args: []string{"processor/my-processor", "function/my-function"},
+			GivenObjects: []runtime.Object{
+				processor,
+			},
+			ShouldError: true,
+		},
+		{
+			Name: "kail error",
+			Args: []string{cli.AllFlagName},
+			Prepare: func(t *testing.T, ctx context.Context, c *cli.Config) (context.Context, error) {
+				kail := &kailtesting.Logger{}
+				c.Kail = kail
+				kail.On("Logs", mock.Anything, defaultNamespace, []labels.Selector{}, riffkail.LogOptions{Since: cli.TailSinceDefault, Containers: []string{}}, mock.Anything).Return(fmt.Errorf("kail error"))
+				return ctx, nil
+			},
+			CleanUp: func(t *testing.T, ctx context.Context, c *cli.Config) error {
+				kail := c.Kail.(*kailtesting.Logger)
+				kail.AssertExpectations(t)
+				return nil
+			},
+			ShouldError: true,
+		},
+	}
+
+	table.Run(t, commands.NewTailCommand)
+}
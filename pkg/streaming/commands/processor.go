@@ -34,6 +34,8 @@ func NewProcessorCommand(ctx context.Context, c *cli.Config) *cobra.Command {
 		Aliases: []string{"processors"},
 	}
 
+	cli.CompleteNameArgs(cmd, cli.ProcessorsCompletion)
+
 	cmd.AddCommand(NewProcessorListCommand(ctx, c))
 	cmd.AddCommand(NewProcessorCreateCommand(ctx, c))
 	cmd.AddCommand(NewProcessorDeleteCommand(ctx, c))
@@ -24,10 +24,12 @@ import (
 
 	"github.com/projectriff/cli/pkg/cli"
 	"github.com/projectriff/cli/pkg/k8s"
+	"github.com/projectriff/cli/pkg/kail"
 	"github.com/projectriff/cli/pkg/race"
 	streamv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 type ProcessorCreateOptions struct {
@@ -47,6 +49,7 @@ var (
 	_ cli.Validatable = (*ProcessorCreateOptions)(nil)
 	_ cli.Executable  = (*ProcessorCreateOptions)(nil)
 	_ cli.DryRunable  = (*ProcessorCreateOptions)(nil)
+	_ cli.Promptable  = (*ProcessorCreateOptions)(nil)
 )
 
 func (opts *ProcessorCreateOptions) Validate(ctx context.Context) *cli.FieldError {
@@ -91,7 +94,15 @@ func (opts *ProcessorCreateOptions) Exec(ctx context.Context, c *cli.Config) err
 	}
 
 	if opts.DryRun {
-		cli.DryRunResource(ctx, processor, processor.GetGroupVersionKind())
+		get := func() (runtime.Object, error) {
+			return c.StreamingRuntime().Processors(opts.Namespace).Get(processor.Name, metav1.GetOptions{})
+		}
+		create := func() (runtime.Object, error) {
+			return k8s.DryRunCreate(c.StreamingRuntime().RESTClient(), "processors", processor)
+		}
+		if err := cli.DryRunCreate(ctx, c, processor, processor.GetGroupVersionKind(), get, create); err != nil {
+			return err
+		}
 	} else {
 		var err error
 		processor, err = c.StreamingRuntime().Processors(opts.Namespace).Create(processor)
@@ -108,7 +119,7 @@ func (opts *ProcessorCreateOptions) Exec(ctx context.Context, c *cli.Config) err
 				return k8s.WaitUntilReady(ctx, c.StreamingRuntime().RESTClient(), "processors", processor)
 			},
 			func(ctx context.Context) error {
-				return c.Kail.StreamingProcessorLogs(ctx, processor, cli.TailSinceCreateDefault, c.Stdout)
+				return c.Kail.StreamingProcessorLogs(ctx, processor, kail.LogOptions{Since: cli.TailSinceCreateDefault}, c.Stdout)
 			},
 		)
 		if err == context.DeadlineExceeded {
@@ -124,6 +135,16 @@ func (opts *ProcessorCreateOptions) Exec(ctx context.Context, c *cli.Config) err
 	return nil
 }
 
+func (opts *ProcessorCreateOptions) Prompt(ctx context.Context, c *cli.Config, p *cli.Prompter) error {
+	if err := p.Required(cli.FunctionRefFlagName); err != nil {
+		return err
+	}
+	if err := p.Required(cli.InputFlagName); err != nil {
+		return err
+	}
+	return p.Optional(cli.OutputFlagName)
+}
+
 func (opts *ProcessorCreateOptions) IsDryRun() bool {
 	return opts.DryRun
 }
@@ -149,13 +170,18 @@ func NewProcessorCreateCommand(ctx context.Context, c *cli.Config) *cobra.Comman
 		cli.NameArg(&opts.Name),
 	)
 
+	cli.InteractiveFlag(ctx, cmd, c, opts)
 	cli.NamespaceFlag(cmd, c, &opts.Namespace)
 	cmd.Flags().StringVar(&opts.FunctionRef, cli.StripDash(cli.FunctionRefFlagName), "", "`name` of function build to deploy")
 	cmd.Flags().StringArrayVar(&opts.Inputs, cli.StripDash(cli.InputFlagName), []string{}, "`name` of stream to read messages from (may be set multiple times)")
 	cmd.Flags().StringArrayVar(&opts.Outputs, cli.StripDash(cli.OutputFlagName), []string{}, "`name` of stream to write messages to (may be set multiple times)")
 	cmd.Flags().BoolVar(&opts.Tail, cli.StripDash(cli.TailFlagName), false, "watch processor logs")
 	cmd.Flags().StringVar(&opts.WaitTimeout, cli.StripDash(cli.WaitTimeoutFlagName), "10m", "`duration` to wait for the processor to become ready when watching logs")
-	cmd.Flags().BoolVar(&opts.DryRun, cli.StripDash(cli.DryRunFlagName), false, "print kubernetes resources to stdout rather than apply them to the cluster, messages normally on stdout will be sent to stderr")
+	cli.DryRunFlags(cmd, c, &opts.DryRun)
+
+	cli.CompleteFlagResource(cmd, cli.FunctionRefFlagName, cli.FunctionsCompletion)
+	cli.CompleteFlagResource(cmd, cli.InputFlagName, cli.StreamsCompletion)
+	cli.CompleteFlagResource(cmd, cli.OutputFlagName, cli.StreamsCompletion)
 
 	return cmd
 }
@@ -20,7 +20,6 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/projectriff/cli/pkg/cli"
 	"github.com/spf13/cobra"
@@ -30,7 +29,7 @@ import (
 type ProcessorTailOptions struct {
 	cli.ResourceOptions
 
-	Since string
+	cli.TailOptions
 }
 
 var (
@@ -43,11 +42,7 @@ func (opts *ProcessorTailOptions) Validate(ctx context.Context) *cli.FieldError
 
 	errs = errs.Also(opts.ResourceOptions.Validate(ctx))
 
-	if opts.Since != "" {
-		if _, err := time.ParseDuration(opts.Since); err != nil {
-			errs = errs.Also(cli.ErrInvalidValue(opts.Since, cli.SinceFlagName))
-		}
-	}
+	errs = errs.Also(opts.TailOptions.Validate(ctx))
 
 	return errs
 }
@@ -57,12 +52,7 @@ func (opts *ProcessorTailOptions) Exec(ctx context.Context, c *cli.Config) error
 	if err != nil {
 		return err
 	}
-	since := cli.TailSinceDefault
-	if opts.Since != "" {
-		// error is protected by Validate()
-		since, _ = time.ParseDuration(opts.Since)
-	}
-	return c.Kail.StreamingProcessorLogs(ctx, processor, since, c.Stdout)
+	return c.Kail.StreamingProcessorLogs(ctx, processor, opts.TailOptions.LogOptions(), c.Stdout)
 }
 
 func NewProcessorTailCommand(ctx context.Context, c *cli.Config) *cobra.Command {
@@ -77,10 +67,17 @@ the shell or kill the process.
 
 As new processor pods are started, the logs are displayed. To show historical
 logs use ` + cli.SinceFlagName + `.
+
+Log lines are written for people to read by default. Use ` + cli.OutputFlagName + ` json to
+write one JSON object per line instead, for processing by other tools.
+
+Use ` + cli.NoFollowFlagName + ` to read existing logs and exit, or ` + cli.PreviousFlagName + ` to read
+the logs of containers from before they last restarted.
 `),
 		Example: strings.Join([]string{
 			fmt.Sprintf("%s processor tail my-processor", c.Name),
 			fmt.Sprintf("%s processor tail my-processor %s 1h", c.Name, cli.SinceFlagName),
+			fmt.Sprintf("%s processor tail my-processor %s json %s error", c.Name, cli.OutputFlagName, cli.GrepFlagName),
 		}, "\n"),
 		PreRunE: cli.ValidateOptions(ctx, opts),
 		RunE:    cli.ExecOptions(ctx, c, opts),
@@ -91,7 +88,7 @@ logs use ` + cli.SinceFlagName + `.
 	)
 
 	cli.NamespaceFlag(cmd, c, &opts.Namespace)
-	cmd.Flags().StringVar(&opts.Since, cli.StripDash(cli.SinceFlagName), "", "time `duration` to start reading logs from")
+	cli.TailFlags(cmd, &opts.TailOptions)
 	cmd.Flag(cli.StripDash(cli.SinceFlagName)).Hidden = true
 
 	return cmd
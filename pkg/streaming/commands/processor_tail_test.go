@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/projectriff/cli/pkg/cli"
+	riffkail "github.com/projectriff/cli/pkg/kail"
 	"github.com/projectriff/cli/pkg/streaming/commands"
 	rifftesting "github.com/projectriff/cli/pkg/testing"
 	kailtesting "github.com/projectriff/cli/pkg/testing/kail"
@@ -52,7 +53,9 @@ func TestProcessorTailOptions(t *testing.T) {
 			Name: "since duration",
 			Options: &commands.ProcessorTailOptions{
 				ResourceOptions: rifftesting.ValidResourceOptions,
-				Since:           "1m",
+				TailOptions: cli.TailOptions{
+					Since: "1m",
+				},
 			},
 			ShouldValidate: true,
 		},
@@ -60,7 +63,9 @@ func TestProcessorTailOptions(t *testing.T) {
 			Name: "invalid duration",
 			Options: &commands.ProcessorTailOptions{
 				ResourceOptions: rifftesting.ValidResourceOptions,
-				Since:           "1",
+				TailOptions: cli.TailOptions{
+					Since: "1",
+				},
 			},
 			ExpectFieldError: cli.ErrInvalidValue("1", cli.SinceFlagName),
 		},
@@ -91,7 +96,7 @@ func TestProcessorTailCommand(t *testing.T) {
 			Prepare: func(t *testing.T, ctx context.Context, c *cli.Config) (context.Context, error) {
 				kail := &kailtesting.Logger{}
 				c.Kail = kail
-				kail.On("StreamingProcessorLogs", mock.Anything, processor, cli.TailSinceDefault, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				kail.On("StreamingProcessorLogs", mock.Anything, processor, riffkail.LogOptions{Since: cli.TailSinceDefault, Containers: []string{}}, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
 					fmt.Fprintf(c.Stdout, "...log output...\n")
 				})
 				return ctx, nil
@@ -114,7 +119,7 @@ func TestProcessorTailCommand(t *testing.T) {
 			Prepare: func(t *testing.T, ctx context.Context, c *cli.Config) (context.Context, error) {
 				kail := &kailtesting.Logger{}
 				c.Kail = kail
-				kail.On("StreamingProcessorLogs", mock.Anything, processor, time.Hour, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				kail.On("StreamingProcessorLogs", mock.Anything, processor, riffkail.LogOptions{Since: time.Hour, Containers: []string{}}, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
 					fmt.Fprintf(c.Stdout, "...log output...\n")
 				})
 				return ctx, nil
@@ -142,7 +147,7 @@ func TestProcessorTailCommand(t *testing.T) {
 			Prepare: func(t *testing.T, ctx context.Context, c *cli.Config) (context.Context, error) {
 				kail := &kailtesting.Logger{}
 				c.Kail = kail
-				kail.On("StreamingProcessorLogs", mock.Anything, processor, cli.TailSinceDefault, mock.Anything).Return(fmt.Errorf("kail error"))
+				kail.On("StreamingProcessorLogs", mock.Anything, processor, riffkail.LogOptions{Since: cli.TailSinceDefault, Containers: []string{}}, mock.Anything).Return(fmt.Errorf("kail error"))
 				return ctx, nil
 			},
 			CleanUp: func(t *testing.T, ctx context.Context, c *cli.Config) error {
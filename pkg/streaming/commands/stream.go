@@ -34,6 +34,8 @@ func NewStreamCommand(ctx context.Context, c *cli.Config) *cobra.Command {
 		Aliases: []string{"streams"},
 	}
 
+	cli.CompleteNameArgs(cmd, cli.StreamsCompletion)
+
 	cmd.AddCommand(NewStreamListCommand(ctx, c))
 	cmd.AddCommand(NewStreamCreateCommand(ctx, c))
 	cmd.AddCommand(NewStreamDeleteCommand(ctx, c))
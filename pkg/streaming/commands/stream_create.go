@@ -22,10 +22,12 @@ import (
 	"strings"
 
 	"github.com/projectriff/cli/pkg/cli"
+	"github.com/projectriff/cli/pkg/k8s"
 	"github.com/projectriff/cli/pkg/validation"
 	streamv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 type StreamCreateOptions struct {
@@ -41,6 +43,7 @@ var (
 	_ cli.Validatable = (*StreamCreateOptions)(nil)
 	_ cli.Executable  = (*StreamCreateOptions)(nil)
 	_ cli.DryRunable  = (*StreamCreateOptions)(nil)
+	_ cli.Promptable  = (*StreamCreateOptions)(nil)
 )
 
 func (opts *StreamCreateOptions) Validate(ctx context.Context) *cli.FieldError {
@@ -73,7 +76,15 @@ func (opts *StreamCreateOptions) Exec(ctx context.Context, c *cli.Config) error
 	}
 
 	if opts.DryRun {
-		cli.DryRunResource(ctx, stream, stream.GetGroupVersionKind())
+		get := func() (runtime.Object, error) {
+			return c.StreamingRuntime().Streams(opts.Namespace).Get(stream.Name, metav1.GetOptions{})
+		}
+		create := func() (runtime.Object, error) {
+			return k8s.DryRunCreate(c.StreamingRuntime().RESTClient(), "streams", stream)
+		}
+		if err := cli.DryRunCreate(ctx, c, stream, stream.GetGroupVersionKind(), get, create); err != nil {
+			return err
+		}
 	} else {
 		var err error
 		stream, err = c.StreamingRuntime().Streams(opts.Namespace).Create(stream)
@@ -85,6 +96,13 @@ func (opts *StreamCreateOptions) Exec(ctx context.Context, c *cli.Config) error
 	return nil
 }
 
+func (opts *StreamCreateOptions) Prompt(ctx context.Context, c *cli.Config, p *cli.Prompter) error {
+	if err := p.Required(cli.ProviderFlagName); err != nil {
+		return err
+	}
+	return p.Optional(cli.ContentTypeFlagName)
+}
+
 func (opts *StreamCreateOptions) IsDryRun() bool {
 	return opts.DryRun
 }
@@ -107,10 +125,11 @@ func NewStreamCreateCommand(ctx context.Context, c *cli.Config) *cobra.Command {
 		cli.NameArg(&opts.Name),
 	)
 
+	cli.InteractiveFlag(ctx, cmd, c, opts)
 	cli.NamespaceFlag(cmd, c, &opts.Namespace)
 	cmd.Flags().StringVar(&opts.Provider, cli.StripDash(cli.ProviderFlagName), "", "`name` of stream provider")
 	cmd.Flags().StringVar(&opts.ContentType, cli.StripDash(cli.ContentTypeFlagName), "", "`MIME type` for message payloads accepted by the stream")
-	cmd.Flags().BoolVar(&opts.DryRun, cli.StripDash(cli.DryRunFlagName), false, "print kubernetes resources to stdout rather than apply them to the cluster, messages normally on stdout will be sent to stderr")
+	cli.DryRunFlags(cmd, c, &opts.DryRun)
 
 	return cmd
 }
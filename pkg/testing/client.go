@@ -27,13 +27,17 @@ import (
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1beta1"
 	"k8s.io/apimachinery/pkg/runtime"
 	kubernetes "k8s.io/client-go/kubernetes/fake"
+	appsv1clientset "k8s.io/client-go/kubernetes/typed/apps/v1"
 	authv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
 	corev1clientset "k8s.io/client-go/kubernetes/typed/core/v1"
+	extensionsv1beta1clientset "k8s.io/client-go/kubernetes/typed/extensions/v1beta1"
+	rbacv1clientset "k8s.io/client-go/kubernetes/typed/rbac/v1"
 	"k8s.io/client-go/rest"
 )
 
 type FakeClient struct {
 	Namespace                  string
+	NamespaceError             error
 	FakeKubeRestConfig         *rest.Config
 	FakeKubeClientset          *kubernetes.Clientset
 	FakeRiffClientset          *projectriffclientset.Clientset
@@ -45,36 +49,55 @@ func (c *FakeClient) DefaultNamespace() string {
 	return c.Namespace
 }
 
+func (c *FakeClient) LoadDefaultNamespace() (string, error) {
+	if c.NamespaceError != nil {
+		return "", c.NamespaceError
+	}
+	return c.Namespace, nil
+}
+
 func (c *FakeClient) KubeRestConfig() *rest.Config {
 	return c.FakeKubeRestConfig
 }
 
 func (c *FakeClient) Core() corev1clientset.CoreV1Interface {
-	return c.FakeKubeClientset.CoreV1()
+	return &fakeCoreV1{c.FakeKubeClientset.CoreV1()}
+}
+
+func (c *FakeClient) Apps() appsv1clientset.AppsV1Interface {
+	return c.FakeKubeClientset.AppsV1()
 }
 
 func (c *FakeClient) Auth() authv1client.AuthorizationV1Interface {
 	return c.FakeKubeClientset.AuthorizationV1()
 }
 
+func (c *FakeClient) Extensions() extensionsv1beta1clientset.ExtensionsV1beta1Interface {
+	return c.FakeKubeClientset.ExtensionsV1beta1()
+}
+
+func (c *FakeClient) Rbac() rbacv1clientset.RbacV1Interface {
+	return &fakeRbacV1{c.FakeKubeClientset.RbacV1()}
+}
+
 func (c *FakeClient) APIExtension() apiextensionsv1beta1.ApiextensionsV1beta1Interface {
 	return c.FakeAPIExtensionsClientset.ApiextensionsV1beta1()
 }
 
 func (c *FakeClient) Build() buildv1alpha1clientset.BuildV1alpha1Interface {
-	return c.FakeRiffClientset.BuildV1alpha1()
+	return &fakeBuildV1alpha1{c.FakeRiffClientset.BuildV1alpha1()}
 }
 
 func (c *FakeClient) CoreRuntime() corev1alpha1clientset.CoreV1alpha1Interface {
-	return c.FakeRiffClientset.CoreV1alpha1()
+	return &fakeCoreV1alpha1{c.FakeRiffClientset.CoreV1alpha1()}
 }
 
 func (c *FakeClient) StreamingRuntime() streamv1alpha1clientset.StreamingV1alpha1Interface {
-	return c.FakeRiffClientset.StreamingV1alpha1()
+	return &fakeStreamingV1alpha1{c.FakeRiffClientset.StreamingV1alpha1()}
 }
 
 func (c *FakeClient) KnativeRuntime() knativev1alpha1clientset.KnativeV1alpha1Interface {
-	return c.FakeRiffClientset.KnativeV1alpha1()
+	return &fakeKnativeV1alpha1{c.FakeRiffClientset.KnativeV1alpha1()}
 }
 
 func (c *FakeClient) PrependReactor(verb, resource string, reaction ReactionFunc) {
@@ -0,0 +1,130 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package testing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	buildv1alpha1clientset "github.com/projectriff/system/pkg/client/clientset/versioned/typed/build/v1alpha1"
+	corev1alpha1clientset "github.com/projectriff/system/pkg/client/clientset/versioned/typed/core/v1alpha1"
+	knativev1alpha1clientset "github.com/projectriff/system/pkg/client/clientset/versioned/typed/knative/v1alpha1"
+	streamv1alpha1clientset "github.com/projectriff/system/pkg/client/clientset/versioned/typed/streaming/v1alpha1"
+	corev1clientset "k8s.io/client-go/kubernetes/typed/core/v1"
+	rbacv1clientset "k8s.io/client-go/kubernetes/typed/rbac/v1"
+	"k8s.io/client-go/rest"
+)
+
+// DryRunUID is the uid the fake REST clients assign to resources created in
+// dry run mode, as the API server would.
+const DryRunUID = "dry-run-uid"
+
+// dryRunRESTConfig is used for the REST clients of the fake clientsets, which
+// otherwise have none. Requests that modify resources in dry run mode respond
+// with the submitted resource. All other requests fail, the fake clientsets
+// should be used instead.
+var dryRunRESTConfig = &rest.Config{
+	Host:      "https://localhost:8443",
+	Transport: dryRunRoundTripper{},
+}
+
+type dryRunRoundTripper struct{}
+
+func (dryRunRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Query().Get("dryRun") != "All" || (req.Method != http.MethodPost && req.Method != http.MethodPut) {
+		return nil, fmt.Errorf("unexpected %s request to fake REST client: %s", req.Method, req.URL)
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return nil, err
+	}
+	status := http.StatusOK
+	if req.Method == http.MethodPost {
+		status = http.StatusCreated
+		metadata, _ := obj["metadata"].(map[string]interface{})
+		if metadata == nil {
+			metadata = map[string]interface{}{}
+			obj["metadata"] = metadata
+		}
+		metadata["uid"] = DryRunUID
+	}
+	body, err = json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+type fakeCoreV1 struct {
+	corev1clientset.CoreV1Interface
+}
+
+func (c *fakeCoreV1) RESTClient() rest.Interface {
+	return corev1clientset.NewForConfigOrDie(dryRunRESTConfig).RESTClient()
+}
+
+type fakeRbacV1 struct {
+	rbacv1clientset.RbacV1Interface
+}
+
+func (c *fakeRbacV1) RESTClient() rest.Interface {
+	return rbacv1clientset.NewForConfigOrDie(dryRunRESTConfig).RESTClient()
+}
+
+type fakeBuildV1alpha1 struct {
+	buildv1alpha1clientset.BuildV1alpha1Interface
+}
+
+func (c *fakeBuildV1alpha1) RESTClient() rest.Interface {
+	return buildv1alpha1clientset.NewForConfigOrDie(dryRunRESTConfig).RESTClient()
+}
+
+type fakeCoreV1alpha1 struct {
+	corev1alpha1clientset.CoreV1alpha1Interface
+}
+
+func (c *fakeCoreV1alpha1) RESTClient() rest.Interface {
+	return corev1alpha1clientset.NewForConfigOrDie(dryRunRESTConfig).RESTClient()
+}
+
+type fakeStreamingV1alpha1 struct {
+	streamv1alpha1clientset.StreamingV1alpha1Interface
+}
+
+func (c *fakeStreamingV1alpha1) RESTClient() rest.Interface {
+	return streamv1alpha1clientset.NewForConfigOrDie(dryRunRESTConfig).RESTClient()
+}
+
+type fakeKnativeV1alpha1 struct {
+	knativev1alpha1clientset.KnativeV1alpha1Interface
+}
+
+func (c *fakeKnativeV1alpha1) RESTClient() rest.Interface {
+	return knativev1alpha1clientset.NewForConfigOrDie(dryRunRESTConfig).RESTClient()
+}
@@ -6,16 +6,18 @@ import (
 	context "context"
 	io "io"
 
+	kail "github.com/projectriff/cli/pkg/kail"
+
 	corev1alpha1 "github.com/projectriff/system/pkg/apis/core/v1alpha1"
 
 	knativev1alpha1 "github.com/projectriff/system/pkg/apis/knative/v1alpha1"
 
+	labels "k8s.io/apimachinery/pkg/labels"
+
 	mock "github.com/stretchr/testify/mock"
 
 	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
 
-	time "time"
-
 	v1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
 )
 
@@ -24,13 +26,27 @@ type Logger struct {
 	mock.Mock
 }
 
-// ApplicationLogs provides a mock function with given fields: ctx, application, since, out
-func (_m *Logger) ApplicationLogs(ctx context.Context, application *v1alpha1.Application, since time.Duration, out io.Writer) error {
-	ret := _m.Called(ctx, application, since, out)
+// ApplicationLogs provides a mock function with given fields: ctx, application, opts, out
+func (_m *Logger) ApplicationLogs(ctx context.Context, application *v1alpha1.Application, opts kail.LogOptions, out io.Writer) error {
+	ret := _m.Called(ctx, application, opts, out)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *v1alpha1.Application, kail.LogOptions, io.Writer) error); ok {
+		r0 = rf(ctx, application, opts, out)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CoreDeployerLogs provides a mock function with given fields: ctx, deployer, opts, out
+func (_m *Logger) CoreDeployerLogs(ctx context.Context, deployer *corev1alpha1.Deployer, opts kail.LogOptions, out io.Writer) error {
+	ret := _m.Called(ctx, deployer, opts, out)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *v1alpha1.Application, time.Duration, io.Writer) error); ok {
-		r0 = rf(ctx, application, since, out)
+	if rf, ok := ret.Get(0).(func(context.Context, *corev1alpha1.Deployer, kail.LogOptions, io.Writer) error); ok {
+		r0 = rf(ctx, deployer, opts, out)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -38,13 +54,13 @@ func (_m *Logger) ApplicationLogs(ctx context.Context, application *v1alpha1.App
 	return r0
 }
 
-// CoreDeployerLogs provides a mock function with given fields: ctx, deployer, since, out
-func (_m *Logger) CoreDeployerLogs(ctx context.Context, deployer *corev1alpha1.Deployer, since time.Duration, out io.Writer) error {
-	ret := _m.Called(ctx, deployer, since, out)
+// FunctionLogs provides a mock function with given fields: ctx, function, opts, out
+func (_m *Logger) FunctionLogs(ctx context.Context, function *v1alpha1.Function, opts kail.LogOptions, out io.Writer) error {
+	ret := _m.Called(ctx, function, opts, out)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *corev1alpha1.Deployer, time.Duration, io.Writer) error); ok {
-		r0 = rf(ctx, deployer, since, out)
+	if rf, ok := ret.Get(0).(func(context.Context, *v1alpha1.Function, kail.LogOptions, io.Writer) error); ok {
+		r0 = rf(ctx, function, opts, out)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -52,13 +68,13 @@ func (_m *Logger) CoreDeployerLogs(ctx context.Context, deployer *corev1alpha1.D
 	return r0
 }
 
-// FunctionLogs provides a mock function with given fields: ctx, function, since, out
-func (_m *Logger) FunctionLogs(ctx context.Context, function *v1alpha1.Function, since time.Duration, out io.Writer) error {
-	ret := _m.Called(ctx, function, since, out)
+// KnativeDeployerLogs provides a mock function with given fields: ctx, deployer, opts, out
+func (_m *Logger) KnativeDeployerLogs(ctx context.Context, deployer *knativev1alpha1.Deployer, opts kail.LogOptions, out io.Writer) error {
+	ret := _m.Called(ctx, deployer, opts, out)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *v1alpha1.Function, time.Duration, io.Writer) error); ok {
-		r0 = rf(ctx, function, since, out)
+	if rf, ok := ret.Get(0).(func(context.Context, *knativev1alpha1.Deployer, kail.LogOptions, io.Writer) error); ok {
+		r0 = rf(ctx, deployer, opts, out)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -66,13 +82,13 @@ func (_m *Logger) FunctionLogs(ctx context.Context, function *v1alpha1.Function,
 	return r0
 }
 
-// KnativeDeployerLogs provides a mock function with given fields: ctx, deployer, since, out
-func (_m *Logger) KnativeDeployerLogs(ctx context.Context, deployer *knativev1alpha1.Deployer, since time.Duration, out io.Writer) error {
-	ret := _m.Called(ctx, deployer, since, out)
+// Logs provides a mock function with given fields: ctx, namespace, selectors, opts, out
+func (_m *Logger) Logs(ctx context.Context, namespace string, selectors []labels.Selector, opts kail.LogOptions, out io.Writer) error {
+	ret := _m.Called(ctx, namespace, selectors, opts, out)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *knativev1alpha1.Deployer, time.Duration, io.Writer) error); ok {
-		r0 = rf(ctx, deployer, since, out)
+	if rf, ok := ret.Get(0).(func(context.Context, string, []labels.Selector, kail.LogOptions, io.Writer) error); ok {
+		r0 = rf(ctx, namespace, selectors, opts, out)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -80,13 +96,13 @@ func (_m *Logger) KnativeDeployerLogs(ctx context.Context, deployer *knativev1al
 	return r0
 }
 
-// StreamingProcessorLogs provides a mock function with given fields: ctx, processor, since, out
-func (_m *Logger) StreamingProcessorLogs(ctx context.Context, processor *streamingv1alpha1.Processor, since time.Duration, out io.Writer) error {
-	ret := _m.Called(ctx, processor, since, out)
+// StreamingProcessorLogs provides a mock function with given fields: ctx, processor, opts, out
+func (_m *Logger) StreamingProcessorLogs(ctx context.Context, processor *streamingv1alpha1.Processor, opts kail.LogOptions, out io.Writer) error {
+	ret := _m.Called(ctx, processor, opts, out)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *streamingv1alpha1.Processor, time.Duration, io.Writer) error); ok {
-		r0 = rf(ctx, processor, since, out)
+	if rf, ok := ret.Get(0).(func(context.Context, *streamingv1alpha1.Processor, kail.LogOptions, io.Writer) error); ok {
+		r0 = rf(ctx, processor, opts, out)
 	} else {
 		r0 = ret.Error(0)
 	}
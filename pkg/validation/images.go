@@ -0,0 +1,120 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validation
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/knative/pkg/apis"
+)
+
+// the image grammar follows https://github.com/docker/distribution/blob/master/reference/reference.go
+var (
+	imageDomainRegexp = regexp.MustCompile(`^(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])(?:\.(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9]))*(?::[0-9]+)?$`)
+	imagePathRegexp   = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*$`)
+	imageTagRegexp    = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	imageDigestRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,}$`)
+)
+
+const imageNameMaxLength = 255
+
+// ImageRepository validates an image repository, an optional registry host
+// followed by one or more path components, without a tag or digest.
+func ImageRepository(repository, field string) *apis.FieldError {
+	errs := &apis.FieldError{}
+
+	if !validImageRepository(repository) {
+		errs = errs.Also(apis.ErrInvalidValue(repository, field))
+	}
+
+	return errs
+}
+
+// ImageRef validates an image reference, an image repository optionally
+// followed by a tag, a digest, or both.
+func ImageRef(image, field string) *apis.FieldError {
+	errs := &apis.FieldError{}
+
+	if !validImageRef(image) {
+		errs = errs.Also(apis.ErrInvalidValue(image, field))
+	}
+
+	return errs
+}
+
+// BuildImageRef validates the image for a build. In addition to image
+// references, the image may be '_' or start with '_/' to be resolved with the
+// namespace's default image prefix.
+func BuildImageRef(image, field string) *apis.FieldError {
+	errs := &apis.FieldError{}
+
+	if image == "_" {
+		return errs
+	}
+	if strings.HasPrefix(image, "_/") {
+		// the prefix is validated when it is set, check the remainder as a path
+		if !validImageRef(strings.TrimPrefix(image, "_/")) {
+			errs = errs.Also(apis.ErrInvalidValue(image, field))
+		}
+		return errs
+	}
+
+	return errs.Also(ImageRef(image, field))
+}
+
+func validImageRef(image string) bool {
+	repository := image
+	if i := strings.Index(repository, "@"); i >= 0 {
+		if !imageDigestRegexp.MatchString(repository[i+1:]) {
+			return false
+		}
+		repository = repository[:i]
+	}
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		if !imageTagRegexp.MatchString(repository[i+1:]) {
+			return false
+		}
+		repository = repository[:i]
+	}
+	return validImageRepository(repository)
+}
+
+func validImageRepository(repository string) bool {
+	if repository == "" || len(repository) > imageNameMaxLength {
+		return false
+	}
+	components := strings.Split(repository, "/")
+	if len(components) > 1 && isImageDomain(components[0]) {
+		if !imageDomainRegexp.MatchString(components[0]) {
+			return false
+		}
+		components = components[1:]
+	}
+	for _, component := range components {
+		if !imagePathRegexp.MatchString(component) {
+			return false
+		}
+	}
+	return true
+}
+
+// isImageDomain applies docker's rule for telling a registry host apart from
+// the first path component of a repository.
+func isImageDomain(component string) bool {
+	return strings.ContainsAny(component, ".:") || component == "localhost"
+}
@@ -0,0 +1,217 @@
+/*
+ * Copyright 2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validation_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/projectriff/cli/pkg/cli"
+	rifftesting "github.com/projectriff/cli/pkg/testing"
+	"github.com/projectriff/cli/pkg/validation"
+)
+
+func TestImageRepository(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected *cli.FieldError
+		value    string
+	}{{
+		name:     "docker hub",
+		expected: cli.EmptyFieldError,
+		value:    "projectriff",
+	}, {
+		name:     "docker hub user",
+		expected: cli.EmptyFieldError,
+		value:    "docker.io/projectriff",
+	}, {
+		name:     "registry with port",
+		expected: cli.EmptyFieldError,
+		value:    "registry.example.com:5000/my-team/apps",
+	}, {
+		name:     "localhost",
+		expected: cli.EmptyFieldError,
+		value:    "localhost/my-user",
+	}, {
+		name:     "separators",
+		expected: cli.EmptyFieldError,
+		value:    "gcr.io/my_project/my.repo__name---x",
+	}, {
+		name:     "empty",
+		expected: cli.ErrInvalidValue("", rifftesting.TestField),
+		value:    "",
+	}, {
+		name:     "empty path component",
+		expected: cli.ErrInvalidValue("registry.example.com//my-user", rifftesting.TestField),
+		value:    "registry.example.com//my-user",
+	}, {
+		name:     "trailing slash",
+		expected: cli.ErrInvalidValue("registry.example.com/my-user/", rifftesting.TestField),
+		value:    "registry.example.com/my-user/",
+	}, {
+		name:     "upper case path",
+		expected: cli.ErrInvalidValue("docker.io/MyUser", rifftesting.TestField),
+		value:    "docker.io/MyUser",
+	}, {
+		name:     "invalid port",
+		expected: cli.ErrInvalidValue("registry.example.com:port/my-user", rifftesting.TestField),
+		value:    "registry.example.com:port/my-user",
+	}, {
+		name:     "scheme",
+		expected: cli.ErrInvalidValue("https://registry.example.com/my-user", rifftesting.TestField),
+		value:    "https://registry.example.com/my-user",
+	}, {
+		name:     "tag",
+		expected: cli.ErrInvalidValue("docker.io/projectriff:latest", rifftesting.TestField),
+		value:    "docker.io/projectriff:latest",
+	}, {
+		name:     "too long",
+		expected: cli.ErrInvalidValue("docker.io/"+strings.Repeat("a", 250), rifftesting.TestField),
+		value:    "docker.io/" + strings.Repeat("a", 250),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expected := test.expected
+			actual := validation.ImageRepository(test.value, rifftesting.TestField)
+			if diff := rifftesting.DiffFieldErrors(expected, actual); diff != "" {
+				t.Errorf("%s() = (-expected, +actual): %s", test.name, diff)
+			}
+		})
+	}
+}
+
+func TestImageRef(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	tests := []struct {
+		name     string
+		expected *cli.FieldError
+		value    string
+	}{{
+		name:     "repository",
+		expected: cli.EmptyFieldError,
+		value:    "registry.example.com/image",
+	}, {
+		name:     "tag",
+		expected: cli.EmptyFieldError,
+		value:    "registry.example.com:5000/image:v1.0_rc-1",
+	}, {
+		name:     "digest",
+		expected: cli.EmptyFieldError,
+		value:    "registry.example.com/image@" + digest,
+	}, {
+		name:     "tag and digest",
+		expected: cli.EmptyFieldError,
+		value:    "projectriff/image:latest@" + digest,
+	}, {
+		name:     "empty",
+		expected: cli.ErrInvalidValue("", rifftesting.TestField),
+		value:    "",
+	}, {
+		name:     "empty path component and tag",
+		expected: cli.ErrInvalidValue("registry.example.com//image:", rifftesting.TestField),
+		value:    "registry.example.com//image:",
+	}, {
+		name:     "empty tag",
+		expected: cli.ErrInvalidValue("registry.example.com/image:", rifftesting.TestField),
+		value:    "registry.example.com/image:",
+	}, {
+		name:     "invalid tag",
+		expected: cli.ErrInvalidValue("registry.example.com/image:-latest", rifftesting.TestField),
+		value:    "registry.example.com/image:-latest",
+	}, {
+		name:     "tag too long",
+		expected: cli.ErrInvalidValue("image:"+strings.Repeat("a", 129), rifftesting.TestField),
+		value:    "image:" + strings.Repeat("a", 129),
+	}, {
+		name:     "short digest",
+		expected: cli.ErrInvalidValue("image@sha256:abc", rifftesting.TestField),
+		value:    "image@sha256:abc",
+	}, {
+		name:     "digest without algorithm",
+		expected: cli.ErrInvalidValue("image@"+strings.Repeat("a", 64), rifftesting.TestField),
+		value:    "image@" + strings.Repeat("a", 64),
+	}, {
+		name:     "invalid registry",
+		expected: cli.ErrInvalidValue("-registry.example.com/image", rifftesting.TestField),
+		value:    "-registry.example.com/image",
+	}, {
+		name:     "default image prefix",
+		expected: cli.ErrInvalidValue("_", rifftesting.TestField),
+		value:    "_",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expected := test.expected
+			actual := validation.ImageRef(test.value, rifftesting.TestField)
+			if diff := rifftesting.DiffFieldErrors(expected, actual); diff != "" {
+				t.Errorf("%s() = (-expected, +actual): %s", test.name, diff)
+			}
+		})
+	}
+}
+
+func TestBuildImageRef(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected *cli.FieldError
+		value    string
+	}{{
+		name:     "default image prefix",
+		expected: cli.EmptyFieldError,
+		value:    "_",
+	}, {
+		name:     "default image prefix with path",
+		expected: cli.EmptyFieldError,
+		value:    "_/apps/my-app",
+	}, {
+		name:     "default image prefix with tag",
+		expected: cli.EmptyFieldError,
+		value:    "_/my-app:latest",
+	}, {
+		name:     "image ref",
+		expected: cli.EmptyFieldError,
+		value:    "registry.example.com/my-app",
+	}, {
+		name:     "default image prefix without path",
+		expected: cli.ErrInvalidValue("_/", rifftesting.TestField),
+		value:    "_/",
+	}, {
+		name:     "default image prefix with empty path component",
+		expected: cli.ErrInvalidValue("_//my-app", rifftesting.TestField),
+		value:    "_//my-app",
+	}, {
+		name:     "underscore",
+		expected: cli.ErrInvalidValue("_my-app", rifftesting.TestField),
+		value:    "_my-app",
+	}, {
+		name:     "invalid image ref",
+		expected: cli.ErrInvalidValue("registry.example.com//my-app:", rifftesting.TestField),
+		value:    "registry.example.com//my-app:",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expected := test.expected
+			actual := validation.BuildImageRef(test.value, rifftesting.TestField)
+			if diff := rifftesting.DiffFieldErrors(expected, actual); diff != "" {
+				t.Errorf("%s() = (-expected, +actual): %s", test.name, diff)
+			}
+		})
+	}
+}